@@ -0,0 +1,185 @@
+package httptester
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheControl returns the Cache-Control directives with their unquoted
+// values (empty for directives without a value). Directive names are
+// lowercase.
+func (r *Response) CacheControl() map[string]string {
+	return parseCacheControl(strings.Join(r.Header.Values("Cache-Control"), ","))
+}
+
+func parseCacheControl(header string) map[string]string {
+	directives := map[string]string{}
+	parseHeaderParams(header, func(name string, value string) {
+		if _, ok := directives[name]; !ok {
+			directives[name] = value
+		}
+	})
+	return directives
+}
+
+// CacheDirective checks that Cache-Control contains directive, e.g.
+// must-revalidate.
+func (r *Response) CacheDirective(directive string) *Response {
+	r.helper()
+	defer r.track("CacheDirective", directive)()
+
+	if _, ok := r.CacheControl()[strings.ToLower(directive)]; !ok {
+		r.err(r.headerError("CacheDirective", "Cache-Control", directive, "Cache-Control: expected %s got %q", directive, r.Header.Get("Cache-Control")))
+	}
+
+	return r
+}
+
+func (r *Response) NoStore() *Response {
+	r.helper()
+	return r.CacheDirective("no-store")
+}
+
+func (r *Response) NoCache() *Response {
+	r.helper()
+	return r.CacheDirective("no-cache")
+}
+
+func (r *Response) Private() *Response {
+	r.helper()
+	return r.CacheDirective("private")
+}
+
+func (r *Response) Public() *Response {
+	r.helper()
+	return r.CacheDirective("public")
+}
+
+// MaxAge checks the Cache-Control max-age directive in seconds.
+func (r *Response) MaxAge(seconds int) *Response {
+	r.helper()
+	defer r.track("MaxAge", seconds)()
+
+	r.cacheSeconds("MaxAge", "max-age", seconds)
+	return r
+}
+
+// SMaxAge checks the Cache-Control s-maxage directive in seconds, which
+// applies to shared caches such as CDNs.
+func (r *Response) SMaxAge(seconds int) *Response {
+	r.helper()
+	defer r.track("SMaxAge", seconds)()
+
+	r.cacheSeconds("SMaxAge", "s-maxage", seconds)
+	return r
+}
+
+func (r *Response) cacheSeconds(assertion string, directive string, seconds int) {
+	r.helper()
+
+	expected := fmt.Sprintf("%s=%d", directive, seconds)
+
+	value, ok := r.CacheControl()[directive]
+	if !ok {
+		r.err(r.headerError(assertion, "Cache-Control", expected, "Cache-Control: expected %s got %q", expected, r.Header.Get("Cache-Control")))
+		return
+	}
+
+	if actual, err := strconv.Atoi(value); err != nil || actual != seconds {
+		r.err(r.headerError(assertion, "Cache-Control", expected, "Cache-Control: expected %s got %s=%s", expected, directive, value))
+	}
+}
+
+var etagRegexp = regexp.MustCompile(`^(W/)?"[\x21\x23-\x7e\x80-\xff]*"$`)
+
+// ValidETag checks that the response has an ETag that is a quoted strong or
+// weak (W/"...") entity tag.
+func (r *Response) ValidETag() *Response {
+	r.helper()
+	defer r.track("ValidETag")()
+
+	etag := r.Header.Get("ETag")
+	if etag == "" {
+		r.err(r.headerError("ValidETag", "ETag", "", "response has no ETag header"))
+		return r
+	}
+
+	if !etagRegexp.MatchString(etag) {
+		r.err(r.headerError("ValidETag", "ETag", "", "invalid ETag %s", etag))
+	}
+
+	return r
+}
+
+// AgeAtMost checks that the Age header set by caches is at most seconds. A
+// missing Age header counts as 0.
+func (r *Response) AgeAtMost(seconds int) *Response {
+	r.helper()
+	defer r.track("AgeAtMost", seconds)()
+
+	header := r.Header.Get("Age")
+	if header == "" {
+		return r
+	}
+
+	age, err := strconv.Atoi(strings.TrimSpace(header))
+	if err != nil || age < 0 {
+		r.err(r.headerError("AgeAtMost", "Age", strconv.Itoa(seconds), "invalid Age %q", header))
+		return r
+	}
+
+	if age > seconds {
+		r.err(r.headerError("AgeAtMost", "Age", strconv.Itoa(seconds), "expected Age at most %d got %d", seconds, age))
+	}
+
+	return r
+}
+
+// ExpiresBetween checks that the Expires header is between min and max
+// after the Date header (or now if the response has no Date).
+func (r *Response) ExpiresBetween(min time.Duration, max time.Duration) *Response {
+	r.helper()
+	defer r.track("ExpiresBetween", min, max)()
+
+	header := r.Header.Get("Expires")
+	if header == "" {
+		r.err(r.headerError("ExpiresBetween", "Expires", "", "response has no Expires header"))
+		return r
+	}
+
+	expires, err := http.ParseTime(header)
+	if err != nil {
+		r.err(r.headerError("ExpiresBetween", "Expires", "", "invalid Expires %q", header))
+		return r
+	}
+
+	date, err := http.ParseTime(r.Header.Get("Date"))
+	if err != nil {
+		date = time.Now()
+	}
+
+	if d := expires.Sub(date); d < min || d > max {
+		r.err(r.headerError("ExpiresBetween", "Expires", "", "expected Expires between %s and %s after Date got %s", min, max, d))
+	}
+
+	return r
+}
+
+// Varies checks that the Vary header lists all headers.
+func (r *Response) Varies(headers ...string) *Response {
+	r.helper()
+	defer r.track("Varies", headers)()
+
+	vary := r.headerList("Vary")
+	for _, header := range headers {
+		if !containsFold(vary, header) && !containsFold(vary, "*") {
+			r.err(r.headerError("Varies", "Vary", header, "Vary: expected %s in %q", header, vary))
+		}
+	}
+
+	return r
+}
@@ -0,0 +1,74 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bancek/httptester"
+)
+
+func TestResponseCaching(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now().UTC()
+		w.Header().Set("Date", now.Format(http.TimeFormat))
+		if r.URL.Path == "/static" {
+			w.Header().Set("Cache-Control", `public, max-age=3600, s-maxage="86400", no-cache="Set-Cookie, X-Token"`)
+			w.Header().Set("ETag", `W/"abc-123"`)
+			w.Header().Set("Age", "10")
+			w.Header().Set("Expires", now.Add(time.Hour).Format(http.TimeFormat))
+			w.Header().Add("Vary", "Accept-Encoding")
+			w.Header().Add("Vary", "Origin")
+			return
+		}
+		w.Header().Set("Cache-Control", "private, no-store")
+		w.Header().Set("ETag", "abc")
+		w.Header().Set("Age", "120")
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	res := c.GET("/static").Do().
+		Public().
+		MaxAge(3600).
+		SMaxAge(86400).
+		NoCache().
+		ValidETag().
+		AgeAtMost(60).
+		ExpiresBetween(59*time.Minute, 61*time.Minute).
+		Varies("origin", "Accept-Encoding")
+
+	if v := res.CacheControl()["no-cache"]; v != "Set-Cookie, X-Token" {
+		t.Fatalf("expected no-cache field names got %q", v)
+	}
+
+	c.GET("/api").Do().Private().NoStore().CacheDirective("NO-STORE")
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.GET("/static").Do().MaxAge(60).NoStore()
+	b.GET("/api").Do().MaxAge(60).ValidETag().AgeAtMost(60).ExpiresBetween(0, time.Hour).Varies("Origin")
+
+	if len(errs) != 7 {
+		t.Fatalf("expected 7 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		"Cache-Control: expected max-age=60 got max-age=3600",
+		"Cache-Control: expected no-store got",
+		`Cache-Control: expected max-age=60 got "private, no-store"`,
+		"invalid ETag abc",
+		"expected Age at most 60 got 120",
+		"response has no Expires header",
+		"Vary: expected Origin in []",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package httptester
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// CaptureServer records every request it receives so tests can verify the
+// outbound calls made by the system under test.
+type CaptureServer struct {
+	*httptest.Server
+
+	t        testing.TB
+	mu       sync.Mutex
+	requests []CapturedRequest
+	received chan struct{}
+	status   int
+	headers  http.Header
+	body     []byte
+}
+
+type CapturedRequest struct {
+	Method string
+	URL    *url.URL
+	Header http.Header
+	Body   []byte
+	Time   time.Time
+}
+
+// NewCaptureServer starts a CaptureServer that replies 200 with an empty
+// body to every request and is closed when the test ends.
+func NewCaptureServer(t testing.TB) *CaptureServer {
+	c := &CaptureServer{
+		t:        t,
+		received: make(chan struct{}, 1),
+		status:   http.StatusOK,
+		headers:  http.Header{},
+	}
+
+	c.Server = httptest.NewServer(http.HandlerFunc(c.serveHTTP))
+	t.Cleanup(c.Close)
+
+	return c
+}
+
+// Reply sets the response to every request.
+func (c *CaptureServer) Reply(status int, body string) *CaptureServer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.status = status
+	c.body = []byte(body)
+	return c
+}
+
+func (c *CaptureServer) ReplyHeader(key string, value string) *CaptureServer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.headers.Set(key, value)
+	return c
+}
+
+func (c *CaptureServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		c.t.Errorf("capture server: %s %s: %s", r.Method, r.URL.RequestURI(), err)
+	}
+
+	c.mu.Lock()
+	c.requests = append(c.requests, CapturedRequest{
+		Method: r.Method,
+		URL:    r.URL,
+		Header: r.Header,
+		Body:   body,
+		Time:   time.Now(),
+	})
+	status := c.status
+	headers := c.headers.Clone()
+	resBody := c.body
+	c.mu.Unlock()
+
+	select {
+	case c.received <- struct{}{}:
+	default:
+	}
+
+	for k, vs := range headers {
+		w.Header()[k] = vs
+	}
+	w.WriteHeader(status)
+	w.Write(resBody)
+}
+
+func (c *CaptureServer) Requests() []CapturedRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]CapturedRequest(nil), c.requests...)
+}
+
+// Reset forgets the captured requests.
+func (c *CaptureServer) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests = nil
+}
+
+func (c *CaptureServer) find(method string, path string) []CapturedRequest {
+	matched := []CapturedRequest{}
+	for _, r := range c.Requests() {
+		if r.Method == method && pathMatches(path, r.URL.Path) {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// Received checks that exactly n requests matching method and path (which
+// may contain {name} placeholders) were captured.
+func (c *CaptureServer) Received(n int, method string, path string) *CaptureAssertion {
+	c.t.Helper()
+
+	return c.ReceivedWithin(0, n, method, path)
+}
+
+// ReceivedWithin is like Received but waits up to timeout for the requests,
+// for calls made asynchronously.
+func (c *CaptureServer) ReceivedWithin(timeout time.Duration, n int, method string, path string) *CaptureAssertion {
+	c.t.Helper()
+
+	deadline := time.Now().Add(timeout)
+
+	matched := c.find(method, path)
+	for len(matched) < n && time.Now().Before(deadline) {
+		select {
+		case <-c.received:
+		case <-time.After(time.Until(deadline)):
+		}
+		matched = c.find(method, path)
+	}
+
+	a := &CaptureAssertion{
+		t:        c.t,
+		name:     method + " " + path,
+		requests: matched,
+	}
+
+	if len(matched) != n {
+		a.errorf("expected %d requests got %d", n, len(matched))
+	}
+
+	return a
+}
+
+// CaptureAssertion checks the requests selected by CaptureServer.Received.
+// Every With method must hold for each of the requests.
+type CaptureAssertion struct {
+	t        testing.TB
+	name     string
+	requests []CapturedRequest
+}
+
+func (a *CaptureAssertion) Requests() []CapturedRequest {
+	return a.requests
+}
+
+func (a *CaptureAssertion) errorf(format string, args ...interface{}) {
+	a.t.Helper()
+
+	a.t.Errorf("capture server: %s: %s", a.name, fmt.Sprintf(format, args...))
+}
+
+func (a *CaptureAssertion) each(check func(r CapturedRequest) error) *CaptureAssertion {
+	a.t.Helper()
+
+	for i, r := range a.requests {
+		if err := check(r); err != nil {
+			a.errorf("request %d: %s", i+1, err)
+		}
+	}
+
+	return a
+}
+
+func (a *CaptureAssertion) WithHeader(key string, value string) *CaptureAssertion {
+	a.t.Helper()
+
+	return a.each(func(r CapturedRequest) error {
+		if actual := r.Header.Get(key); actual != value {
+			return fmt.Errorf("header %s: expected %s got %s", key, value, actual)
+		}
+		return nil
+	})
+}
+
+func (a *CaptureAssertion) WithQuery(key string, value string) *CaptureAssertion {
+	a.t.Helper()
+
+	return a.each(func(r CapturedRequest) error {
+		if actual := r.URL.Query().Get(key); actual != value {
+			return fmt.Errorf("query %s: expected %s got %s", key, value, actual)
+		}
+		return nil
+	})
+}
+
+func (a *CaptureAssertion) WithBody(body string) *CaptureAssertion {
+	a.t.Helper()
+
+	return a.each(func(r CapturedRequest) error {
+		if string(r.Body) != body {
+			return fmt.Errorf("expected body %q got %q", body, bodyExcerpt(r.Body))
+		}
+		return nil
+	})
+}
+
+func (a *CaptureAssertion) WithBodyContains(substr string) *CaptureAssertion {
+	a.t.Helper()
+
+	return a.each(func(r CapturedRequest) error {
+		if !bytes.Contains(r.Body, []byte(substr)) {
+			return fmt.Errorf("body does not contain %q: %s", substr, bodyExcerpt(r.Body))
+		}
+		return nil
+	})
+}
+
+// WithJSONBody checks that the body is JSON equal to v.
+func (a *CaptureAssertion) WithJSONBody(v interface{}) *CaptureAssertion {
+	a.t.Helper()
+
+	expected, err := normalizeJSON(v)
+	if err != nil {
+		a.errorf("%s", err)
+		return a
+	}
+
+	return a.each(func(r CapturedRequest) error {
+		var actual interface{}
+		if err := json.Unmarshal(r.Body, &actual); err != nil {
+			return fmt.Errorf("invalid JSON body: %s: %s", err, bodyExcerpt(r.Body))
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			return fmt.Errorf("expected JSON body %s got %s", jsonString(expected), jsonString(actual))
+		}
+		return nil
+	})
+}
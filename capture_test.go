@@ -0,0 +1,52 @@
+package httptester_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bancek/httptester"
+)
+
+func TestCaptureServer(t *testing.T) {
+	cs := httptester.NewCaptureServer(t).Reply(202, "accepted")
+
+	c := httptester.New(t, cs.URL)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		res, err := http.Post(cs.URL+"/webhooks/orders", "application/json", strings.NewReader(`{"event":"paid","id":1}`))
+		if err == nil {
+			res.Body.Close()
+		}
+	}()
+
+	c.GET("/ping").Q("x", "1").Do().Status(202).Eq("accepted")
+
+	cs.ReceivedWithin(time.Second, 1, "POST", "/webhooks/{topic}").
+		WithHeader("Content-Type", "application/json").
+		WithJSONBody(map[string]interface{}{"id": 1, "event": "paid"}).
+		WithBodyContains(`"paid"`)
+	cs.Received(1, "GET", "/ping").WithQuery("x", "1")
+
+	if len(cs.Requests()) != 2 {
+		t.Fatalf("expected 2 requests got %d", len(cs.Requests()))
+	}
+
+	rt := &recordingT{TB: t}
+	cs = httptester.NewCaptureServer(rt)
+
+	httptester.New(t, cs.URL).POST("/webhook").Body(strings.NewReader(`{"id":2}`)).Do().Status(200)
+
+	cs.Received(1, "POST", "/webhook").WithJSONBody(map[string]int{"id": 1}).WithBody("x")
+	cs.Received(2, "POST", "/webhook")
+
+	errs := rt.Errors()
+	if len(errs) != 3 ||
+		errs[0] != `capture server: POST /webhook: request 1: expected JSON body {"id":1} got {"id":2}` ||
+		errs[1] != `capture server: POST /webhook: request 1: expected body "x" got "{\"id\":2}"` ||
+		errs[2] != "capture server: POST /webhook: expected 2 requests got 1" {
+		t.Fatalf("unexpected errors %q", errs)
+	}
+}
@@ -0,0 +1,374 @@
+package httptester
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+type CassetteMode int
+
+const (
+	// CassetteAuto replays the cassette if the file exists and records it
+	// otherwise. Setting HTTPTESTER_RECORD=1 switches it to CassetteRecord.
+	CassetteAuto CassetteMode = iota
+	// CassetteRecord always sends requests and overwrites the file.
+	CassetteRecord
+	// CassetteReplay never sends requests and fails if the file is missing.
+	CassetteReplay
+)
+
+// DefaultScrubHeaders are the headers whose values are replaced with
+// RedactedValue when a cassette is saved.
+var DefaultScrubHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// CassetteMatcher reports whether a recorded request matches req.
+type CassetteMatcher func(req *http.Request, body []byte, recorded CassetteRequest) bool
+
+// MatchMethodURLBody matches requests with the same method, URL and body.
+func MatchMethodURLBody(req *http.Request, body []byte, recorded CassetteRequest) bool {
+	return req.Method == recorded.Method && req.URL.String() == recorded.URL && bytes.Equal(body, recorded.body())
+}
+
+// MatchMethodURL matches requests with the same method and URL.
+func MatchMethodURL(req *http.Request, body []byte, recorded CassetteRequest) bool {
+	return req.Method == recorded.Method && req.URL.String() == recorded.URL
+}
+
+type CassetteInteraction struct {
+	Request  CassetteRequest  `json:"request" yaml:"request"`
+	Response CassetteResponse `json:"response" yaml:"response"`
+}
+
+type CassetteRequest struct {
+	Method     string              `json:"method" yaml:"method"`
+	URL        string              `json:"url" yaml:"url"`
+	Headers    map[string][]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body       string              `json:"body,omitempty" yaml:"body,omitempty"`
+	BodyBase64 bool                `json:"bodyBase64,omitempty" yaml:"bodyBase64,omitempty"`
+}
+
+type CassetteResponse struct {
+	Status     int                 `json:"status" yaml:"status"`
+	Headers    map[string][]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body       string              `json:"body,omitempty" yaml:"body,omitempty"`
+	BodyBase64 bool                `json:"bodyBase64,omitempty" yaml:"bodyBase64,omitempty"`
+}
+
+func (r CassetteRequest) body() []byte {
+	return decodeCassetteBody(r.Body, r.BodyBase64)
+}
+
+func (r CassetteResponse) body() []byte {
+	return decodeCassetteBody(r.Body, r.BodyBase64)
+}
+
+func encodeCassetteBody(body []byte) (string, bool) {
+	if utf8.Valid(body) {
+		return string(body), false
+	}
+	return base64.StdEncoding.EncodeToString(body), true
+}
+
+func decodeCassetteBody(body string, isBase64 bool) []byte {
+	if !isBase64 {
+		if body == "" {
+			return nil
+		}
+		return []byte(body)
+	}
+	data, _ := base64.StdEncoding.DecodeString(body)
+	return data
+}
+
+// Cassette records the interactions of builders it is attached to (see
+// ReqBuilder.Cassette) to a YAML or JSON file (by extension) and replays
+// them on subsequent runs so tests do not depend on external services.
+// Repeated matching requests are served the recorded responses in order,
+// the last one being reused once they run out.
+type Cassette struct {
+	path         string
+	mode         CassetteMode
+	matcher      CassetteMatcher
+	redactor     *Redactor
+	mu           sync.Mutex
+	interactions []CassetteInteraction
+	used         []bool
+}
+
+type CassetteOption func(c *Cassette)
+
+func WithCassetteMode(mode CassetteMode) CassetteOption {
+	return func(c *Cassette) {
+		c.mode = mode
+	}
+}
+
+func WithCassetteMatcher(matcher CassetteMatcher) CassetteOption {
+	return func(c *Cassette) {
+		c.matcher = matcher
+	}
+}
+
+// WithScrubHeaders sets the headers (in addition to DefaultScrubHeaders)
+// whose values are replaced when the cassette is saved.
+func WithScrubHeaders(headers ...string) CassetteOption {
+	return func(c *Cassette) {
+		c.redactor.Headers(headers...)
+	}
+}
+
+// NewCassette loads the cassette at path, usually under testdata, or
+// prepares it for recording. A recorded cassette is saved when the test
+// ends.
+func NewCassette(t testing.TB, path string, opts ...CassetteOption) *Cassette {
+	t.Helper()
+
+	c := &Cassette{
+		path:     path,
+		matcher:  MatchMethodURLBody,
+		redactor: NewRedactor().Headers(DefaultScrubHeaders...),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.mode == CassetteAuto && os.Getenv("HTTPTESTER_RECORD") != "" {
+		c.mode = CassetteRecord
+	}
+
+	if c.mode == CassetteAuto {
+		c.mode = CassetteRecord
+		if _, err := os.Stat(path); err == nil {
+			c.mode = CassetteReplay
+		}
+	}
+
+	if c.mode == CassetteReplay {
+		if err := c.load(); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	t.Cleanup(func() {
+		if err := c.Save(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	return c
+}
+
+// Recording reports whether the cassette sends requests and records them.
+func (c *Cassette) Recording() bool {
+	return c.mode == CassetteRecord
+}
+
+func (c *Cassette) isJSON() bool {
+	return strings.EqualFold(filepath.Ext(c.path), ".json")
+}
+
+func (c *Cassette) load() error {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return err
+	}
+
+	var interactions []CassetteInteraction
+	if c.isJSON() {
+		err = json.Unmarshal(data, &interactions)
+	} else {
+		err = yaml.Unmarshal(data, &interactions)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid cassette %s: %w", c.path, err)
+	}
+
+	c.interactions = interactions
+	c.used = make([]bool, len(interactions))
+
+	return nil
+}
+
+// Save writes the recorded interactions to the cassette file.
+func (c *Cassette) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	interactions := c.interactions
+	if interactions == nil {
+		interactions = []CassetteInteraction{}
+	}
+
+	var data []byte
+	var err error
+	if c.isJSON() {
+		data, err = json.MarshalIndent(interactions, "", "  ")
+	} else {
+		data, err = yaml.Marshal(interactions)
+	}
+	if err != nil {
+		return err
+	}
+
+	f, err := createFile(c.path)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// scrubHeaders redacts the scrubbed headers and the headers redacted by
+// redactor, the Redactor of the builder if it has one.
+func (c *Cassette) scrubHeaders(header http.Header, redactor *Redactor) map[string][]string {
+	if len(header) == 0 {
+		return nil
+	}
+
+	return c.redactor.header(redactor.header(header))
+}
+
+// Transport returns an http.RoundTripper that records requests sent through
+// next or, when replaying, serves them from the cassette.
+func (c *Cassette) Transport(next http.RoundTripper) http.RoundTripper {
+	return c.transport(next, nil)
+}
+
+// transport returns a cassette transport also redacting the headers redacted
+// by redactor.
+func (c *Cassette) transport(next http.RoundTripper, redactor *Redactor) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &cassetteTransport{
+		cassette: c,
+		next:     next,
+		redactor: redactor,
+	}
+}
+
+type cassetteTransport struct {
+	cassette *Cassette
+	next     http.RoundTripper
+	redactor *Redactor
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c := t.cassette
+
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	if !c.Recording() {
+		return c.replay(req, reqBody)
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	interaction := CassetteInteraction{
+		Request: CassetteRequest{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: c.scrubHeaders(req.Header, t.redactor),
+		},
+		Response: CassetteResponse{
+			Status:  res.StatusCode,
+			Headers: c.scrubHeaders(res.Header, t.redactor),
+		},
+	}
+	interaction.Request.Body, interaction.Request.BodyBase64 = encodeCassetteBody(reqBody)
+	interaction.Response.Body, interaction.Response.BodyBase64 = encodeCassetteBody(resBody)
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, interaction)
+	c.used = append(c.used, true)
+	c.mu.Unlock()
+
+	return res, nil
+}
+
+func (c *Cassette) replay(req *http.Request, body []byte) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	last := -1
+	found := -1
+	for i, interaction := range c.interactions {
+		if !c.matcher(req, body, interaction.Request) {
+			continue
+		}
+		last = i
+		if !c.used[i] {
+			found = i
+			break
+		}
+	}
+	if found < 0 {
+		found = last
+	}
+	if found < 0 {
+		return nil, fmt.Errorf("no interaction in cassette %s matches %s %s", c.path, req.Method, req.URL.String())
+	}
+
+	c.used[found] = true
+	recorded := c.interactions[found].Response
+
+	header := http.Header{}
+	for k, vs := range recorded.Headers {
+		for _, v := range vs {
+			header.Add(k, v)
+		}
+	}
+
+	resBody := recorded.body()
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(resBody)),
+		ContentLength: int64(len(resBody)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,75 @@
+package httptester_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestCassette(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		fmt.Fprintf(w, `{"n":%d}`, requests)
+	}))
+	defer server.Close()
+
+	for _, name := range []string{"users.yaml", "users.json"} {
+		t.Run(name, func(t *testing.T) {
+			requests = 0
+			path := filepath.Join(t.TempDir(), "cassettes", name)
+
+			record := func(t *testing.T) {
+				cassette := httptester.NewCassette(t, path, httptester.WithScrubHeaders("X-Tenant"))
+				if !cassette.Recording() {
+					t.Fatal("expected recording")
+				}
+
+				c := httptester.New(t, server.URL, httptester.WithCassette(cassette), httptester.WithRedactor(httptester.NewRedactor().Headers("X-Trace")))
+				c.GET("/users").Bearer("token").Header("X-Tenant", "acme", "X-Trace", "trace-1").Do().Status(200).JSONPath("$.n", 1)
+				c.GET("/users").Do().Status(200).JSONPath("$.n", 2)
+				c.POST("/users").Body(strings.NewReader("ann")).Do().Status(200).JSONPath("$.n", 3)
+			}
+			t.Run("record", record)
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, secret := range []string{"token", "acme", "trace-1", "session=secret"} {
+				if strings.Contains(string(data), secret) {
+					t.Fatalf("cassette contains %s:\n%s", secret, data)
+				}
+			}
+
+			cassette := httptester.NewCassette(t, path)
+			if cassette.Recording() {
+				t.Fatal("expected replay")
+			}
+
+			c := httptester.New(t, server.URL, httptester.WithCassette(cassette))
+			c.GET("/users").Do().Status(200).JSONPath("$.n", 1).HeaderEq("Set-Cookie", "session="+httptester.RedactedValue)
+			c.GET("/users").Do().Status(200).JSONPath("$.n", 2)
+			c.GET("/users").Do().Status(200).JSONPath("$.n", 2)
+			c.POST("/users").Body(strings.NewReader("ann")).Do().JSONPath("$.n", 3)
+
+			var errs []error
+			collectErrors(server.URL, &errs).Cassette(cassette).POST("/users").Body(strings.NewReader("bob")).Do()
+			if len(errs) != 1 || !strings.Contains(errs[0].Error(), "no interaction in cassette") {
+				t.Fatalf("expected no match error got %v", errs)
+			}
+
+			if requests != 3 {
+				t.Fatalf("expected 3 requests to reach the server got %d", requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,378 @@
+package httptester
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"mime"
+)
+
+// CBORContentType is the Content-Type of CBOR bodies.
+const CBORContentType = "application/cbor"
+
+type cborCodec struct{}
+
+func (cborCodec) Marshal(v interface{}) ([]byte, error) {
+	return marshalCBOR(v)
+}
+
+func (cborCodec) Unmarshal(data []byte, v interface{}) error {
+	return unmarshalCBOR(data, v)
+}
+
+func init() {
+	RegisterCodec(CBORContentType, cborCodec{})
+}
+
+// marshalCBOR encodes v as CBOR. Values are converted like encoding/json
+// does, so struct fields are named by their json tags and []byte is encoded
+// as a base64 string.
+func marshalCBOR(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := encodeCBOR(buf, generic); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalCBOR decodes CBOR into v, see marshalCBOR. Byte strings decode
+// into []byte fields and tags are ignored.
+func unmarshalCBOR(data []byte, v interface{}) error {
+	d := &cborDecoder{data: data}
+	generic, err := d.decode()
+	if err != nil {
+		return fmt.Errorf("invalid CBOR: %w", err)
+	}
+	if d.pos != len(data) {
+		return fmt.Errorf("invalid CBOR: %d trailing bytes", len(data)-d.pos)
+	}
+
+	jsonBytes, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonBytes, v)
+}
+
+const (
+	cborUint   = 0
+	cborNegInt = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborTag    = 6
+	cborSimple = 7
+)
+
+func encodeCBORHead(buf *bytes.Buffer, major byte, n uint64) {
+	major <<= 5
+	switch {
+	case n < 24:
+		buf.WriteByte(major | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(major | 24)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(major | 25)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	case n <= math.MaxUint32:
+		buf.WriteByte(major | 26)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	default:
+		buf.WriteByte(major | 27)
+		binary.Write(buf, binary.BigEndian, n)
+	}
+}
+
+func encodeCBOR(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(0xf6)
+
+	case bool:
+		if v {
+			buf.WriteByte(0xf5)
+		} else {
+			buf.WriteByte(0xf4)
+		}
+
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			if i >= 0 {
+				encodeCBORHead(buf, cborUint, uint64(i))
+			} else {
+				encodeCBORHead(buf, cborNegInt, uint64(-1-i))
+			}
+			return nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return err
+		}
+		buf.WriteByte(0xfb)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(f))
+
+	case string:
+		encodeCBORHead(buf, cborText, uint64(len(v)))
+		buf.WriteString(v)
+
+	case []interface{}:
+		encodeCBORHead(buf, cborArray, uint64(len(v)))
+		for _, elem := range v {
+			if err := encodeCBOR(buf, elem); err != nil {
+				return err
+			}
+		}
+
+	case map[string]interface{}:
+		encodeCBORHead(buf, cborMap, uint64(len(v)))
+		for _, key := range sortedMapKeys(v) {
+			encodeCBOR(buf, key)
+			if err := encodeCBOR(buf, v[key]); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("cannot encode %T as CBOR", v)
+	}
+
+	return nil
+}
+
+var (
+	errCBORShort = errors.New("unexpected end of data")
+	errCBORBreak = errors.New("unexpected break")
+)
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *cborDecoder) read(n uint64) ([]byte, error) {
+	if uint64(len(d.data)-d.pos) < n {
+		return nil, errCBORShort
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// head reads the initial byte and argument of a data item. indefinite is
+// set for indefinite length items.
+func (d *cborDecoder) head() (major byte, info byte, arg uint64, err error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major, info = b[0]>>5, b[0]&0x1f
+
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info <= 27:
+		b, err := d.read(1 << (info - 24))
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		for _, c := range b {
+			arg = arg<<8 | uint64(c)
+		}
+		return major, info, arg, nil
+	case info == 31:
+		return major, info, 0, nil
+	}
+
+	return 0, 0, 0, fmt.Errorf("invalid additional information %d at offset %d", info, d.pos-1)
+}
+
+func (d *cborDecoder) decode() (interface{}, error) {
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	indefinite := info == 31
+
+	switch major {
+	case cborUint:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+		return int64(arg), nil
+
+	case cborNegInt:
+		if arg > math.MaxInt64 {
+			return -1 - float64(arg), nil
+		}
+		return -1 - int64(arg), nil
+
+	case cborBytes, cborText:
+		b, err := d.decodeString(major, arg, indefinite)
+		if err != nil {
+			return nil, err
+		}
+		if major == cborText {
+			return string(b), nil
+		}
+		return b, nil
+
+	case cborArray:
+		arr := []interface{}{}
+		for i := uint64(0); indefinite || i < arg; i++ {
+			if indefinite && d.atBreak() {
+				break
+			}
+			v, err := d.decode()
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, nil
+
+	case cborMap:
+		m := map[string]interface{}{}
+		for i := uint64(0); indefinite || i < arg; i++ {
+			if indefinite && d.atBreak() {
+				break
+			}
+			key, err := d.decode()
+			if err != nil {
+				return nil, err
+			}
+			value, err := d.decode()
+			if err != nil {
+				return nil, err
+			}
+			if s, ok := key.(string); ok {
+				m[s] = value
+			} else {
+				m[fmt.Sprint(key)] = value
+			}
+		}
+		return m, nil
+
+	case cborTag:
+		return d.decode()
+	}
+
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		return float16ToFloat64(uint16(arg)), nil
+	case 26:
+		return float64(math.Float32frombits(uint32(arg))), nil
+	case 27:
+		return math.Float64frombits(arg), nil
+	case 31:
+		return nil, errCBORBreak
+	}
+
+	return nil, fmt.Errorf("unsupported simple value %d at offset %d", arg, d.pos-1)
+}
+
+// atBreak consumes the break ending an indefinite length item.
+func (d *cborDecoder) atBreak() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == 0xff {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+func (d *cborDecoder) decodeString(major byte, n uint64, indefinite bool) ([]byte, error) {
+	if !indefinite {
+		return d.read(n)
+	}
+
+	var b []byte
+	for !d.atBreak() {
+		chunkMajor, info, arg, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || info == 31 {
+			return nil, fmt.Errorf("invalid chunk of indefinite length string at offset %d", d.pos-1)
+		}
+		chunk, err := d.read(arg)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, chunk...)
+	}
+	return b, nil
+}
+
+func float16ToFloat64(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1
+	}
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			return math.Inf(int(sign))
+		}
+		return math.NaN()
+	}
+	return sign * math.Ldexp(mant+1024, exp-25)
+}
+
+// CBOR sets the body to v encoded as CBOR and the Content-Type to
+// application/cbor. Struct fields are named by their json tags.
+func (b *ReqBuilder) CBOR(v interface{}) *ReqBuilder {
+	b.helper()
+
+	b.Header("Content-Type", CBORContentType)
+	data, err := marshalCBOR(v)
+	if err != nil {
+		b.onError(err)
+	}
+	return b.Body(bytes.NewReader(data))
+}
+
+// CBOR decodes the CBOR body into v, see ReqBuilder.CBOR.
+func (r *Response) CBOR(v interface{}) interface{} {
+	r.helper()
+
+	if !r.hasBody() {
+		return nil
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != CBORContentType {
+		r.err(fmt.Errorf("Content-Type is not application/cbor, got %s: %s", contentType, r.bodyExcerpt()))
+	}
+	if err := unmarshalCBOR(r.Body, v); err != nil {
+		r.err(err)
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,106 @@
+package httptester_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestCBOR(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/echo":
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+			w.Write(body)
+		case "/fixed":
+			w.Header().Set("Content-Type", "application/cbor")
+			// {"a": 1.5 (float16), "b": [_ true, null], "c": -500, "d": h'6869', "t": 1(1700000000), "s": (_ "ab", "c")}
+			w.Write([]byte{
+				0xa6,
+				0x61, 'a', 0xf9, 0x3e, 0x00,
+				0x61, 'b', 0x9f, 0xf5, 0xf6, 0xff,
+				0x61, 'c', 0x39, 0x01, 0xf3,
+				0x61, 'd', 0x42, 'h', 'i',
+				0x61, 't', 0xc1, 0x1a, 0x65, 0x53, 0xf1, 0x00,
+				0x61, 's', 0x7f, 0x62, 'a', 'b', 0x61, 'c', 0xff,
+			})
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{}`))
+		case "/truncated":
+			w.Header().Set("Content-Type", "application/cbor")
+			w.Write([]byte{0x82, 0x01})
+		}
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	type reading struct {
+		Sensor string            `json:"sensor"`
+		Value  float64           `json:"value"`
+		Seq    int64             `json:"seq"`
+		Offset int64             `json:"offset"`
+		Tags   []string          `json:"tags"`
+		Meta   map[string]string `json:"meta"`
+		Valid  bool              `json:"valid"`
+	}
+	in := reading{
+		Sensor: strings.Repeat("s", 30),
+		Value:  21.5,
+		Seq:    1 << 33,
+		Offset: -70000,
+		Tags:   []string{"a", "b"},
+		Meta:   map[string]string{"k": "v"},
+		Valid:  true,
+	}
+
+	var out reading
+	c.POST("/echo").CBOR(in).Do().
+		HeaderEq("Content-Type", "application/cbor").
+		CBOR(&out)
+	if out.Sensor != in.Sensor || out.Value != in.Value || out.Seq != in.Seq || out.Offset != in.Offset || len(out.Tags) != 2 || out.Meta["k"] != "v" || !out.Valid {
+		t.Fatalf("unexpected reading %+v", out)
+	}
+
+	var fixed struct {
+		A float64       `json:"a"`
+		B []interface{} `json:"b"`
+		C int           `json:"c"`
+		D []byte        `json:"d"`
+		T int64         `json:"t"`
+		S string        `json:"s"`
+	}
+	c.GET("/fixed").Do().CBOR(&fixed)
+	if fixed.A != 1.5 || len(fixed.B) != 2 || fixed.B[0] != true || fixed.B[1] != nil || fixed.C != -500 || !bytes.Equal(fixed.D, []byte("hi")) || fixed.T != 1700000000 || fixed.S != "abc" {
+		t.Fatalf("unexpected value %+v", fixed)
+	}
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	var v interface{}
+	b.GET("/json").Do().CBOR(&v)
+	b.GET("/truncated").Do().CBOR(&v)
+
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		"Content-Type is not application/cbor, got application/json: {}",
+		"invalid CBOR: unexpected end of data",
+		"invalid CBOR: unexpected end of data",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package httptester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen matches the errors of requests failed by an open
+// CircuitBreaker with errors.Is.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// CircuitOpenError is the error of requests to a host whose circuit is open.
+// Err is the transport error that tripped the circuit.
+type CircuitOpenError struct {
+	Host     string
+	Failures int
+	Err      error
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker open for %s after %d consecutive transport failures: %v", e.Host, e.Failures, e.Err)
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// CircuitBreaker fails requests of builders it is attached to (see
+// ReqBuilder.CircuitBreaker) fast once threshold consecutive requests to the
+// same host failed with a transport error, so a suite against an environment
+// that is down does not wait for every request to time out. Responses of
+// any status reset the count. Requests are counted across all builders and
+// retries.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	mu        sync.Mutex
+	hosts     map[string]*circuit
+}
+
+type circuit struct {
+	failures int
+	lastErr  error
+	openedAt time.Time
+}
+
+func NewCircuitBreaker(threshold int) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		hosts:     map[string]*circuit{},
+	}
+}
+
+// Cooldown lets one request through to a host whose circuit has been open
+// for d. The circuit closes if it gets a response and stays open otherwise.
+// Without a cooldown the circuit stays open until Reset.
+func (cb *CircuitBreaker) Cooldown(d time.Duration) *CircuitBreaker {
+	cb.cooldown = d
+	return cb
+}
+
+// Reset closes the circuits of all hosts.
+func (cb *CircuitBreaker) Reset() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.hosts = map[string]*circuit{}
+}
+
+// allow returns a *CircuitOpenError if the circuit of host is open.
+func (cb *CircuitBreaker) allow(host string) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c := cb.hosts[host]
+	if c == nil || c.failures < cb.threshold {
+		return nil
+	}
+
+	if cb.cooldown > 0 && time.Since(c.openedAt) >= cb.cooldown {
+		// Let a single probe through, following requests wait for another
+		// cooldown.
+		c.openedAt = time.Now()
+		return nil
+	}
+
+	return &CircuitOpenError{
+		Host:     host,
+		Failures: c.failures,
+		Err:      c.lastErr,
+	}
+}
+
+func (cb *CircuitBreaker) record(host string, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if err == nil {
+		delete(cb.hosts, host)
+		return
+	}
+
+	c := cb.hosts[host]
+	if c == nil {
+		c = &circuit{}
+		cb.hosts[host] = c
+	}
+	c.failures++
+	c.lastErr = err
+	if c.failures >= cb.threshold {
+		c.openedAt = time.Now()
+	}
+}
+
+// Transport returns an http.RoundTripper that sends requests through next
+// unless the circuit of their host is open.
+func (cb *CircuitBreaker) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &circuitBreakerTransport{
+		breaker: cb,
+		next:    next,
+	}
+}
+
+type circuitBreakerTransport struct {
+	breaker *CircuitBreaker
+	next    http.RoundTripper
+}
+
+func (t *circuitBreakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+
+	if err := t.breaker.allow(host); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil && errors.Is(err, context.Canceled) {
+		// Canceled by the test, not a failure of the host.
+		return nil, err
+	}
+	t.breaker.record(host, err)
+
+	return res, err
+}
+
+// CircuitBreaker fails the request fast if the circuit of its host is open,
+// see CircuitBreaker.
+func (b *ReqBuilder) CircuitBreaker(cb *CircuitBreaker) *ReqBuilder {
+	b.breaker = cb
+	return b
+}
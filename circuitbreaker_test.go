@@ -0,0 +1,106 @@
+package httptester_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bancek/httptester"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	faults := httptester.NewFaultInjector(httptester.FaultSequence(
+		httptester.ResetFault(),
+		httptester.ResetFault(),
+		httptester.NoFault(),
+		httptester.ResetFault(),
+		httptester.ResetFault(),
+		httptester.ResetFault(),
+	))
+	breaker := httptester.NewCircuitBreaker(3)
+
+	var errs []error
+	c := httptester.NewClient(
+		httptester.WithBaseURL(server.URL),
+		httptester.WithFaults(faults),
+		httptester.WithCircuitBreaker(breaker),
+		httptester.WithOnError(func(err error) {
+			errs = append(errs, err)
+		}),
+	)
+
+	// A response resets the consecutive failures.
+	c.GET("/").Do()
+	c.GET("/").Do()
+	c.GET("/").Do().Eq("ok")
+
+	policy := httptester.DefaultRetryPolicy(5)
+	policy.InitialBackoff = time.Millisecond
+
+	// Retries stop once the circuit opens.
+	c.GET("/").RetryPolicy(policy).Do()
+	c.GET("/").Do()
+
+	if requests != 1 {
+		t.Fatalf("expected 1 request to reach the server got %d", requests)
+	}
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 errors got %d: %v", len(errs), errs)
+	}
+	for i, err := range errs[2:] {
+		if !errors.Is(err, httptester.ErrCircuitOpen) {
+			t.Errorf("expected error %d to be ErrCircuitOpen got %v", i+2, err)
+		}
+		var circuitErr *httptester.CircuitOpenError
+		if !errors.As(err, &circuitErr) || circuitErr.Failures != 3 {
+			t.Errorf("expected CircuitOpenError after 3 failures got %v", err)
+		}
+		if !strings.Contains(err.Error(), "circuit breaker open for "+strings.TrimPrefix(server.URL, "http://")+" after 3 consecutive transport failures") {
+			t.Errorf("unexpected error message: %v", err)
+		}
+	}
+
+	breaker.Reset()
+	c.GET("/").Do().Eq("ok")
+	if requests != 2 {
+		t.Fatalf("expected the request after Reset to reach the server got %d requests", requests)
+	}
+}
+
+func TestCircuitBreakerCooldown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	faults := httptester.NewFaultInjector(httptester.FaultSequence(httptester.ResetFault()))
+	breaker := httptester.NewCircuitBreaker(1).Cooldown(10 * time.Millisecond)
+
+	var errs []error
+	newRequest := func() *httptester.ReqBuilder {
+		return collectErrors(server.URL, &errs).Faults(faults).CircuitBreaker(breaker).GET("/")
+	}
+
+	newRequest().Do()
+	newRequest().Do()
+	if len(errs) != 2 || !errors.Is(errs[1], httptester.ErrCircuitOpen) {
+		t.Fatalf("expected the circuit to open got %v", errs)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	newRequest().Do().Eq("ok")
+	newRequest().Do().Eq("ok")
+	if len(errs) != 2 {
+		t.Fatalf("expected the circuit to close after the cooldown got %v", errs)
+	}
+}
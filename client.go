@@ -0,0 +1,292 @@
+package httptester
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Client produces ReqBuilders that share a base URL, http.Client, default
+// headers, timeout, error handler and hooks.
+type Client struct {
+	mu       sync.Mutex
+	template *ReqBuilder
+}
+
+type ClientOption func(c *Client)
+
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.template.baseURL = baseURL
+	}
+}
+
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(c *Client) {
+		c.template.client = client
+	}
+}
+
+func WithHeader(args ...string) ClientOption {
+	return func(c *Client) {
+		c.template.Header(args...)
+	}
+}
+
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.template.Timeout(d)
+	}
+}
+
+func WithOnError(f func(error)) ClientOption {
+	return func(c *Client) {
+		c.template.OnError(f)
+	}
+}
+
+func WithBeforeRequest(f func(req *http.Request)) ClientOption {
+	return func(c *Client) {
+		c.template.BeforeRequest(f)
+	}
+}
+
+func WithMiddleware(mw ...Middleware) ClientOption {
+	return func(c *Client) {
+		c.template.Use(mw...)
+	}
+}
+
+func WithAfterRequest(f func(req *http.Request, res *http.Response, err error)) ClientOption {
+	return func(c *Client) {
+		c.template.AfterRequest(f)
+	}
+}
+
+func WithSign(f func(req *http.Request, body []byte)) ClientOption {
+	return func(c *Client) {
+		c.template.Sign(f)
+	}
+}
+
+func WithVars(vars *Vars) ClientOption {
+	return func(c *Client) {
+		c.template.Vars(vars)
+	}
+}
+
+func WithMetrics(metrics *Metrics) ClientOption {
+	return func(c *Client) {
+		c.template.Metrics(metrics)
+	}
+}
+
+func WithTracer(tracer Tracer) ClientOption {
+	return func(c *Client) {
+		c.template.Tracer(tracer)
+	}
+}
+
+func WithCassette(cassette *Cassette) ClientOption {
+	return func(c *Client) {
+		c.template.Cassette(cassette)
+	}
+}
+
+func WithHAR(recorder *HARRecorder) ClientOption {
+	return func(c *Client) {
+		c.template.Record(recorder)
+	}
+}
+
+func WithFaults(injector *FaultInjector) ClientOption {
+	return func(c *Client) {
+		c.template.Faults(injector)
+	}
+}
+
+func WithCircuitBreaker(cb *CircuitBreaker) ClientOption {
+	return func(c *Client) {
+		c.template.CircuitBreaker(cb)
+	}
+}
+
+func WithOAuth2(oauth2 *OAuth2) ClientOption {
+	return func(c *Client) {
+		c.template.OAuth2(oauth2)
+	}
+}
+
+func WithCoverage(coverage *Coverage) ClientOption {
+	return func(c *Client) {
+		c.template.Coverage(coverage)
+	}
+}
+
+func WithRetryAfter(maxRetries int, maxDelay time.Duration) ClientOption {
+	return func(c *Client) {
+		c.template.RetryAfter(maxRetries, maxDelay)
+	}
+}
+
+func WithFailureReport(report *FailureReport) ClientOption {
+	return func(c *Client) {
+		c.template.FailureReport(report)
+	}
+}
+
+func WithRedactor(redactor *Redactor) ClientOption {
+	return func(c *Client) {
+		c.template.Redact(redactor)
+	}
+}
+
+func WithRequestID(header string) ClientOption {
+	return func(c *Client) {
+		c.template.RequestID(header)
+	}
+}
+
+func WithReporter(reporter *JUnitReporter) ClientOption {
+	return func(c *Client) {
+		c.template.Reporter(reporter)
+	}
+}
+
+func withHelper(f func()) ClientOption {
+	return func(c *Client) {
+		c.template.Helper(f)
+	}
+}
+
+// NewClient returns a Client using http.DefaultClient that panics on errors
+// unless configured otherwise.
+func NewClient(opts ...ClientOption) *Client {
+	c := &Client{
+		template: NewReqBuilder("", http.DefaultClient, func(err error) {
+			panic(err)
+		}),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// New returns a Client whose requests fail the test on the first error and
+// report failures at the calling test line.
+func New(t testing.TB, baseURL string, opts ...ClientOption) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	t.Cleanup(transport.CloseIdleConnections)
+
+	defaults := []ClientOption{
+		WithBaseURL(baseURL),
+		WithHTTPClient(&http.Client{
+			Transport: transport,
+		}),
+		WithOnError(func(err error) {
+			t.Helper()
+			t.Fatal(err)
+		}),
+		withHelper(t.Helper),
+	}
+
+	return NewClient(append(defaults, opts...)...)
+}
+
+func (c *Client) Request() *ReqBuilder {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.template.Clone()
+}
+
+// update changes the defaults of requests created afterwards.
+func (c *Client) update(f func(template *ReqBuilder)) *Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f(c.template)
+	return c
+}
+
+// BeforeRequest adds a hook called before every request created afterwards
+// is sent. Requests can add their own hooks, which run after the hooks of
+// the client.
+func (c *Client) BeforeRequest(f func(req *http.Request)) *Client {
+	return c.update(func(template *ReqBuilder) {
+		template.BeforeRequest(f)
+	})
+}
+
+// AfterRequest adds a hook called after every request created afterwards
+// is sent.
+func (c *Client) AfterRequest(f func(req *http.Request, res *http.Response, err error)) *Client {
+	return c.update(func(template *ReqBuilder) {
+		template.AfterRequest(f)
+	})
+}
+
+// Use adds middleware to every request created afterwards, see
+// ReqBuilder.Use.
+func (c *Client) Use(mw ...Middleware) *Client {
+	return c.update(func(template *ReqBuilder) {
+		template.Use(mw...)
+	})
+}
+
+// OnError replaces the error handler of requests created afterwards. Unlike
+// the hooks it is not chained: requests replace it with ReqBuilder.OnError
+// or extend it with ReqBuilder.WrapOnError.
+func (c *Client) OnError(f func(error)) *Client {
+	return c.update(func(template *ReqBuilder) {
+		template.OnError(f)
+	})
+}
+
+// WrapOnError extends the error handler of requests created afterwards,
+// see ReqBuilder.WrapOnError.
+func (c *Client) WrapOnError(wrap func(next func(error)) func(error)) *Client {
+	return c.update(func(template *ReqBuilder) {
+		template.WrapOnError(wrap)
+	})
+}
+
+func (c *Client) Method(method string, url string) *ReqBuilder {
+	return c.Request().Method(method, url)
+}
+
+func (c *Client) GET(url string) *ReqBuilder {
+	return c.Request().GET(url)
+}
+
+func (c *Client) POST(url string) *ReqBuilder {
+	return c.Request().POST(url)
+}
+
+func (c *Client) PUT(url string) *ReqBuilder {
+	return c.Request().PUT(url)
+}
+
+func (c *Client) DELETE(url string) *ReqBuilder {
+	return c.Request().DELETE(url)
+}
+
+func (c *Client) PATCH(url string) *ReqBuilder {
+	return c.Request().PATCH(url)
+}
+
+func (c *Client) HEAD(url string) *ReqBuilder {
+	return c.Request().HEAD(url)
+}
+
+func (c *Client) OPTIONS(url string) *ReqBuilder {
+	return c.Request().OPTIONS(url)
+}
+
+func (c *Client) TRACE(url string) *ReqBuilder {
+	return c.Request().TRACE(url)
+}
@@ -0,0 +1,149 @@
+package httptester_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bancek/httptester"
+)
+
+func TestNewClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		fmt.Fprintf(w, "%s %s", r.Header.Get("X-Tenant"), r.Header.Get("X-Before"))
+	}))
+	defer server.Close()
+
+	var errs []error
+	var after []int
+
+	c := httptester.NewClient(
+		httptester.WithBaseURL(server.URL),
+		httptester.WithHeader("X-Tenant", "acme"),
+		httptester.WithTimeout(20*time.Millisecond),
+		httptester.WithOnError(func(err error) {
+			errs = append(errs, err)
+		}),
+		httptester.WithBeforeRequest(func(req *http.Request) {
+			req.Header.Set("X-Before", "yes")
+		}),
+		httptester.WithAfterRequest(func(req *http.Request, res *http.Response, err error) {
+			if res != nil {
+				after = append(after, res.StatusCode)
+			}
+		}),
+	)
+
+	c.GET("/").Header("X-Tenant", "other").Do().Status(200).Eq("other yes")
+	c.GET("/").Do().Status(200).Eq("acme yes")
+	c.GET("/slow").Do()
+
+	if len(errs) != 1 {
+		t.Fatalf("expected timeout error got %v", errs)
+	}
+	if len(after) != 2 {
+		t.Fatalf("expected 2 after hooks got %v", after)
+	}
+}
+
+func TestClientHooks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("Authorization") + " " + strings.Join(r.Header.Values("X-Hook"), ",")))
+	}))
+	defer server.Close()
+
+	var errs []error
+	var after []string
+
+	c := httptester.NewClient(httptester.WithBaseURL(server.URL))
+
+	token := "t1"
+	early := c.GET("/")
+
+	c.BeforeRequest(func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}).AfterRequest(func(req *http.Request, res *http.Response, err error) {
+		after = append(after, req.URL.Path)
+	}).Use(func(next httptester.RoundTripFunc) httptester.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			req.Header.Add("X-Hook", "client")
+			return next(req)
+		}
+	}).OnError(func(err error) {
+		errs = append(errs, err)
+	})
+
+	c.GET("/a").Do().Eq("Bearer t1 client")
+
+	token = "t2"
+	c.GET("/b").
+		BeforeRequest(func(req *http.Request) {
+			req.Header.Add("X-Hook", "request")
+		}).
+		Do().
+		Eq("Bearer t2 client,request").
+		Status(500)
+
+	c.GET("/c").Do().Eq("Bearer t2 client")
+
+	if len(after) != 3 || after[0] != "/a" || after[2] != "/c" {
+		t.Errorf("expected after hooks for /a, /b and /c got %v", after)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "expected status [500] got 200") {
+		t.Errorf("expected status error got %v", errs)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected builder created before OnError to keep panicking")
+			}
+		}()
+		early.Do().Eq("Bearer t1 client")
+	}()
+}
+
+func TestClientOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	var calls []string
+	c := httptester.NewClient(httptester.WithBaseURL(server.URL)).
+		OnError(func(err error) {
+			calls = append(calls, "client")
+		}).
+		WrapOnError(func(next func(error)) func(error) {
+			return func(err error) {
+				calls = append(calls, "client wrapper")
+				next(err)
+			}
+		})
+
+	c.GET("/").Do().Status(500)
+	c.GET("/").
+		WrapOnError(func(next func(error)) func(error) {
+			return func(err error) {
+				calls = append(calls, "request wrapper")
+				next(err)
+			}
+		}).
+		Do().Status(500)
+	c.GET("/").
+		OnError(func(err error) {
+			calls = append(calls, "request")
+		}).
+		Do().Status(500)
+
+	expected := "client wrapper,client,request wrapper,client wrapper,client,request"
+	if strings.Join(calls, ",") != expected {
+		t.Fatalf("expected %s got %s", expected, strings.Join(calls, ","))
+	}
+}
@@ -0,0 +1,127 @@
+package httptester
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"strings"
+	"sync"
+)
+
+// Codec marshals request bodies and unmarshals response bodies of a content
+// type, see RegisterCodec.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type xmlCodec struct{}
+
+func (xmlCodec) Marshal(v interface{}) ([]byte, error) {
+	return xml.Marshal(v)
+}
+
+func (xmlCodec) Unmarshal(data []byte, v interface{}) error {
+	return xml.Unmarshal(data, v)
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{
+		"application/json": jsonCodec{},
+		"application/xml":  xmlCodec{},
+		"text/xml":         xmlCodec{},
+	}
+)
+
+// RegisterCodec registers the codec used by Encode and Decode for a media
+// type such as application/msgpack. JSON and XML are built in and also
+// used for +json and +xml suffixed types like application/problem+json.
+func RegisterCodec(mediaType string, codec Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+
+	codecs[strings.ToLower(mediaType)] = codec
+}
+
+func codecFor(contentType string) (Codec, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Type %q: %w", contentType, err)
+	}
+
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	if codec, ok := codecs[mediaType]; ok {
+		return codec, nil
+	}
+	if i := strings.LastIndexByte(mediaType, '+'); i >= 0 {
+		if codec, ok := codecs["application/"+mediaType[i+1:]]; ok {
+			return codec, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no codec registered for %s", mediaType)
+}
+
+// Encode marshals v as the body with the codec registered for the
+// request's Content-Type, which defaults to application/json.
+func (b *ReqBuilder) Encode(v interface{}) *ReqBuilder {
+	b.helper()
+
+	contentType := b.headers.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/json"
+		b.Header("Content-Type", contentType)
+	}
+
+	codec, err := codecFor(contentType)
+	if err != nil {
+		b.onError(err)
+		return b
+	}
+
+	data, err := codec.Marshal(v)
+	if err != nil {
+		b.onError(err)
+		return b
+	}
+
+	return b.Body(bytes.NewReader(data))
+}
+
+// Decode unmarshals the body into v with the codec registered for the
+// response's Content-Type.
+func (r *Response) Decode(v interface{}) interface{} {
+	r.helper()
+
+	if !r.hasBody() {
+		return nil
+	}
+
+	codec, err := codecFor(r.Header.Get("Content-Type"))
+	if err != nil {
+		r.err(fmt.Errorf("cannot decode body: %w: %s", err, r.bodyExcerpt()))
+		return nil
+	}
+
+	if err := codec.Unmarshal(r.Body, v); err != nil {
+		r.err(err)
+		return nil
+	}
+
+	return v
+}
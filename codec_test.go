@@ -0,0 +1,97 @@
+package httptester_test
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+// lineCodec encodes a []string as one value per line.
+type lineCodec struct{}
+
+func (lineCodec) Marshal(v interface{}) ([]byte, error) {
+	return []byte(strings.Join(v.([]string), "\n")), nil
+}
+
+func (lineCodec) Unmarshal(data []byte, v interface{}) error {
+	*v.(*[]string) = strings.Split(string(data), "\n")
+	return nil
+}
+
+func TestCodecs(t *testing.T) {
+	httptester.RegisterCodec("text/x-lines", lineCodec{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		contentType := r.Header.Get("Content-Type")
+		if r.URL.Path == "/problem" {
+			contentType = "application/problem+json"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	type user struct {
+		XMLName xml.Name `json:"-" xml:"user"`
+		Name    string   `json:"name" xml:"name"`
+	}
+
+	var u user
+	c.POST("/").Encode(user{Name: "alice"}).Do().
+		HeaderEq("Content-Type", "application/json").
+		Eq(`{"name":"alice"}`).
+		Decode(&u)
+	if u.Name != "alice" {
+		t.Fatalf("unexpected user %+v", u)
+	}
+
+	u = user{}
+	c.POST("/").Header("Content-Type", "application/xml; charset=utf-8").Encode(user{Name: "bob"}).Do().
+		Eq(`<user><name>bob</name></user>`).
+		Decode(&u)
+	if u.Name != "bob" {
+		t.Fatalf("unexpected user %+v", u)
+	}
+
+	u = user{}
+	c.POST("/problem").Encode(user{Name: "carol"}).Do().Decode(&u)
+	if u.Name != "carol" {
+		t.Fatalf("unexpected user %+v", u)
+	}
+
+	var lines []string
+	c.POST("/").Header("Content-Type", "text/x-lines").Encode([]string{"a", "b"}).Do().
+		Eq("a\nb").
+		Decode(&lines)
+	if len(lines) != 2 || lines[1] != "b" {
+		t.Fatalf("unexpected lines %q", lines)
+	}
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.POST("/").Header("Content-Type", "application/x-unknown").Encode(u)
+	b.POST("/").Header("Content-Type", "text/plain").Body(strings.NewReader("hi")).Do().Decode(&u)
+
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		"no codec registered for application/x-unknown",
+		"cannot decode body: no codec registered for text/plain: hi",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
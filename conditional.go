@@ -0,0 +1,49 @@
+package httptester
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+func (b *ReqBuilder) IfNoneMatch(etag string) *ReqBuilder {
+	return b.Header("If-None-Match", etag)
+}
+
+func (b *ReqBuilder) IfModifiedSince(t time.Time) *ReqBuilder {
+	return b.Header("If-Modified-Since", t.UTC().Format(http.TimeFormat))
+}
+
+// RevalidatesWith304 sends a copy of the request with the validators (ETag
+// and Last-Modified) of prior, a response to the same request, and checks
+// that the server answers 304 Not Modified without a body.
+func (b *ReqBuilder) RevalidatesWith304(prior *Response) *Response {
+	b.helper()
+
+	etag := prior.Header.Get("ETag")
+	lastModified := prior.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		b.onError(errors.New("cannot revalidate: response has no ETag or Last-Modified"))
+		return nil
+	}
+
+	c := b.Clone()
+	if etag != "" {
+		c.IfNoneMatch(etag)
+	}
+	if lastModified != "" {
+		c.Header("If-Modified-Since", lastModified)
+	}
+
+	res := c.Do()
+	if res == nil {
+		return nil
+	}
+
+	res.Status(http.StatusNotModified)
+	if len(res.Body) > 0 {
+		res.err(errors.New("304 response has a body"))
+	}
+
+	return res
+}
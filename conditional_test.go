@@ -0,0 +1,63 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bancek/httptester"
+)
+
+func TestReqBuilderConditional(t *testing.T) {
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte("hello"))
+			return
+		}
+		if r.URL.Path == "/none" {
+			w.Write([]byte("hello"))
+			return
+		}
+		http.ServeContent(w, r, "hello.txt", modified, strings.NewReader("hello"))
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	c.GET("/").IfModifiedSince(modified).Do().Status(304)
+	c.GET("/").IfModifiedSince(modified.Add(-time.Hour)).Do().Status(200)
+
+	req := c.GET("/")
+	res := req.Clone().Do().Status(200)
+	req.RevalidatesWith304(res).BodyLen(0)
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	req = b.GET("/broken")
+	req.RevalidatesWith304(req.Clone().Do())
+	req = b.GET("/none")
+	if res := req.RevalidatesWith304(req.Clone().Do()); res != nil {
+		t.Fatalf("expected nil response got %v", res)
+	}
+
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		"expected status [304] got 200",
+		"304 response has a body",
+		"cannot revalidate: response has no ETag or Last-Modified",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
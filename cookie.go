@@ -0,0 +1,163 @@
+package httptester
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SetCookie returns the last cookie named name set by the response, or nil.
+func (r *Response) SetCookie(name string) *http.Cookie {
+	var cookie *http.Cookie
+	for _, c := range r.Cookies() {
+		if c.Name == name {
+			cookie = c
+		}
+	}
+	return cookie
+}
+
+// setCookie returns the cookie named name and reports an error if the
+// response did not set it.
+func (r *Response) setCookie(assertion string, name string) (*http.Cookie, bool) {
+	r.helper()
+
+	cookie := r.SetCookie(name)
+	if cookie == nil {
+		r.err(r.headerError(assertion, "Set-Cookie", name, "response did not set cookie %s", name))
+		return nil, false
+	}
+
+	return cookie, true
+}
+
+func (r *Response) CookieSecure(name string) *Response {
+	r.helper()
+	defer r.track("CookieSecure", name)()
+
+	if cookie, ok := r.setCookie("CookieSecure", name); ok && !cookie.Secure {
+		r.err(r.headerError("CookieSecure", "Set-Cookie", "Secure", "cookie %s: expected Secure attribute: %s", name, cookie.Raw))
+	}
+
+	return r
+}
+
+func (r *Response) CookieHttpOnly(name string) *Response {
+	r.helper()
+	defer r.track("CookieHttpOnly", name)()
+
+	if cookie, ok := r.setCookie("CookieHttpOnly", name); ok && !cookie.HttpOnly {
+		r.err(r.headerError("CookieHttpOnly", "Set-Cookie", "HttpOnly", "cookie %s: expected HttpOnly attribute: %s", name, cookie.Raw))
+	}
+
+	return r
+}
+
+// CookieSameSite checks the SameSite attribute, one of Strict, Lax or None
+// (case insensitive).
+func (r *Response) CookieSameSite(name string, sameSite string) *Response {
+	r.helper()
+	defer r.track("CookieSameSite", name, sameSite)()
+
+	cookie, ok := r.setCookie("CookieSameSite", name)
+	if !ok {
+		return r
+	}
+
+	if actual := cookieSameSite(cookie.SameSite); !strings.EqualFold(actual, sameSite) {
+		r.err(r.headerError("CookieSameSite", "Set-Cookie", "SameSite="+sameSite, "cookie %s: expected SameSite=%s got %q: %s", name, sameSite, actual, cookie.Raw))
+	}
+
+	return r
+}
+
+func cookieSameSite(sameSite http.SameSite) string {
+	switch sameSite {
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteNoneMode:
+		return "None"
+	}
+	return ""
+}
+
+func (r *Response) CookiePath(name string, path string) *Response {
+	r.helper()
+	defer r.track("CookiePath", name, path)()
+
+	if cookie, ok := r.setCookie("CookiePath", name); ok && cookie.Path != path {
+		r.err(r.headerError("CookiePath", "Set-Cookie", "Path="+path, "cookie %s: expected Path=%s got %q: %s", name, path, cookie.Path, cookie.Raw))
+	}
+
+	return r
+}
+
+// CookieDomain checks the Domain attribute. An empty domain checks that the
+// cookie is host-only.
+func (r *Response) CookieDomain(name string, domain string) *Response {
+	r.helper()
+	defer r.track("CookieDomain", name, domain)()
+
+	cookie, ok := r.setCookie("CookieDomain", name)
+	if !ok {
+		return r
+	}
+
+	if !strings.EqualFold(strings.TrimPrefix(cookie.Domain, "."), strings.TrimPrefix(domain, ".")) {
+		r.err(r.headerError("CookieDomain", "Set-Cookie", "Domain="+domain, "cookie %s: expected Domain=%s got %q: %s", name, domain, cookie.Domain, cookie.Raw))
+	}
+
+	return r
+}
+
+// CookieExpiresWithin checks that the cookie is persistent (has Max-Age or
+// Expires) and expires no later than d from now. Max-Age takes precedence
+// over Expires.
+func (r *Response) CookieExpiresWithin(name string, d time.Duration) *Response {
+	r.helper()
+	defer r.track("CookieExpiresWithin", name, d)()
+
+	cookie, ok := r.setCookie("CookieExpiresWithin", name)
+	if !ok {
+		return r
+	}
+
+	var lifetime time.Duration
+	switch {
+	case cookie.MaxAge > 0:
+		lifetime = time.Duration(cookie.MaxAge) * time.Second
+	case cookie.MaxAge < 0:
+		lifetime = 0
+	case !cookie.Expires.IsZero():
+		lifetime = time.Until(cookie.Expires)
+	default:
+		r.err(r.headerError("CookieExpiresWithin", "Set-Cookie", "Max-Age or Expires", "cookie %s: expected Max-Age or Expires attribute: %s", name, cookie.Raw))
+		return r
+	}
+
+	if lifetime > d {
+		r.err(r.headerError("CookieExpiresWithin", "Set-Cookie", d.String(), "cookie %s: expected to expire within %s, expires in %s: %s", name, d, lifetime.Round(time.Second), cookie.Raw))
+	}
+
+	return r
+}
+
+// CookieExpired checks that the response deletes the cookie with Max-Age=0
+// or an Expires date in the past, e.g. on logout.
+func (r *Response) CookieExpired(name string) *Response {
+	r.helper()
+	defer r.track("CookieExpired", name)()
+
+	cookie, ok := r.setCookie("CookieExpired", name)
+	if !ok {
+		return r
+	}
+
+	if cookie.MaxAge >= 0 && (cookie.MaxAge > 0 || cookie.Expires.IsZero() || cookie.Expires.After(time.Now())) {
+		r.err(r.headerError("CookieExpired", "Set-Cookie", "Max-Age=0", "cookie %s: expected to be expired: %s", name, cookie.Raw))
+	}
+
+	return r
+}
@@ -0,0 +1,68 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bancek/httptester"
+)
+
+func TestResponseCookies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Set-Cookie", "session=abc; Path=/; Domain=example.com; Max-Age=3600; Secure; HttpOnly; SameSite=Strict")
+		w.Header().Add("Set-Cookie", "theme=dark; Path=/ui; Expires="+time.Now().Add(48*time.Hour).UTC().Format(http.TimeFormat))
+		w.Header().Add("Set-Cookie", "old=; Path=/; Max-Age=0")
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	res := c.GET("/").Do().
+		CookieSecure("session").
+		CookieHttpOnly("session").
+		CookieSameSite("session", "strict").
+		CookiePath("session", "/").
+		CookieDomain("session", ".example.com").
+		CookieExpiresWithin("session", time.Hour).
+		CookieDomain("theme", "").
+		CookieExpiresWithin("theme", 49*time.Hour).
+		CookieExpired("old")
+
+	if cookie := res.SetCookie("session"); cookie == nil || cookie.Value != "abc" {
+		t.Fatalf("expected session cookie got %v", cookie)
+	}
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.GET("/").Do().
+		CookieSecure("theme").
+		CookieHttpOnly("theme").
+		CookieSameSite("theme", "Lax").
+		CookiePath("theme", "/").
+		CookieExpiresWithin("theme", time.Hour).
+		CookieExpired("session").
+		CookieSecure("missing")
+
+	if len(errs) != 7 {
+		t.Fatalf("expected 7 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		"cookie theme: expected Secure attribute",
+		"cookie theme: expected HttpOnly attribute",
+		`cookie theme: expected SameSite=Lax got ""`,
+		`cookie theme: expected Path=/ got "/ui"`,
+		"cookie theme: expected to expire within 1h0m0s, expires in 4",
+		"cookie session: expected to be expired",
+		"response did not set cookie missing",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
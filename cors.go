@@ -0,0 +1,103 @@
+package httptester
+
+import (
+	"strings"
+)
+
+// Preflight turns the request into a CORS preflight: an OPTIONS request
+// with Origin, Access-Control-Request-Method and, if headers are given,
+// Access-Control-Request-Headers.
+func (b *ReqBuilder) Preflight(origin string, method string, headers ...string) *ReqBuilder {
+	b.method = "OPTIONS"
+	b.Header("Origin", origin, "Access-Control-Request-Method", method)
+	if len(headers) > 0 {
+		b.Header("Access-Control-Request-Headers", strings.ToLower(strings.Join(headers, ",")))
+	}
+	return b
+}
+
+// headerList returns the comma separated values of a list header.
+func (r *Response) headerList(key string) []string {
+	values := []string{}
+	for _, header := range r.Header.Values(key) {
+		for _, v := range strings.Split(header, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+// AllowsOrigin checks that Access-Control-Allow-Origin allows origin, either
+// by echoing it or with a wildcard. A wildcard is rejected when the response
+// also allows credentials, which browsers do not accept.
+func (r *Response) AllowsOrigin(origin string) *Response {
+	r.helper()
+	defer r.track("AllowsOrigin", origin)()
+
+	allowed := r.Header.Get("Access-Control-Allow-Origin")
+	switch {
+	case allowed == origin:
+	case allowed == "*" && r.Header.Get("Access-Control-Allow-Credentials") == "true":
+		r.err(r.headerError("AllowsOrigin", "Access-Control-Allow-Origin", origin, "Access-Control-Allow-Origin: wildcard is not allowed with credentials"))
+	case allowed == "*":
+	default:
+		r.err(r.headerError("AllowsOrigin", "Access-Control-Allow-Origin", origin, "Access-Control-Allow-Origin: expected %s got %q", origin, allowed))
+	}
+
+	return r
+}
+
+func (r *Response) AllowsMethod(method string) *Response {
+	r.helper()
+	defer r.track("AllowsMethod", method)()
+
+	methods := r.headerList("Access-Control-Allow-Methods")
+	if !containsFold(methods, method) && !containsFold(methods, "*") {
+		r.err(r.headerError("AllowsMethod", "Access-Control-Allow-Methods", method, "Access-Control-Allow-Methods: expected %s in %q", method, methods))
+	}
+
+	return r
+}
+
+func (r *Response) AllowsHeaders(headers ...string) *Response {
+	r.helper()
+	defer r.track("AllowsHeaders", headers)()
+
+	allowed := r.headerList("Access-Control-Allow-Headers")
+	for _, header := range headers {
+		if !containsFold(allowed, header) && !containsFold(allowed, "*") {
+			r.err(r.headerError("AllowsHeaders", "Access-Control-Allow-Headers", header, "Access-Control-Allow-Headers: expected %s in %q", header, allowed))
+		}
+	}
+
+	return r
+}
+
+func (r *Response) AllowsCredentials() *Response {
+	r.helper()
+	defer r.track("AllowsCredentials")()
+
+	if v := r.Header.Get("Access-Control-Allow-Credentials"); v != "true" {
+		r.err(r.headerError("AllowsCredentials", "Access-Control-Allow-Credentials", "true", "Access-Control-Allow-Credentials: expected true got %q", v))
+	}
+
+	return r
+}
+
+// ExposesHeaders checks that Access-Control-Expose-Headers lets scripts read
+// headers of the actual response.
+func (r *Response) ExposesHeaders(headers ...string) *Response {
+	r.helper()
+	defer r.track("ExposesHeaders", headers)()
+
+	exposed := r.headerList("Access-Control-Expose-Headers")
+	for _, header := range headers {
+		if !containsFold(exposed, header) && !containsFold(exposed, "*") {
+			r.err(r.headerError("ExposesHeaders", "Access-Control-Expose-Headers", header, "Access-Control-Expose-Headers: expected %s in %q", header, exposed))
+		}
+	}
+
+	return r
+}
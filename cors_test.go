@@ -0,0 +1,75 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestCORS(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "https://app.example.com" {
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+		if r.Method == "OPTIONS" {
+			w.Header().Set("X-Request-Method", r.Header.Get("Access-Control-Request-Method"))
+			w.Header().Set("X-Request-Headers", r.Header.Get("Access-Control-Request-Headers"))
+			w.Header().Set("Access-Control-Allow-Methods", "GET, PUT")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Token")
+			w.WriteHeader(204)
+			return
+		}
+		w.Header().Set("Access-Control-Expose-Headers", "X-Total-Count")
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	c.PUT("/users/1").Preflight("https://app.example.com", "PUT", "X-Token", "Content-Type").Do().
+		Status(204).
+		HeaderEq("X-Request-Method", "PUT").
+		HeaderEq("X-Request-Headers", "x-token,content-type").
+		AllowsOrigin("https://app.example.com").
+		AllowsMethod("PUT").
+		AllowsHeaders("x-token", "content-type").
+		AllowsCredentials()
+
+	c.GET("/users").Header("Origin", "https://app.example.com").Do().
+		AllowsOrigin("https://app.example.com").
+		AllowsCredentials().
+		ExposesHeaders("X-Total-Count")
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.GET("/users").Preflight("https://app.example.com", "DELETE", "X-Other").Do().
+		AllowsMethod("DELETE").
+		AllowsHeaders("X-Other").
+		ExposesHeaders("X-Total-Count")
+	b.GET("/users").Preflight("https://evil.example.com", "GET").Do().
+		AllowsOrigin("https://evil.example.com").
+		AllowsCredentials()
+
+	if len(errs) != 5 {
+		t.Fatalf("expected 5 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		`Access-Control-Allow-Methods: expected DELETE in ["GET" "PUT"]`,
+		`Access-Control-Allow-Headers: expected X-Other in ["Content-Type" "X-Token"]`,
+		`Access-Control-Expose-Headers: expected X-Total-Count in []`,
+		`Access-Control-Allow-Origin: expected https://evil.example.com got ""`,
+		`Access-Control-Allow-Credentials: expected true got ""`,
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package httptester
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Coverage records the method and path of every request sent by builders it
+// is attached to (see ReqBuilder.Coverage) so they can be compared to the
+// operations of an OpenAPI spec, an API counterpart to code coverage.
+type Coverage struct {
+	mu       sync.Mutex
+	requests []coverageRequest
+}
+
+type coverageRequest struct {
+	method string
+	path   string
+	status int
+}
+
+func NewCoverage() *Coverage {
+	return &Coverage{}
+}
+
+func (c *Coverage) add(method string, path string, status int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests = append(c.requests, coverageRequest{
+		method: method,
+		path:   path,
+		status: status,
+	})
+}
+
+type CoverageEndpoint struct {
+	Method      string
+	Path        string
+	OperationID string
+	// Statuses are the distinct response statuses received for the
+	// endpoint.
+	Statuses []int
+}
+
+func (e CoverageEndpoint) String() string {
+	s := e.Method + " " + e.Path
+	if e.OperationID != "" {
+		s += " (" + e.OperationID + ")"
+	}
+	return s
+}
+
+type CoverageReport struct {
+	Covered   []CoverageEndpoint
+	Uncovered []CoverageEndpoint
+	// Undocumented are the requests that did not match any operation, as
+	// METHOD path.
+	Undocumented []string
+}
+
+// Report compares the recorded requests to the operations of spec. Request
+// paths are matched to the path templates of operations after removing the
+// base path of the first server.
+func (c *Coverage) Report(spec *OpenAPI) *CoverageReport {
+	c.mu.Lock()
+	requests := append([]coverageRequest(nil), c.requests...)
+	c.mu.Unlock()
+
+	basePath := spec.basePath()
+	operations := spec.operations()
+	statuses := make([]map[int]bool, len(operations))
+	undocumented := map[string]bool{}
+
+	report := &CoverageReport{}
+
+	for _, req := range requests {
+		path := strings.TrimPrefix(req.path, basePath)
+
+		found := false
+		for i, op := range operations {
+			if op.method == req.method && pathMatches(op.path, path) {
+				if statuses[i] == nil {
+					statuses[i] = map[int]bool{}
+				}
+				statuses[i][req.status] = true
+				found = true
+				break
+			}
+		}
+
+		name := req.method + " " + req.path
+		if !found && !undocumented[name] {
+			undocumented[name] = true
+			report.Undocumented = append(report.Undocumented, name)
+		}
+	}
+
+	for i, op := range operations {
+		operationID, _ := op.operation["operationId"].(string)
+		endpoint := CoverageEndpoint{
+			Method:      op.method,
+			Path:        op.path,
+			OperationID: operationID,
+		}
+
+		if statuses[i] == nil {
+			report.Uncovered = append(report.Uncovered, endpoint)
+			continue
+		}
+
+		for status := range statuses[i] {
+			endpoint.Statuses = append(endpoint.Statuses, status)
+		}
+		sort.Ints(endpoint.Statuses)
+		report.Covered = append(report.Covered, endpoint)
+	}
+
+	return report
+}
+
+// Percent returns the percentage of operations that were requested.
+func (r *CoverageReport) Percent() float64 {
+	total := len(r.Covered) + len(r.Uncovered)
+	if total == 0 {
+		return 100
+	}
+	return float64(len(r.Covered)) * 100 / float64(total)
+}
+
+func (r *CoverageReport) String() string {
+	sb := &strings.Builder{}
+
+	fmt.Fprintf(sb, "API coverage: %.1f%% (%d of %d operations)\n", r.Percent(), len(r.Covered), len(r.Covered)+len(r.Uncovered))
+	for _, e := range r.Covered {
+		fmt.Fprintf(sb, "  covered     %s %v\n", e, e.Statuses)
+	}
+	for _, e := range r.Uncovered {
+		fmt.Fprintf(sb, "  uncovered   %s\n", e)
+	}
+	for _, name := range r.Undocumented {
+		fmt.Fprintf(sb, "  undocumented %s\n", name)
+	}
+
+	return sb.String()
+}
+
+// Require fails the test if less than minPercent of the operations were
+// requested, listing the untested ones.
+func (r *CoverageReport) Require(t testing.TB, minPercent float64) {
+	t.Helper()
+
+	if r.Percent() < minPercent {
+		t.Errorf("API coverage %.1f%% is below %.1f%%, untested operations:\n  %s", r.Percent(), minPercent, strings.Join(r.uncoveredNames(), "\n  "))
+	}
+}
+
+// Warn logs the untested operations without failing the test.
+func (r *CoverageReport) Warn(t testing.TB) {
+	t.Helper()
+
+	if len(r.Uncovered) > 0 {
+		t.Logf("API coverage %.1f%%, untested operations:\n  %s", r.Percent(), strings.Join(r.uncoveredNames(), "\n  "))
+	}
+}
+
+func (r *CoverageReport) uncoveredNames() []string {
+	names := make([]string, len(r.Uncovered))
+	for i, e := range r.Uncovered {
+		names[i] = e.String()
+	}
+	return names
+}
@@ -0,0 +1,94 @@
+package httptester_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+const usersSpec = `
+openapi: 3.0.3
+info:
+  title: Users
+  version: "1.0"
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      responses:
+        200:
+          description: Users
+    post:
+      operationId: createUser
+      responses:
+        201:
+          description: Created
+  /users/{id}:
+    get:
+      operationId: getUser
+      responses:
+        200:
+          description: User
+    delete:
+      operationId: deleteUser
+      responses:
+        204:
+          description: Deleted
+`
+
+func TestCoverage(t *testing.T) {
+	spec, err := httptester.ParseOpenAPI([]byte(usersSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := spec.StubServer(t)
+	coverage := httptester.NewCoverage()
+	c := httptester.New(t, server.URL+"/v1", httptester.WithCoverage(coverage))
+
+	c.GET("/users").Do().Status(200)
+	c.GET("/users/{id}").Param("id", "1").Do().Status(200)
+	c.GET("/users/{id}").Param("id", "2").Do().Status(200)
+	c.GET("/groups").Do().Status(404)
+
+	report := coverage.Report(spec)
+
+	covered := []string{}
+	for _, e := range report.Covered {
+		covered = append(covered, e.String())
+	}
+	if expected := []string{"GET /users (listUsers)", "GET /users/{id} (getUser)"}; !reflect.DeepEqual(covered, expected) {
+		t.Fatalf("unexpected covered %v", covered)
+	}
+	if !reflect.DeepEqual(report.Covered[1].Statuses, []int{200}) {
+		t.Fatalf("unexpected statuses %v", report.Covered[1].Statuses)
+	}
+	if len(report.Uncovered) != 2 || report.Uncovered[0].OperationID != "createUser" || report.Uncovered[1].OperationID != "deleteUser" {
+		t.Fatalf("unexpected uncovered %v", report.Uncovered)
+	}
+	if !reflect.DeepEqual(report.Undocumented, []string{"GET /v1/groups"}) {
+		t.Fatalf("unexpected undocumented %v", report.Undocumented)
+	}
+	if report.Percent() != 50 {
+		t.Fatalf("expected 50%% coverage got %f", report.Percent())
+	}
+	if s := report.String(); !strings.HasPrefix(s, "API coverage: 50.0% (2 of 4 operations)\n") {
+		t.Fatalf("unexpected report %s", s)
+	}
+
+	rt := &recordingT{TB: t}
+	report.Require(rt, 50)
+	if errs := rt.Errors(); len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+
+	report.Require(rt, 100)
+	errs := rt.Errors()
+	if len(errs) != 1 || !strings.Contains(errs[0], "API coverage 50.0% is below 100.0%") || !strings.Contains(errs[0], "DELETE /users/{id} (deleteUser)") {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+}
@@ -0,0 +1,144 @@
+package httptester
+
+import (
+	"errors"
+	"mime"
+	"net/url"
+	"strings"
+)
+
+// csrfSource is a place a framework puts its CSRF token and the header it
+// expects the token back in.
+type csrfSource struct {
+	name   string
+	header string
+}
+
+// csrfInputs are hidden form inputs, checked first.
+var csrfInputs = []csrfSource{
+	{"csrfmiddlewaretoken", "X-CSRFToken"},
+	{"authenticity_token", "X-CSRF-Token"},
+	{"__RequestVerificationToken", "RequestVerificationToken"},
+	{"_csrf", "X-CSRF-Token"},
+	{"_token", "X-CSRF-Token"},
+	{"csrf_token", "X-CSRF-Token"},
+	{"csrf", "X-CSRF-Token"},
+}
+
+// csrfMetas are <meta name=... content=...> tags. Spring names the header in
+// a _csrf_header meta tag.
+var csrfMetas = []csrfSource{
+	{"csrf-token", "X-CSRF-Token"},
+	{"_csrf", "X-CSRF-Token"},
+	{"csrf_token", "X-CSRF-Token"},
+}
+
+// csrfCookies are double submit cookies.
+var csrfCookies = []csrfSource{
+	{"XSRF-TOKEN", "X-XSRF-TOKEN"},
+	{"csrftoken", "X-CSRFToken"},
+	{"_csrf", "X-CSRF-Token"},
+	{"csrf_token", "X-CSRF-Token"},
+}
+
+type csrfToken struct {
+	value  string
+	header string
+	// field is the form field name if the token came from a hidden input.
+	field string
+}
+
+// csrfToken looks for a token in hidden inputs and meta tags of an HTML
+// body and in cookies set by the response.
+func (r *Response) csrfToken() (csrfToken, bool) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/html" || mediaType == "application/xhtml+xml" {
+		doc := parseHTMLDocument(string(r.Body))
+
+		for _, source := range csrfInputs {
+			sel, _ := compileCSSSelector("input[name='" + source.name + "']")
+			for _, n := range sel.selectFrom(doc) {
+				if value, ok := n.attr("value"); ok && value != "" {
+					return csrfToken{value: value, header: source.header, field: source.name}, true
+				}
+			}
+		}
+
+		header := ""
+		sel, _ := compileCSSSelector("meta[name='_csrf_header']")
+		if nodes := sel.selectFrom(doc); len(nodes) > 0 {
+			header, _ = nodes[0].attr("content")
+		}
+
+		for _, source := range csrfMetas {
+			sel, _ := compileCSSSelector("meta[name='" + source.name + "']")
+			for _, n := range sel.selectFrom(doc) {
+				if value, ok := n.attr("content"); ok && value != "" {
+					if header == "" {
+						header = source.header
+					}
+					return csrfToken{value: value, header: header}, true
+				}
+			}
+		}
+	}
+
+	for _, source := range csrfCookies {
+		if cookie := r.SetCookie(source.name); cookie != nil && cookie.Value != "" {
+			value, err := url.QueryUnescape(cookie.Value)
+			if err != nil {
+				value = cookie.Value
+			}
+			return csrfToken{value: value, header: source.header}, true
+		}
+	}
+
+	return csrfToken{}, false
+}
+
+// CSRFToken returns the CSRF token found in a hidden input, a meta tag or a
+// cookie of the response, or an empty string.
+func (r *Response) CSRFToken() string {
+	token, _ := r.csrfToken()
+	return token.value
+}
+
+// CSRF copies the CSRF token of prior to the request. Tokens from hidden
+// inputs are added as a form field if the request already has a form body
+// (so call it after Form); otherwise the token is sent in the header the
+// framework it came from expects, e.g. X-CSRFToken for Django,
+// X-XSRF-TOKEN for an XSRF-TOKEN cookie or the header named by Spring's
+// _csrf_header meta tag.
+func (b *ReqBuilder) CSRF(prior *Response) *ReqBuilder {
+	b.helper()
+
+	token, ok := prior.csrfToken()
+	if !ok {
+		b.onError(errors.New("no CSRF token found in response"))
+		return b
+	}
+
+	contentType, _, _ := mime.ParseMediaType(b.headers.Get("Content-Type"))
+	if token.field != "" && contentType == "application/x-www-form-urlencoded" {
+		body, err := b.readBody()
+		if err != nil {
+			b.onError(err)
+			return b
+		}
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			b.onError(err)
+			return b
+		}
+		if !form.Has(token.field) {
+			encoded := url.QueryEscape(token.field) + "=" + url.QueryEscape(token.value)
+			if len(body) > 0 {
+				encoded = string(body) + "&" + encoded
+			}
+			return b.Body(strings.NewReader(encoded))
+		}
+		return b
+	}
+
+	return b.Header(token.header, token.value)
+}
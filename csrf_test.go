@@ -0,0 +1,60 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestReqBuilderCSRF(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/django":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<form method="post"><input type="hidden" name="csrfmiddlewaretoken" value="dj-123"></form>`))
+		case "/rails":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<head><meta name="csrf-param" content="authenticity_token"><meta name="csrf-token" content="rails-456"></head>`))
+		case "/spring":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<head><meta name="_csrf" content="spring-789"><meta name="_csrf_header" content="X-Spring-CSRF"></head>`))
+		case "/spa":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Add("Set-Cookie", "XSRF-TOKEN=spa%3D1; Path=/")
+			w.Write([]byte(`{}`))
+		case "/none":
+			w.Write([]byte(`nothing`))
+		default:
+			r.ParseForm()
+			w.Write([]byte(r.Header.Get("X-CSRFToken") + "|" + r.Header.Get("X-CSRF-Token") + "|" + r.Header.Get("X-Spring-CSRF") + "|" + r.Header.Get("X-XSRF-TOKEN") + "|" + r.PostForm.Encode()))
+		}
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	django := c.GET("/django").Do()
+	if token := django.CSRFToken(); token != "dj-123" {
+		t.Fatalf("unexpected token %q", token)
+	}
+
+	c.POST("/submit").Form("name", "x").CSRF(django).Do().Eq("||||csrfmiddlewaretoken=dj-123&name=x")
+	c.POST("/submit").JSON(map[string]string{}).CSRF(django).Do().Eq("dj-123||||")
+	c.POST("/submit").CSRF(c.GET("/rails").Do()).Do().Eq("|rails-456|||")
+	c.POST("/submit").CSRF(c.GET("/spring").Do()).Do().Eq("||spring-789||")
+	c.POST("/submit").CSRF(c.GET("/spa").Do()).Do().Eq("|||spa=1|")
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.POST("/submit").CSRF(b.GET("/none").Do())
+
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "no CSRF token found in response") {
+		t.Fatalf("expected missing token error got %v", errs)
+	}
+}
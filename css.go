@@ -0,0 +1,438 @@
+package httptester
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// cssSelector is a compiled selector list, see HTMLDocument.Select.
+type cssSelector []cssComplex
+
+// cssComplex is a sequence of compound selectors. combinators[i] (' ', '>',
+// '+' or '~') joins compounds[i] and compounds[i+1].
+type cssComplex struct {
+	compounds   []cssCompound
+	combinators []byte
+}
+
+type cssCompound struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   []cssAttr
+	pseudos []cssPseudo
+}
+
+type cssAttr struct {
+	name  string
+	op    string
+	value string
+}
+
+type cssPseudo struct {
+	name string
+	// nth matches positions a*k+b for nth-child.
+	a, b int
+	not  cssSelector
+}
+
+func compileCSSSelector(selector string) (cssSelector, error) {
+	list := cssSelector{}
+
+	for _, part := range splitXPath(selector, ',') {
+		seq, err := compileCSSComplex(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, seq)
+	}
+
+	return list, nil
+}
+
+func isCSSSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func compileCSSComplex(s string) (cssComplex, error) {
+	seq := cssComplex{}
+	if s == "" {
+		return seq, errors.New("empty selector")
+	}
+
+	i := 0
+	for i < len(s) {
+		space := false
+		for i < len(s) && isCSSSpace(s[i]) {
+			space = true
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+
+		if len(seq.compounds) > 0 {
+			combinator := byte(' ')
+			if strings.IndexByte(">+~", s[i]) >= 0 {
+				combinator = s[i]
+				i++
+				for i < len(s) && isCSSSpace(s[i]) {
+					i++
+				}
+			} else if !space {
+				return seq, fmt.Errorf("unexpected %q", s[i:])
+			}
+			seq.combinators = append(seq.combinators, combinator)
+		}
+
+		compound, next, err := compileCSSCompound(s, i)
+		if err != nil {
+			return seq, err
+		}
+		seq.compounds = append(seq.compounds, compound)
+		i = next
+	}
+
+	if len(seq.compounds) == 0 || len(seq.combinators) >= len(seq.compounds) {
+		return seq, errors.New("selector ends with a combinator")
+	}
+
+	return seq, nil
+}
+
+func isCSSIdent(c byte) bool {
+	return isASCIILetter(c) || '0' <= c && c <= '9' || c == '-' || c == '_'
+}
+
+func cssIdent(s string, i int) (string, int) {
+	start := i
+	for i < len(s) && isCSSIdent(s[i]) {
+		i++
+	}
+	return s[start:i], i
+}
+
+func compileCSSCompound(s string, i int) (cssCompound, int, error) {
+	compound := cssCompound{}
+	start := i
+
+	if i < len(s) && s[i] == '*' {
+		compound.tag = "*"
+		i++
+	} else if i < len(s) && isASCIILetter(s[i]) {
+		compound.tag, i = cssIdent(s, i)
+		compound.tag = strings.ToLower(compound.tag)
+	}
+
+	for i < len(s) && !isCSSSpace(s[i]) && strings.IndexByte(">+~", s[i]) < 0 {
+		var name string
+		switch s[i] {
+		case '#':
+			name, i = cssIdent(s, i+1)
+			if name == "" {
+				return compound, i, errors.New("expected id after #")
+			}
+			compound.id = name
+
+		case '.':
+			name, i = cssIdent(s, i+1)
+			if name == "" {
+				return compound, i, errors.New("expected class after .")
+			}
+			compound.classes = append(compound.classes, name)
+
+		case '[':
+			end := matchingClose(s[i:], '[', ']')
+			if end < 0 {
+				return compound, i, errors.New("unterminated [")
+			}
+			attr, err := compileCSSAttr(s[i+1 : i+end])
+			if err != nil {
+				return compound, i, err
+			}
+			compound.attrs = append(compound.attrs, attr)
+			i += end + 1
+
+		case ':':
+			name, i = cssIdent(s, i+1)
+			pseudo := cssPseudo{name: strings.ToLower(name)}
+			arg := ""
+			if i < len(s) && s[i] == '(' {
+				end := matchingClose(s[i:], '(', ')')
+				if end < 0 {
+					return compound, i, errors.New("unterminated (")
+				}
+				arg = strings.TrimSpace(s[i+1 : i+end])
+				i += end + 1
+			}
+
+			switch pseudo.name {
+			case "first-child":
+				pseudo.name, pseudo.a, pseudo.b = "nth-child", 0, 1
+			case "last-child":
+			case "nth-child":
+				a, b, err := parseCSSNth(arg)
+				if err != nil {
+					return compound, i, err
+				}
+				pseudo.a, pseudo.b = a, b
+			case "not":
+				not, err := compileCSSSelector(arg)
+				if err != nil {
+					return compound, i, err
+				}
+				pseudo.not = not
+			default:
+				return compound, i, fmt.Errorf("unsupported pseudo-class :%s", name)
+			}
+			compound.pseudos = append(compound.pseudos, pseudo)
+
+		default:
+			return compound, i, fmt.Errorf("unexpected %q", s[i:])
+		}
+	}
+
+	if i == start {
+		return compound, i, fmt.Errorf("unexpected %q", s[i:])
+	}
+
+	return compound, i, nil
+}
+
+func compileCSSAttr(s string) (cssAttr, error) {
+	attr := cssAttr{}
+
+	for _, op := range []string{"~=", "^=", "$=", "*=", "|=", "="} {
+		if i := strings.Index(s, op); i >= 0 {
+			attr.name = strings.ToLower(strings.TrimSpace(s[:i]))
+			attr.op = op
+			attr.value = xpathLiteral(s[i+len(op):])
+			break
+		}
+	}
+	if attr.op == "" {
+		attr.name = strings.ToLower(strings.TrimSpace(s))
+	}
+
+	if attr.name == "" {
+		return attr, fmt.Errorf("invalid attribute selector [%s]", s)
+	}
+
+	return attr, nil
+}
+
+// parseCSSNth parses the argument of :nth-child: odd, even, b or an+b.
+func parseCSSNth(arg string) (int, int, error) {
+	arg = strings.ReplaceAll(strings.ToLower(arg), " ", "")
+	switch arg {
+	case "odd":
+		return 2, 1, nil
+	case "even":
+		return 2, 0, nil
+	}
+
+	n := strings.IndexByte(arg, 'n')
+	if n < 0 {
+		b, err := strconv.Atoi(arg)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid :nth-child(%s)", arg)
+		}
+		return 0, b, nil
+	}
+
+	a := 1
+	switch prefix := arg[:n]; prefix {
+	case "", "+":
+	case "-":
+		a = -1
+	default:
+		var err error
+		if a, err = strconv.Atoi(prefix); err != nil {
+			return 0, 0, fmt.Errorf("invalid :nth-child(%s)", arg)
+		}
+	}
+
+	b := 0
+	if rest := strings.TrimPrefix(arg[n+1:], "+"); rest != "" {
+		var err error
+		if b, err = strconv.Atoi(rest); err != nil {
+			return 0, 0, fmt.Errorf("invalid :nth-child(%s)", arg)
+		}
+	}
+
+	return a, b, nil
+}
+
+// selectFrom returns the descendant elements of root matching the selector
+// in document order.
+func (sel cssSelector) selectFrom(root *domNode) []*domNode {
+	nodes := []*domNode{}
+	for _, n := range root.descendantsOrSelf()[1:] {
+		if sel.matches(n) {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+func (sel cssSelector) matches(n *domNode) bool {
+	for _, seq := range sel {
+		if seq.matches(n, len(seq.compounds)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c cssComplex) matches(n *domNode, i int) bool {
+	if !c.compounds[i].matches(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+
+	switch c.combinators[i-1] {
+	case ' ':
+		for p := n.parent; p != nil && p.name != ""; p = p.parent {
+			if c.matches(p, i-1) {
+				return true
+			}
+		}
+	case '>':
+		return n.parent != nil && n.parent.name != "" && c.matches(n.parent, i-1)
+	case '+':
+		siblings, index := n.elementSiblings()
+		return index > 0 && c.matches(siblings[index-1], i-1)
+	case '~':
+		siblings, index := n.elementSiblings()
+		for _, s := range siblings[:index] {
+			if c.matches(s, i-1) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// elementSiblings returns the element children of n's parent and the index
+// of n among them.
+func (n *domNode) elementSiblings() ([]*domNode, int) {
+	if n.parent == nil {
+		return []*domNode{n}, 0
+	}
+
+	siblings := []*domNode{}
+	index := 0
+	for _, c := range n.parent.children {
+		if c.kind != elementNode {
+			continue
+		}
+		if c == n {
+			index = len(siblings)
+		}
+		siblings = append(siblings, c)
+	}
+
+	return siblings, index
+}
+
+func (c cssCompound) matches(n *domNode) bool {
+	if n.kind != elementNode || n.name == "" {
+		return false
+	}
+
+	if c.tag != "" && c.tag != "*" && c.tag != n.name {
+		return false
+	}
+
+	if c.id != "" {
+		if id, _ := n.attr("id"); id != c.id {
+			return false
+		}
+	}
+
+	if len(c.classes) > 0 {
+		class, _ := n.attr("class")
+		classes := strings.Fields(class)
+		for _, want := range c.classes {
+			found := false
+			for _, have := range classes {
+				if have == want {
+					found = true
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+
+	for _, a := range c.attrs {
+		if !a.matches(n) {
+			return false
+		}
+	}
+
+	for _, p := range c.pseudos {
+		if !p.matches(n) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (a cssAttr) matches(n *domNode) bool {
+	value, ok := n.attr(a.name)
+	if !ok {
+		return false
+	}
+
+	switch a.op {
+	case "":
+		return true
+	case "=":
+		return value == a.value
+	case "~=":
+		for _, field := range strings.Fields(value) {
+			if field == a.value {
+				return true
+			}
+		}
+		return false
+	case "^=":
+		return a.value != "" && strings.HasPrefix(value, a.value)
+	case "$=":
+		return a.value != "" && strings.HasSuffix(value, a.value)
+	case "*=":
+		return a.value != "" && strings.Contains(value, a.value)
+	case "|=":
+		return value == a.value || strings.HasPrefix(value, a.value+"-")
+	}
+
+	return false
+}
+
+func (p cssPseudo) matches(n *domNode) bool {
+	switch p.name {
+	case "nth-child":
+		_, index := n.elementSiblings()
+		position := index + 1
+		if p.a == 0 {
+			return position == p.b
+		}
+		k := position - p.b
+		return k%p.a == 0 && k/p.a >= 0
+	case "last-child":
+		siblings, index := n.elementSiblings()
+		return index == len(siblings)-1
+	case "not":
+		return !p.not.matches(n)
+	}
+
+	return false
+}
@@ -0,0 +1,112 @@
+package httptester
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"mime"
+	"slices"
+)
+
+func isCSV(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv", "application/csv", "text/comma-separated-values":
+		return true
+	}
+	return false
+}
+
+// CSV parses a text/csv body into records. The first record is the header
+// row used by CSVHeader and CSVCell.
+func (r *Response) CSV() [][]string {
+	r.helper()
+
+	if !r.hasBody() {
+		return nil
+	}
+
+	if contentType := r.Header.Get("Content-Type"); !isCSV(contentType) {
+		r.err(fmt.Errorf("Content-Type is not text/csv, got %s: %s", contentType, r.bodyExcerpt()))
+	}
+
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(r.Body, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		r.err(fmt.Errorf("body is not CSV: %w: %s", err, r.bodyExcerpt()))
+		return nil
+	}
+	return records
+}
+
+// CSVHeader checks the header row of a CSV body.
+func (r *Response) CSVHeader(columns ...string) *Response {
+	r.helper()
+	defer r.track("CSVHeader", columns)()
+
+	records := r.CSV()
+	if records == nil {
+		if r.hasBody() {
+			r.err(fmt.Errorf("expected CSV header %q got empty body", columns))
+		}
+		return r
+	}
+
+	if !slices.Equal(records[0], columns) {
+		r.err(fmt.Errorf("expected CSV header %q got %q", columns, records[0]))
+	}
+
+	return r
+}
+
+// CSVRowCount checks the number of rows of a CSV body, not counting the
+// header row.
+func (r *Response) CSVRowCount(n int) *Response {
+	r.helper()
+	defer r.track("CSVRowCount", n)()
+
+	records := r.CSV()
+	actual := max(len(records)-1, 0)
+	if actual != n {
+		r.err(fmt.Errorf("expected %d CSV rows got %d", n, actual))
+	}
+
+	return r
+}
+
+// CSVCell checks the cell in the named column of the zero based data row
+// (the header row is not counted).
+func (r *Response) CSVCell(row int, column string, expected string) *Response {
+	r.helper()
+	defer r.track("CSVCell", row, column, expected)()
+
+	records := r.CSV()
+	if len(records) == 0 {
+		if r.hasBody() {
+			r.err(fmt.Errorf("expected CSV row %d got empty body", row))
+		}
+		return r
+	}
+
+	col := slices.Index(records[0], column)
+	if col < 0 {
+		r.err(fmt.Errorf("CSV has no column %q, header is %q", column, records[0]))
+		return r
+	}
+	if row < 0 || row+1 >= len(records) {
+		r.err(fmt.Errorf("CSV has no row %d, it has %d rows", row, len(records)-1))
+		return r
+	}
+
+	record := records[row+1]
+	if col >= len(record) {
+		r.err(fmt.Errorf("CSV row %d has no column %q: %q", row, column, record))
+		return r
+	}
+	if record[col] != expected {
+		r.err(fmt.Errorf("expected CSV row %d column %q to be %q got %q", row, column, expected, record[col]))
+	}
+
+	return r
+}
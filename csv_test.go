@@ -0,0 +1,73 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestResponseCSV(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/export":
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Write([]byte("\xef\xbb\xbfid,name,email\n1,Alice,alice@example.com\n2,\"Bob, Jr.\",bob@example.com\n3,Carol\n"))
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{}`))
+		case "/invalid":
+			w.Header().Set("Content-Type", "text/csv")
+			w.Write([]byte("a,\"b\nc"))
+		}
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	res := c.GET("/export").Do().
+		CSVHeader("id", "name", "email").
+		CSVRowCount(3).
+		CSVCell(0, "email", "alice@example.com").
+		CSVCell(1, "name", "Bob, Jr.")
+
+	if records := res.CSV(); len(records) != 4 || records[2][1] != "Bob, Jr." {
+		t.Fatalf("unexpected records %q", records)
+	}
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.GET("/export").Do().
+		CSVHeader("id", "name").
+		CSVRowCount(2).
+		CSVCell(0, "name", "Bob").
+		CSVCell(0, "phone", "").
+		CSVCell(5, "name", "").
+		CSVCell(2, "email", "")
+	b.GET("/json").Do().CSV()
+	b.GET("/invalid").Do().CSV()
+
+	expected := []string{
+		`expected CSV header ["id" "name"] got ["id" "name" "email"]`,
+		"expected 2 CSV rows got 3",
+		`expected CSV row 0 column "name" to be "Bob" got "Alice"`,
+		`CSV has no column "phone", header is ["id" "name" "email"]`,
+		"CSV has no row 5, it has 3 rows",
+		`CSV row 2 has no column "email": ["3" "Carol"]`,
+		"Content-Type is not text/csv, got application/json: {}",
+		"body is not CSV: record on line 1",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors got %v", len(expected), errs)
+	}
+	for i, msg := range expected {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
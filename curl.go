@@ -0,0 +1,46 @@
+package httptester
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+func curlCommand(method string, url string, header http.Header, body []byte, follow bool) string {
+	parts := []string{"curl"}
+
+	if follow {
+		parts = append(parts, "-L")
+	}
+
+	if method != "GET" || body != nil {
+		parts = append(parts, "-X", shellQuote(method))
+	}
+
+	parts = append(parts, shellQuote(url))
+
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		for _, v := range header[k] {
+			parts = append(parts, "-H", shellQuote(k+": "+v))
+		}
+	}
+
+	if body != nil {
+		parts = append(parts, "--data-binary", shellQuote(string(body)))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,72 @@
+package httptester_test
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func digestServer(t *testing.T, algorithm string, newHash func() hash.Hash) *httptest.Server {
+	paramRegexp := regexp.MustCompile(`(\w+)=(?:"([^"]*)"|([^,\s]*))`)
+
+	h := func(s string) string {
+		hash := newHash()
+		hash.Write([]byte(s))
+		return hex.EncodeToString(hash.Sum(nil))
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		params := map[string]string{}
+		for _, m := range paramRegexp.FindAllStringSubmatch(r.Header.Get("Authorization"), -1) {
+			params[m[1]] = m[2] + m[3]
+		}
+
+		ha1 := h("ann:test realm:secret")
+		ha2 := h(r.Method + ":" + r.URL.RequestURI())
+		expected := h(ha1 + ":n0nce:" + params["nc"] + ":" + params["cnonce"] + ":auth:" + ha2)
+
+		if params["response"] != expected || params["uri"] != r.URL.RequestURI() || params["opaque"] != "op" {
+			w.Header().Add("WWW-Authenticate", `Basic realm="test realm"`)
+			w.Header().Add("WWW-Authenticate", `Digest realm="test realm", qop="auth,auth-int", nonce="n0nce", opaque="op", algorithm=`+algorithm)
+			w.WriteHeader(401)
+			return
+		}
+
+		w.Write(body)
+	}))
+}
+
+func TestReqBuilderDigest(t *testing.T) {
+	for _, tc := range []struct {
+		algorithm string
+		newHash   func() hash.Hash
+	}{
+		{"MD5", md5.New},
+		{"SHA-256", sha256.New},
+	} {
+		t.Run(tc.algorithm, func(t *testing.T) {
+			server := digestServer(t, tc.algorithm, tc.newHash)
+			defer server.Close()
+
+			c := httptester.New(t, server.URL)
+
+			c.POST("/private").Q("a", "1").Digest("ann", "secret").Body(strings.NewReader("hello")).Do().
+				Status(200).
+				Eq("hello")
+
+			c.GET("/private").Digest("ann", "wrong").Do().Status(401)
+			c.GET("/private").Do().Status(401)
+		})
+	}
+}
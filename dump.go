@@ -0,0 +1,49 @@
+package httptester
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+func dumpHeader(w io.Writer, header http.Header) {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		for _, v := range header[k] {
+			fmt.Fprintf(w, "%s: %s\n", k, v)
+		}
+	}
+}
+
+func dumpBody(w io.Writer, contentType string, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w)
+
+	if strings.Contains(contentType, "json") {
+		indented := &bytes.Buffer{}
+		if err := json.Indent(indented, body, "", "  "); err == nil {
+			fmt.Fprintln(w, indented.String())
+			return
+		}
+	}
+
+	if !utf8.Valid(body) {
+		fmt.Fprintf(w, "<%d bytes of binary data>\n", len(body))
+		return
+	}
+
+	fmt.Fprintln(w, string(body))
+}
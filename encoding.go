@@ -0,0 +1,255 @@
+package httptester
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// ContentDecoder returns a reader of the decoded content of r.
+type ContentDecoder func(r io.Reader) (io.Reader, error)
+
+// ContentEncoder returns a writer that encodes the content written to it
+// into w. The content is flushed when the writer is closed.
+type ContentEncoder func(w io.Writer) (io.WriteCloser, error)
+
+var (
+	contentDecodersMu sync.RWMutex
+	contentDecoders   = map[string]ContentDecoder{
+		"gzip":    gzipDecoder,
+		"x-gzip":  gzipDecoder,
+		"deflate": deflateDecoder,
+		"br":      brotliDecoder,
+		"zstd":    zstdDecoder,
+	}
+)
+
+// RegisterContentDecoder registers the decoder used for response bodies
+// with Content-Encoding encoding. gzip, deflate, br and zstd are built in.
+func RegisterContentDecoder(encoding string, decoder ContentDecoder) {
+	contentDecodersMu.Lock()
+	defer contentDecodersMu.Unlock()
+
+	contentDecoders[strings.ToLower(encoding)] = decoder
+}
+
+var (
+	contentEncodersMu sync.RWMutex
+	contentEncoders   = map[string]ContentEncoder{
+		"gzip": func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		},
+		"deflate": func(w io.Writer) (io.WriteCloser, error) {
+			return zlib.NewWriter(w), nil
+		},
+		"br": func(w io.Writer) (io.WriteCloser, error) {
+			return brotli.NewWriter(w), nil
+		},
+		"zstd": func(w io.Writer) (io.WriteCloser, error) {
+			return zstd.NewWriter(w)
+		},
+	}
+)
+
+// RegisterContentEncoder registers the encoder used by EncodeBody for
+// encoding. gzip, deflate, br and zstd are built in.
+func RegisterContentEncoder(encoding string, encoder ContentEncoder) {
+	contentEncodersMu.Lock()
+	defer contentEncodersMu.Unlock()
+
+	contentEncoders[strings.ToLower(encoding)] = encoder
+}
+
+func encodeContent(body []byte, encoding string) ([]byte, error) {
+	contentEncodersMu.RLock()
+	encoder, ok := contentEncoders[strings.ToLower(encoding)]
+	contentEncodersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no content encoder registered for %s", encoding)
+	}
+
+	buf := &bytes.Buffer{}
+	w, err := encoder(buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// curlEncoders are the commands that encode the body in curl commands.
+var curlEncoders = map[string]string{
+	"gzip": "gzip -c",
+	"br":   "brotli -c",
+	"zstd": "zstd -c",
+}
+
+// EncodeBody compresses the request body with encoding (see
+// RegisterContentEncoder) and sets the Content-Encoding header.
+func (b *ReqBuilder) EncodeBody(encoding string) *ReqBuilder {
+	b.bodyEncoding = strings.ToLower(encoding)
+	return b.Header("Content-Encoding", b.bodyEncoding)
+}
+
+// GzipBody compresses the request body with gzip.
+func (b *ReqBuilder) GzipBody() *ReqBuilder {
+	return b.EncodeBody("gzip")
+}
+
+// curl returns the curl command for the request with secrets redacted. An
+// encoded body is piped through a command that encodes it if there is one.
+func (b *ReqBuilder) curl(method string, url string, header http.Header, body []byte) string {
+	url, header, body = b.redactor.text(url), b.redactor.header(header), b.redactor.body(body)
+
+	command, ok := curlEncoders[b.bodyEncoding]
+	if !ok || body == nil {
+		return curlCommand(method, url, header, body, !b.noFollow)
+	}
+
+	return "printf '%s' " + shellQuote(string(body)) + " | " + command + " | " + curlCommand(method, url, header, []byte("@-"), !b.noFollow)
+}
+
+func contentDecoder(encoding string) (ContentDecoder, bool) {
+	contentDecodersMu.RLock()
+	defer contentDecodersMu.RUnlock()
+
+	decoder, ok := contentDecoders[encoding]
+	return decoder, ok
+}
+
+func gzipDecoder(r io.Reader) (io.Reader, error) {
+	return gzip.NewReader(r)
+}
+
+// deflateDecoder accepts zlib wrapped data as specified and raw deflate data
+// as sent by some servers.
+func deflateDecoder(r io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if zr, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+		return zr, nil
+	}
+
+	return flate.NewReader(bytes.NewReader(data)), nil
+}
+
+func brotliDecoder(r io.Reader) (io.Reader, error) {
+	return brotli.NewReader(r), nil
+}
+
+// zstdDecoder decodes the whole content at once so the decoder, which runs
+// goroutines, can be closed.
+func zstdDecoder(r io.Reader) (io.Reader, error) {
+	zr, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(data), nil
+}
+
+// contentEncodings returns the codings of a Content-Encoding header in the
+// order they were applied.
+func contentEncodings(header string) []string {
+	encodings := []string{}
+	for _, e := range strings.Split(header, ",") {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if e != "" && e != "identity" {
+			encodings = append(encodings, e)
+		}
+	}
+	return encodings
+}
+
+// decodeContent decodes body encoded with encodings. ok is false if there is
+// no decoder for one of the encodings, in which case body is returned as is.
+func decodeContent(body []byte, encodings []string) (decoded []byte, ok bool, err error) {
+	decoders := make([]ContentDecoder, len(encodings))
+	for i, encoding := range encodings {
+		decoder, found := contentDecoder(encoding)
+		if !found {
+			return body, false, nil
+		}
+		decoders[i] = decoder
+	}
+
+	decoded = body
+	for i := len(encodings) - 1; i >= 0; i-- {
+		r, err := decoders[i](bytes.NewReader(decoded))
+		if err != nil {
+			return nil, false, fmt.Errorf("cannot decode %s response body: %w", encodings[i], err)
+		}
+		decoded, err = io.ReadAll(r)
+		if err != nil {
+			return nil, false, fmt.Errorf("cannot decode %s response body: %w", encodings[i], err)
+		}
+	}
+
+	return decoded, true, nil
+}
+
+// AcceptEncoding sets the Accept-Encoding header. Setting it explicitly
+// disables the transparent gzip decompression of http.Transport so the
+// response keeps its Content-Encoding header and its encoded size is known.
+// The body is then decoded by the Response.
+func (b *ReqBuilder) AcceptEncoding(encodings ...string) *ReqBuilder {
+	return b.Header("Accept-Encoding", strings.Join(encodings, ", "))
+}
+
+// Encoding returns the content codings of the response, e.g. gzip, also if
+// the body was transparently decompressed by http.Transport.
+func (r *Response) Encoding() string {
+	return strings.Join(r.encodings, ", ")
+}
+
+// EncodedSize returns the size of the body as received, before it was
+// decoded, or -1 if http.Transport decompressed it transparently.
+func (r *Response) EncodedSize() int {
+	return r.encodedSize
+}
+
+// DecodedSize returns the size of the decoded body.
+func (r *Response) DecodedSize() int {
+	return len(r.Body)
+}
+
+// Encoded checks that the response was encoded with encoding (e.g. gzip, br
+// or zstd).
+func (r *Response) Encoded(encoding string) *Response {
+	r.helper()
+	defer r.track("Encoded", encoding)()
+
+	if actual := r.Encoding(); !strings.EqualFold(actual, encoding) {
+		if actual == "" {
+			actual = "identity"
+		}
+		r.err(fmt.Errorf("expected %s content encoding got %s", encoding, actual))
+	}
+
+	return r
+}
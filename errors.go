@@ -0,0 +1,80 @@
+package httptester
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrAssertion matches every failed assertion with errors.Is.
+var ErrAssertion = errors.New("assertion failed")
+
+// AssertionError is the error passed to the onError callback for a failed
+// assertion. Err is the failure, e.g. a *StatusError, *BodyMismatchError or
+// *HeaderError, and can be checked with errors.As. With a Redactor the
+// message and the fields of these failures are redacted, Request and
+// Response are the originals.
+type AssertionError struct {
+	Request  *http.Request
+	Response *Response
+	Err      error
+	msg      string
+}
+
+func (e *AssertionError) Error() string {
+	return e.msg
+}
+
+func (e *AssertionError) Unwrap() error {
+	return e.Err
+}
+
+func (e *AssertionError) Is(target error) bool {
+	return target == ErrAssertion
+}
+
+// StatusError is the failure of Status, StatusNot and the 206 check of the
+// range assertions. Expected are the expected statuses, or the unexpected
+// ones if Not is set.
+type StatusError struct {
+	Expected []int
+	Actual   int
+	Not      bool
+	Body     []byte
+}
+
+func (e *StatusError) Error() string {
+	if e.Not {
+		return fmt.Sprintf("expected status not in %v got %d: %s", e.Expected, e.Actual, bodyExcerpt(e.Body))
+	}
+	return fmt.Sprintf("expected status %v got %d: %s", e.Expected, e.Actual, bodyExcerpt(e.Body))
+}
+
+// BodyMismatchError is the failure of assertions comparing the body with
+// an expected string, pattern or JSON value, e.g. Eq, Contains, Matches and
+// JSONEq.
+type BodyMismatchError struct {
+	Assertion string
+	Expected  string
+	Actual    []byte
+	msg       string
+}
+
+func (e *BodyMismatchError) Error() string {
+	return e.msg
+}
+
+// HeaderError is the failure of header assertions, e.g. HeaderEq,
+// HeaderAbsent, AllowsOrigin, CookieSecure and MaxAge. Actual are all values
+// of the header.
+type HeaderError struct {
+	Assertion string
+	Key       string
+	Expected  string
+	Actual    []string
+	msg       string
+}
+
+func (e *HeaderError) Error() string {
+	return e.msg
+}
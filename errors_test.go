@@ -0,0 +1,129 @@
+package httptester_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bancek/httptester"
+)
+
+func TestAssertionErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Version", "1")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("not found"))
+	}))
+	defer server.Close()
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	res := b.GET("/users/1").Do().
+		Status(200).
+		Eq("found").
+		HeaderEq("X-Version", "2").
+		BodyLen(1)
+
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 errors got %v", errs)
+	}
+	for _, err := range errs {
+		if !errors.Is(err, httptester.ErrAssertion) {
+			t.Errorf("expected %v to be ErrAssertion", err)
+		}
+	}
+
+	var assertionErr *httptester.AssertionError
+	if !errors.As(errs[0], &assertionErr) {
+		t.Fatalf("expected AssertionError got %T", errs[0])
+	}
+	if assertionErr.Request.URL.Path != "/users/1" || assertionErr.Response != res {
+		t.Errorf("unexpected request or response %v %v", assertionErr.Request.URL, assertionErr.Response)
+	}
+
+	var statusErr *httptester.StatusError
+	if !errors.As(errs[0], &statusErr) {
+		t.Fatalf("expected StatusError got %T", errors.Unwrap(errs[0]))
+	}
+	if len(statusErr.Expected) != 1 || statusErr.Expected[0] != 200 || statusErr.Actual != 404 || string(statusErr.Body) != "not found" {
+		t.Errorf("unexpected status error %+v", statusErr)
+	}
+
+	var bodyErr *httptester.BodyMismatchError
+	if !errors.As(errs[1], &bodyErr) {
+		t.Fatalf("expected BodyMismatchError got %T", errors.Unwrap(errs[1]))
+	}
+	if bodyErr.Assertion != "Eq" || bodyErr.Expected != "found" || string(bodyErr.Actual) != "not found" {
+		t.Errorf("unexpected body error %+v", bodyErr)
+	}
+
+	var headerErr *httptester.HeaderError
+	if !errors.As(errs[2], &headerErr) {
+		t.Fatalf("expected HeaderError got %T", errors.Unwrap(errs[2]))
+	}
+	if headerErr.Assertion != "HeaderEq" || headerErr.Key != "X-Version" || headerErr.Expected != "2" || len(headerErr.Actual) != 1 || headerErr.Actual[0] != "1" {
+		t.Errorf("unexpected header error %+v", headerErr)
+	}
+
+	if errors.As(errs[3], &statusErr) || errors.As(errs[3], &bodyErr) || errors.As(errs[3], &headerErr) {
+		t.Errorf("expected untyped error got %T", errors.Unwrap(errs[3]))
+	}
+}
+
+func TestAssertionErrorsHeaderChecks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "https://other.example")
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Set-Cookie", "session=1; Path=/")
+		w.Write([]byte("full"))
+	}))
+	defer server.Close()
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.GET("/").Do().
+		ContentRange(0, 1, 4).
+		AllowsOrigin("https://app.example").
+		CookieSecure("session").
+		HSTS(time.Hour, false).
+		MaxAge(120).
+		SecurityHeaders()
+
+	if len(errs) != 6 {
+		t.Fatalf("expected 6 errors got %v", errs)
+	}
+
+	var statusErr *httptester.StatusError
+	if !errors.As(errs[0], &statusErr) || statusErr.Expected[0] != 206 || statusErr.Actual != 200 {
+		t.Errorf("expected StatusError got %v", errs[0])
+	}
+
+	for i, expected := range []struct {
+		assertion string
+		key       string
+		expected  string
+	}{
+		{"AllowsOrigin", "Access-Control-Allow-Origin", "https://app.example"},
+		{"CookieSecure", "Set-Cookie", "Secure"},
+		{"HSTS", "Strict-Transport-Security", ""},
+		{"MaxAge", "Cache-Control", "max-age=120"},
+		{"SecurityHeaders", "Strict-Transport-Security", ""},
+	} {
+		var headerErr *httptester.HeaderError
+		if !errors.As(errs[i+1], &headerErr) {
+			t.Errorf("expected HeaderError got %v", errs[i+1])
+			continue
+		}
+		if headerErr.Assertion != expected.assertion || headerErr.Key != expected.key || headerErr.Expected != expected.expected {
+			t.Errorf("unexpected header error %+v", headerErr)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package httptester
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Eventually builds and sends a new request every interval until the request
+// succeeds and matcher reports no errors, returning the matching response.
+// Errors of failed attempts are suppressed. If the conditions are not met
+// within timeout the errors of the last attempt are reported to the
+// builder's error handler and nil is returned. The matcher may be nil.
+func Eventually(builderFn func() *ReqBuilder, matcher func(r *Response), timeout time.Duration, interval time.Duration) *Response {
+	deadline := time.Now().Add(timeout)
+
+	for attempt := 1; ; attempt++ {
+		b := builderFn()
+		b.helper()
+
+		onError := b.onError
+
+		var errs []error
+		b.OnError(func(err error) {
+			errs = append(errs, err)
+		})
+
+		r := b.Do()
+		if r != nil && matcher != nil {
+			matcher(r)
+		}
+
+		if len(errs) == 0 {
+			r.onError = onError
+			return r
+		}
+
+		if !time.Now().Add(interval).Before(deadline) {
+			onError(fmt.Errorf("condition not met within %s after %d attempts: %w", timeout, attempt, errors.Join(errs...)))
+			return nil
+		}
+
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,127 @@
+package httptester
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"testing"
+	"text/tabwriter"
+)
+
+// FailureReport collects the failed assertions of builders it is attached
+// to (see ReqBuilder.FailureReport) across a test suite and writes a summary
+// grouped by endpoint and assertion, e.g. from TestMain:
+//
+//	var failures = httptester.NewFailureReport()
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(failures.Run(m))
+//	}
+type FailureReport struct {
+	mu     sync.Mutex
+	groups map[failureKey]*FailureGroup
+}
+
+type failureKey struct {
+	endpoint  string
+	assertion string
+}
+
+// FailureGroup are the failures of an assertion on an endpoint.
+type FailureGroup struct {
+	// Endpoint is the method and URL template of the request, e.g.
+	// GET /users/{id}.
+	Endpoint string
+	// Assertion is the name of the assertion, e.g. Status.
+	Assertion string
+	Count     int
+	// First is the first failure.
+	First error
+}
+
+func NewFailureReport() *FailureReport {
+	return &FailureReport{
+		groups: map[failureKey]*FailureGroup{},
+	}
+}
+
+func (f *FailureReport) add(endpoint string, assertion string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if assertion == "" {
+		assertion = "-"
+	}
+
+	key := failureKey{endpoint: endpoint, assertion: assertion}
+	group, ok := f.groups[key]
+	if !ok {
+		group = &FailureGroup{Endpoint: endpoint, Assertion: assertion, First: err}
+		f.groups[key] = group
+	}
+	group.Count++
+}
+
+// Groups returns the failure groups, most frequent first.
+func (f *FailureReport) Groups() []FailureGroup {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	groups := make([]FailureGroup, 0, len(f.groups))
+	for _, group := range f.groups {
+		groups = append(groups, *group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		if groups[i].Endpoint != groups[j].Endpoint {
+			return groups[i].Endpoint < groups[j].Endpoint
+		}
+		return groups[i].Assertion < groups[j].Assertion
+	})
+
+	return groups
+}
+
+// Write writes the summary table to w. Nothing is written if no assertion
+// failed.
+func (f *FailureReport) Write(w io.Writer) error {
+	groups := f.Groups()
+	if len(groups) == 0 {
+		return nil
+	}
+
+	total := 0
+	endpoints := map[string]bool{}
+	for _, group := range groups {
+		total += group.Count
+		endpoints[group.Endpoint] = true
+	}
+
+	if _, err := fmt.Fprintf(w, "httptester: %d failed assertions on %d endpoints\n\n", total, len(endpoints)); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ENDPOINT\tASSERTION\tFAILURES\tFIRST FAILURE")
+	for _, group := range groups {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", group.Endpoint, group.Assertion, group.Count, firstLine(group.First.Error()))
+	}
+	return tw.Flush()
+}
+
+// Run runs the tests and writes the summary to stderr afterwards. It
+// returns the exit code of m.Run.
+func (f *FailureReport) Run(m *testing.M) int {
+	code := m.Run()
+	f.Write(os.Stderr)
+	return code
+}
+
+func (b *ReqBuilder) FailureReport(report *FailureReport) *ReqBuilder {
+	b.failures = report
+	return b
+}
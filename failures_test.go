@@ -0,0 +1,66 @@
+package httptester_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestFailureReport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("boom"))
+	}))
+	defer server.Close()
+
+	report := httptester.NewFailureReport()
+
+	buf := &bytes.Buffer{}
+	if err := report.Write(buf); err != nil || buf.Len() != 0 {
+		t.Fatalf("expected empty report got %q %v", buf.String(), err)
+	}
+
+	b := httptester.NewClient(
+		httptester.WithBaseURL(server.URL),
+		httptester.WithOnError(func(err error) {}),
+		httptester.WithFailureReport(report),
+	)
+
+	for _, id := range []string{"1", "2", "3"} {
+		b.GET("/users/{id}").Param("id", id).Do().Status(200).Contains("boom")
+	}
+	b.POST("/users").Do().Status(201).HeaderEq("Location", "/users/4")
+	b.GET("/users/{id}").Param("id", "1").Do().Check(func(r *httptester.Response) {
+		r.Status(200)
+	})
+
+	groups := report.Groups()
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups got %+v", groups)
+	}
+	if g := groups[0]; g.Endpoint != "GET /users/{id}" || g.Assertion != "Status" || g.Count != 3 {
+		t.Errorf("unexpected group %+v", g)
+	}
+
+	buf.Reset()
+	if err := report.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := `httptester: 5 failed assertions on 2 endpoints
+
+ENDPOINT         ASSERTION  FAILURES  FIRST FAILURE
+GET /users/{id}  Status     3         expected status [200] got 500: boom
+POST /users      HeaderEq   1         header Location: expected  to equal /users/4
+POST /users      Status     1         expected status [201] got 500: boom
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected report\n%s", buf.String())
+	}
+	if strings.Contains(buf.String(), "Contains") {
+		t.Error("expected passing assertions to be omitted")
+	}
+}
@@ -0,0 +1,212 @@
+package httptester
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type FaultKind int
+
+const (
+	FaultNone FaultKind = iota
+	// FaultReset fails the request with a connection reset error.
+	FaultReset
+	// FaultTimeout fails the request with a timeout error.
+	FaultTimeout
+	// FaultTruncate cuts the response body after Bytes bytes.
+	FaultTruncate
+	// FaultDelay delays the request by Delay.
+	FaultDelay
+	// FaultStatus returns Status without sending the request.
+	FaultStatus
+)
+
+type Fault struct {
+	Kind   FaultKind
+	Delay  time.Duration
+	Status int
+	Bytes  int
+}
+
+func NoFault() Fault {
+	return Fault{Kind: FaultNone}
+}
+
+func ResetFault() Fault {
+	return Fault{Kind: FaultReset}
+}
+
+func TimeoutFault() Fault {
+	return Fault{Kind: FaultTimeout}
+}
+
+func TruncateFault(bytes int) Fault {
+	return Fault{Kind: FaultTruncate, Bytes: bytes}
+}
+
+func DelayFault(d time.Duration) Fault {
+	return Fault{Kind: FaultDelay, Delay: d}
+}
+
+func StatusFault(status int) Fault {
+	return Fault{Kind: FaultStatus, Status: status}
+}
+
+// FaultSchedule returns the fault to inject into the n-th request (starting
+// at 1) sent through a FaultInjector.
+type FaultSchedule func(n int, req *http.Request) Fault
+
+// FaultSequence injects faults into the first len(faults) requests in order
+// and none into the following ones.
+func FaultSequence(faults ...Fault) FaultSchedule {
+	return func(n int, req *http.Request) Fault {
+		if n <= len(faults) {
+			return faults[n-1]
+		}
+		return NoFault()
+	}
+}
+
+// FaultEvery injects fault into every n-th request.
+func FaultEvery(n int, fault Fault) FaultSchedule {
+	return func(i int, req *http.Request) Fault {
+		if n > 0 && i%n == 0 {
+			return fault
+		}
+		return NoFault()
+	}
+}
+
+// FaultRate injects fault into requests with probability p using a random
+// source seeded with seed so runs are reproducible.
+func FaultRate(p float64, seed int64, fault Fault) FaultSchedule {
+	mu := sync.Mutex{}
+	rnd := rand.New(rand.NewSource(seed))
+
+	return func(n int, req *http.Request) Fault {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if rnd.Float64() < p {
+			return fault
+		}
+		return NoFault()
+	}
+}
+
+// FaultInjector injects faults according to a schedule into requests of
+// builders it is attached to (see ReqBuilder.Faults). Requests are counted
+// across all builders and retries.
+type FaultInjector struct {
+	schedule FaultSchedule
+	mu       sync.Mutex
+	n        int
+}
+
+func NewFaultInjector(schedule FaultSchedule) *FaultInjector {
+	return &FaultInjector{
+		schedule: schedule,
+	}
+}
+
+// Transport returns an http.RoundTripper that injects faults into requests
+// sent through next.
+func (f *FaultInjector) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &faultTransport{
+		injector: f,
+		next:     next,
+	}
+}
+
+type faultTransport struct {
+	injector *FaultInjector
+	next     http.RoundTripper
+}
+
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f := t.injector
+
+	f.mu.Lock()
+	f.n++
+	n := f.n
+	f.mu.Unlock()
+
+	fault := f.schedule(n, req)
+
+	switch fault.Kind {
+	case FaultReset:
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	case FaultTimeout:
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: faultTimeoutError{}}
+	case FaultDelay:
+		if err := sleepContext(req.Context(), fault.Delay); err != nil {
+			return nil, err
+		}
+	case FaultStatus:
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		body := fmt.Sprintf("injected fault: status %d", fault.Status)
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", fault.Status, http.StatusText(fault.Status)),
+			StatusCode:    fault.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+			Body:          io.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if fault.Kind == FaultTruncate {
+		res.Body = &truncatedBody{
+			ReadCloser: res.Body,
+			remaining:  fault.Bytes,
+		}
+	}
+
+	return res, nil
+}
+
+type faultTimeoutError struct{}
+
+func (faultTimeoutError) Error() string   { return "injected fault: i/o timeout" }
+func (faultTimeoutError) Timeout() bool   { return true }
+func (faultTimeoutError) Temporary() bool { return true }
+
+// truncatedBody fails with io.ErrUnexpectedEOF after remaining bytes as if
+// the connection was closed mid-body.
+type truncatedBody struct {
+	io.ReadCloser
+	remaining int
+}
+
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if len(p) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= n
+	return n, err
+}
@@ -0,0 +1,79 @@
+package httptester_test
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/bancek/httptester"
+)
+
+func TestFaultInjector(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	policy := httptester.DefaultRetryPolicy(3)
+	policy.InitialBackoff = time.Millisecond
+
+	faults := httptester.NewFaultInjector(httptester.FaultSequence(
+		httptester.StatusFault(503),
+		httptester.ResetFault(),
+		httptester.TimeoutFault(),
+	))
+
+	c := httptester.New(t, server.URL, httptester.WithFaults(faults))
+
+	c.GET("/").RetryPolicy(policy).Do().Status(200).Eq("hello world")
+	if requests != 1 {
+		t.Fatalf("expected 1 request to reach the server got %d", requests)
+	}
+
+	var errs []error
+	b := func(schedule httptester.FaultSchedule) *httptester.ReqBuilder {
+		return collectErrors(server.URL, &errs).Faults(httptester.NewFaultInjector(schedule)).GET("/")
+	}
+
+	b(httptester.FaultSequence(httptester.ResetFault())).Do()
+	if len(errs) != 1 || !errors.Is(errs[0], syscall.ECONNRESET) {
+		t.Fatalf("expected connection reset got %v", errs)
+	}
+
+	b(httptester.FaultSequence(httptester.TimeoutFault())).Do()
+	var netErr net.Error
+	if len(errs) != 2 || !errors.As(errs[1], &netErr) || !netErr.Timeout() {
+		t.Fatalf("expected timeout got %v", errs)
+	}
+
+	b(httptester.FaultSequence(httptester.TruncateFault(5))).Do()
+	if len(errs) != 3 || !strings.Contains(errs[2].Error(), "unexpected EOF") {
+		t.Fatalf("expected truncated body got %v", errs)
+	}
+
+	b(httptester.FaultSequence(httptester.DelayFault(30 * time.Millisecond))).Do().
+		Status(200).SlowerThan(30 * time.Millisecond)
+
+	every := httptester.NewFaultInjector(httptester.FaultEvery(2, httptester.StatusFault(500)))
+	for i, status := range []int{200, 500, 200, 500} {
+		collectErrors(server.URL, &errs).Faults(every).GET("/").Do().Status(status)
+		if len(errs) != 3 {
+			t.Fatalf("request %d: unexpected errors %v", i, errs)
+		}
+	}
+
+	rate := httptester.FaultRate(0.5, 1, httptester.ResetFault())
+	again := httptester.FaultRate(0.5, 1, httptester.ResetFault())
+	for i := 1; i <= 20; i++ {
+		if rate(i, nil) != again(i, nil) {
+			t.Fatal("expected the same faults for the same seed")
+		}
+	}
+}
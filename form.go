@@ -0,0 +1,167 @@
+package httptester
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// htmlForm is a form parsed from an HTML document.
+type htmlForm struct {
+	action string
+	method string
+	values url.Values
+	// names keeps the order of the fields.
+	names []string
+}
+
+func (f *htmlForm) add(name string, value string) {
+	if name == "" {
+		return
+	}
+	if _, ok := f.values[name]; !ok {
+		f.names = append(f.names, name)
+	}
+	f.values.Add(name, value)
+}
+
+// parseHTMLForm collects the action, method and the values a browser would
+// submit without user interaction: inputs (checkboxes and radio buttons
+// only if checked), textareas and selects. Buttons and file inputs are
+// skipped.
+func parseHTMLForm(form *domNode) *htmlForm {
+	f := &htmlForm{
+		method: "GET",
+		values: url.Values{},
+	}
+	f.action, _ = form.attr("action")
+	if method, ok := form.attr("method"); ok && strings.EqualFold(method, "post") {
+		f.method = "POST"
+	}
+
+	for _, n := range form.descendantsOrSelf()[1:] {
+		if n.kind != elementNode {
+			continue
+		}
+		if _, disabled := n.attr("disabled"); disabled {
+			continue
+		}
+		name, _ := n.attr("name")
+
+		switch n.name {
+		case "input":
+			inputType, _ := n.attr("type")
+			value, hasValue := n.attr("value")
+			switch strings.ToLower(inputType) {
+			case "submit", "button", "image", "reset", "file":
+				continue
+			case "checkbox", "radio":
+				if _, checked := n.attr("checked"); !checked {
+					continue
+				}
+				if !hasValue {
+					value = "on"
+				}
+			}
+			f.add(name, value)
+
+		case "textarea":
+			f.add(name, strings.TrimPrefix(n.textContent(), "\n"))
+
+		case "select":
+			var options, selected []*domNode
+			for _, o := range n.descendantsOrSelf()[1:] {
+				if o.name != "option" {
+					continue
+				}
+				options = append(options, o)
+				if _, ok := o.attr("selected"); ok {
+					selected = append(selected, o)
+				}
+			}
+			_, multiple := n.attr("multiple")
+			if len(selected) == 0 && !multiple && len(options) > 0 {
+				selected = options[:1]
+			}
+			for _, o := range selected {
+				value, ok := o.attr("value")
+				if !ok {
+					value = strings.Join(strings.Fields(o.textContent()), " ")
+				}
+				f.add(name, value)
+			}
+		}
+	}
+
+	return f
+}
+
+// Form parses the HTML form matching a CSS selector and returns a builder
+// that submits it to the form's action (resolved against the response URL)
+// with its method and the values of its fields, including hidden inputs
+// such as CSRF tokens. args are field name and value pairs that replace the
+// values from the document, e.g. Form("#login", "username", "alice").
+//
+// The builder is a copy of the builder that sent the request so it keeps
+// its client, headers and cookie jar. Fields are sent as
+// application/x-www-form-urlencoded, or in the query for GET forms.
+func (r *Response) Form(selector string, args ...string) *ReqBuilder {
+	r.helper()
+
+	if r.builder == nil {
+		r.err(errors.New("cannot submit form: response was not sent by a ReqBuilder"))
+		return nil
+	}
+
+	sel := r.HTML().Select(selector)
+	form, ok := sel.first()
+	if !ok {
+		return nil
+	}
+	if form.name != "form" {
+		r.err(fmt.Errorf("selector %s matched <%s> not <form>", selector, form.name))
+		return nil
+	}
+
+	f := parseHTMLForm(form)
+	for i := 0; i < len(args)/2; i++ {
+		if _, ok := f.values[args[i*2]]; !ok {
+			f.names = append(f.names, args[i*2])
+		}
+		f.values.Set(args[i*2], args[i*2+1])
+	}
+
+	action, err := r.URL.Parse(f.action)
+	if err != nil {
+		r.err(fmt.Errorf("invalid form action %q: %w", f.action, err))
+		return nil
+	}
+	action.Fragment = ""
+
+	encoded := make([]string, 0, len(f.names))
+	for _, name := range f.names {
+		for _, value := range f.values[name] {
+			encoded = append(encoded, url.QueryEscape(name)+"="+url.QueryEscape(value))
+		}
+	}
+
+	b := r.builder.Clone()
+	b.query = url.Values{}
+	b.params = map[string]string{}
+	b.body = nil
+	b.bodyBytes = nil
+	b.bodyEncoding = ""
+	b.headers.Del("Content-Type")
+	b.headers.Del("Content-Encoding")
+	b.baseURL = ""
+
+	if f.method == "GET" {
+		action.RawQuery = strings.Join(encoded, "&")
+		return b.Method("GET", action.String())
+	}
+
+	b.Method("POST", action.String())
+	b.Header("Content-Type", "application/x-www-form-urlencoded")
+	return b.Body(strings.NewReader(strings.Join(encoded, "&")))
+}
@@ -0,0 +1,87 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+const loginPage = `<html><body>
+<form id="search" action="/search?ignored=1">
+  <input name="q" value="go">
+  <select name="sort"><option value="name">Name<option value="date" selected>Date</select>
+</form>
+<form id="login" method="post" action="session#top">
+  <input type="hidden" name="csrf" value="tok&amp;123">
+  <input name="username">
+  <input type="password" name="password">
+  <input type="checkbox" name="remember" checked>
+  <input type="checkbox" name="newsletter" value="yes">
+  <input type="radio" name="plan" value="free"><input type="radio" name="plan" value="pro" checked>
+  <input name="legacy" value="x" disabled>
+  <select name="tags" multiple><option selected>a</option><option>b</option><option selected value="c">C</option></select>
+  <textarea name="bio">
+Hello</textarea>
+  <button type="submit" name="go">Log in</button>
+  <input type="submit" value="Submit">
+</form>
+<div id="notform"></div>
+</body></html>`
+
+func TestResponseForm(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/app/login":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(loginPage))
+		default:
+			r.ParseForm()
+			w.Header().Set("X-Method", r.Method)
+			w.Header().Set("X-Auth", r.Header.Get("Authorization"))
+			w.Write([]byte(r.URL.Path + "?" + r.URL.RawQuery + " " + r.PostForm.Encode()))
+		}
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	page := c.GET("/app/login").Header("Authorization", "Bearer t").Do().Status(200)
+
+	page.Form("#login", "username", "alice", "password", "secret").Do().
+		Status(200).
+		HeaderEq("X-Method", "POST").
+		HeaderEq("X-Auth", "Bearer t").
+		Eq("/app/session? bio=Hello&csrf=tok%26123&password=secret&plan=pro&remember=on&tags=a&tags=c&username=alice")
+
+	page.Form("form#search").Do().
+		HeaderEq("X-Method", "GET").
+		Eq("/search?q=go&sort=date ")
+
+	page.Form("#search", "q", "http", "page", "2").Do().
+		Eq("/search?q=http&sort=date&page=2 ")
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	page = b.GET("/app/login").Do()
+	if page.Form("#missing") != nil || page.Form("#notform") != nil {
+		t.Fatal("expected nil builders")
+	}
+
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		"selector #missing matched no elements",
+		"selector #notform matched <div> not <form>",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
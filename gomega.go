@@ -0,0 +1,109 @@
+package httptester
+
+import (
+	"errors"
+	"fmt"
+)
+
+// check runs assertions of f on a copy of r that collects the failures
+// instead of reporting them and returns them joined.
+func (r *Response) check(f func(r *Response)) error {
+	var errs []error
+
+	c := *r
+	c.onError = func(err error) {
+		errs = append(errs, err)
+	}
+	c.reporter = nil
+	c.failures = nil
+	f(&c)
+
+	return errors.Join(errs...)
+}
+
+// Check runs the assertions of f on the response and returns their failures
+// instead of reporting them, e.g. to retry them with Gomega's Eventually.
+func (r *Response) Check(f func(r *Response)) error {
+	return r.check(f)
+}
+
+// Try sends the request and runs the assertions of f on the response. The
+// failures of sending the request and of the assertions are returned
+// instead of reported, so a request can be polled until the assertions
+// pass:
+//
+//	Eventually(func() error {
+//		return c.GET("/jobs/1").Try(func(r *httptester.Response) {
+//			r.Status(200).JSONPath("$.state", "done")
+//		})
+//	}).Should(Succeed())
+func (b *ReqBuilder) Try(f func(r *Response)) error {
+	var errs []error
+
+	res := b.Clone().FailureReport(nil).OnError(func(err error) {
+		errs = append(errs, err)
+	}).Do()
+	if res != nil && len(errs) == 0 {
+		f(res)
+	}
+
+	return errors.Join(errs...)
+}
+
+// ResponseMatcher runs httptester assertions as a Gomega matcher, it
+// implements types.GomegaMatcher:
+//
+//	Expect(res).To(httptester.HaveJSONPath("$.id", 1))
+type ResponseMatcher struct {
+	name   string
+	assert func(r *Response)
+	err    error
+}
+
+// MatchResponse returns a Gomega matcher that succeeds when the assertions
+// of f pass.
+func MatchResponse(name string, f func(r *Response)) *ResponseMatcher {
+	return &ResponseMatcher{name: name, assert: f}
+}
+
+func HaveStatus(statuses ...int) *ResponseMatcher {
+	return MatchResponse(fmt.Sprintf("have status %v", statuses), func(r *Response) {
+		r.Status(statuses...)
+	})
+}
+
+func HaveHeader(key string, value string) *ResponseMatcher {
+	return MatchResponse(fmt.Sprintf("have header %s: %s", key, value), func(r *Response) {
+		r.HeaderEq(key, value)
+	})
+}
+
+func HaveJSONPath(path string, expected interface{}) *ResponseMatcher {
+	return MatchResponse(fmt.Sprintf("have %s equal to %s", path, jsonString(expected)), func(r *Response) {
+		r.JSONPath(path, expected)
+	})
+}
+
+func ContainBody(substr string) *ResponseMatcher {
+	return MatchResponse(fmt.Sprintf("contain %s", substr), func(r *Response) {
+		r.Contains(substr)
+	})
+}
+
+func (m *ResponseMatcher) Match(actual interface{}) (bool, error) {
+	r, ok := actual.(*Response)
+	if !ok || r == nil {
+		return false, fmt.Errorf("%s expects a *httptester.Response got %T", m.name, actual)
+	}
+
+	m.err = r.check(m.assert)
+	return m.err == nil, nil
+}
+
+func (m *ResponseMatcher) FailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected response to %s\n%s", m.name, m.err)
+}
+
+func (m *ResponseMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected response not to %s", m.name)
+}
@@ -0,0 +1,86 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestGomegaMatchers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":1,"name":"alice"}`))
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+	res := c.GET("/").Do()
+
+	for _, m := range []*httptester.ResponseMatcher{
+		httptester.HaveStatus(200),
+		httptester.HaveHeader("Content-Type", "application/json"),
+		httptester.HaveJSONPath("$.name", "alice"),
+		httptester.ContainBody(`"id":1`),
+	} {
+		if ok, err := m.Match(res); !ok || err != nil {
+			t.Errorf("expected match got %v %v: %s", ok, err, m.FailureMessage(res))
+		}
+	}
+
+	m := httptester.HaveJSONPath("$.name", "bob")
+	if ok, err := m.Match(res); ok || err != nil {
+		t.Fatalf("expected no match got %v %v", ok, err)
+	}
+	if msg := m.FailureMessage(res); !strings.Contains(msg, `Expected response to have $.name equal to "bob"`) || !strings.Contains(msg, `expected "bob" got "alice"`) {
+		t.Errorf("unexpected failure message %s", msg)
+	}
+	if msg := m.NegatedFailureMessage(res); msg != `Expected response not to have $.name equal to "bob"` {
+		t.Errorf("unexpected negated failure message %s", msg)
+	}
+
+	if _, err := m.Match("body"); err == nil || !strings.Contains(err.Error(), "expects a *httptester.Response got string") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestTry(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.Write([]byte(`{"state":"running"}`))
+			return
+		}
+		w.Write([]byte(`{"state":"done"}`))
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	var err error
+	for i := 0; i < 3; i++ {
+		err = c.GET("/").Try(func(r *httptester.Response) {
+			r.Status(200).JSONPath("$.state", "done")
+		})
+		if i < 2 && (err == nil || !strings.Contains(err.Error(), `expected "done" got "running"`)) {
+			t.Errorf("expected attempt %d to fail got %v", i, err)
+		}
+	}
+	if err != nil {
+		t.Fatalf("expected last attempt to pass got %v", err)
+	}
+
+	err = c.GET("/").Do().Check(func(r *httptester.Response) {
+		r.Status(201).Contains("running")
+	})
+	if err == nil || !strings.Contains(err.Error(), "expected status [201] got 200") || !strings.Contains(err.Error(), "body does not contain running") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if err := httptester.New(t, "http://127.0.0.1:1").GET("/").Try(func(r *httptester.Response) {}); err == nil {
+		t.Error("expected connection error")
+	}
+}
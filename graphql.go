@@ -0,0 +1,99 @@
+package httptester
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []GraphQLError  `json:"errors"`
+}
+
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (b *ReqBuilder) GraphQL(query string, variables map[string]interface{}, operationName string) *ReqBuilder {
+	return b.JSON(&graphQLRequest{
+		Query:         query,
+		Variables:     variables,
+		OperationName: operationName,
+	})
+}
+
+func (r *Response) graphQL() (*graphQLResponse, bool) {
+	r.helper()
+
+	res := &graphQLResponse{}
+	if err := json.Unmarshal(r.Body, res); err != nil {
+		r.err(fmt.Errorf("invalid GraphQL response: %s: %s", err, r.bodyExcerpt()))
+		return nil, false
+	}
+	return res, true
+}
+
+// GraphQLData fails if the response contains GraphQL errors and otherwise
+// unmarshals the data field into v.
+func (r *Response) GraphQLData(v interface{}) interface{} {
+	r.helper()
+
+	res, ok := r.graphQL()
+	if !ok {
+		return nil
+	}
+
+	if len(res.Errors) > 0 {
+		messages := make([]string, len(res.Errors))
+		for i, e := range res.Errors {
+			messages[i] = e.Message
+		}
+		r.err(fmt.Errorf("GraphQL errors: %s", strings.Join(messages, "; ")))
+		return nil
+	}
+
+	if err := json.Unmarshal(res.Data, v); err != nil {
+		r.err(err)
+		return nil
+	}
+
+	return v
+}
+
+func (r *Response) GraphQLErrors() []GraphQLError {
+	r.helper()
+
+	res, ok := r.graphQL()
+	if !ok {
+		return nil
+	}
+	return res.Errors
+}
+
+func (r *Response) GraphQLError(substr string) *Response {
+	r.helper()
+	defer r.track("GraphQLError", substr)()
+
+	res, ok := r.graphQL()
+	if !ok {
+		return r
+	}
+
+	for _, e := range res.Errors {
+		if strings.Contains(e.Message, substr) {
+			return r
+		}
+	}
+
+	r.err(fmt.Errorf("expected GraphQL error containing %s: %s", substr, r.bodyExcerpt()))
+	return r
+}
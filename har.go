@@ -0,0 +1,463 @@
+package httptester
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HAREntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+}
+
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARCookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+}
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type HARTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// HARRecorder captures every request made by builders it is attached to
+// (see ReqBuilder.Record) and writes them as a HAR 1.2 file.
+type HARRecorder struct {
+	mu      sync.Mutex
+	entries []HAREntry
+}
+
+func NewHARRecorder() *HARRecorder {
+	return &HARRecorder{}
+}
+
+// RecordHAR returns a recorder that writes its entries to path when the
+// test ends.
+func RecordHAR(t testing.TB, path string) *HARRecorder {
+	t.Helper()
+
+	h := NewHARRecorder()
+
+	t.Cleanup(func() {
+		if err := h.WriteFile(path); err != nil {
+			t.Error(err)
+		}
+	})
+
+	return h
+}
+
+func (h *HARRecorder) Entries() []HAREntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]HAREntry(nil), h.entries...)
+}
+
+func (h *HARRecorder) HAR() *HAR {
+	return &HAR{
+		Log: HARLog{
+			Version: "1.2",
+			Creator: HARCreator{
+				Name:    "httptester",
+				Version: "1.0",
+			},
+			Entries: h.Entries(),
+		},
+	}
+}
+
+func (h *HARRecorder) WriteFile(path string) error {
+	data, err := json.MarshalIndent(h.HAR(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func (h *HARRecorder) Transport(next http.RoundTripper) http.RoundTripper {
+	return h.transport(next, nil)
+}
+
+// transport returns a recording transport redacting entries with redactor.
+func (h *HARRecorder) transport(next http.RoundTripper, redactor *Redactor) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &harTransport{
+		recorder: h,
+		next:     next,
+		redactor: redactor,
+	}
+}
+
+func (h *HARRecorder) add(entry HAREntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries = append(h.entries, entry)
+}
+
+type harTransport struct {
+	recorder *HARRecorder
+	next     http.RoundTripper
+	redactor *Redactor
+}
+
+func (t *harTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	start := time.Now()
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	wait := time.Since(start)
+
+	resBody, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	total := time.Since(start)
+
+	t.recorder.add(HAREntry{
+		StartedDateTime: start,
+		Time:            durationMs(total),
+		Request:         t.harRequest(req, reqBody, res, resBody),
+		Response:        t.harResponse(req, reqBody, res, resBody),
+		Timings: HARTimings{
+			Wait:    durationMs(wait),
+			Receive: durationMs(total - wait),
+		},
+	})
+
+	return res, nil
+}
+
+// harRequest returns the HAR request with secrets redacted.
+func (t *harTransport) harRequest(req *http.Request, reqBody []byte, res *http.Response, resBody []byte) HARRequest {
+	if t.redactor == nil {
+		return harRequest(req, reqBody)
+	}
+
+	secrets := t.redactor.secrets([]http.Header{req.Header, res.Header}, [][]byte{reqBody, resBody})
+
+	redacted := *req
+	redacted.Header = t.redactor.header(req.Header)
+	entry := harRequest(&redacted, t.redactor.body(reqBody))
+	entry.URL = t.redactor.text(scrub(entry.URL, secrets))
+	for i := range entry.QueryString {
+		entry.QueryString[i].Value = t.redactor.text(scrub(entry.QueryString[i].Value, secrets))
+	}
+	if entry.PostData != nil {
+		entry.PostData.Text = scrub(entry.PostData.Text, secrets)
+	}
+	return entry
+}
+
+// harResponse returns the HAR response with secrets redacted.
+func (t *harTransport) harResponse(req *http.Request, reqBody []byte, res *http.Response, resBody []byte) HARResponse {
+	if t.redactor == nil {
+		return harResponse(res, resBody)
+	}
+
+	secrets := t.redactor.secrets([]http.Header{req.Header, res.Header}, [][]byte{reqBody, resBody})
+
+	redacted := *res
+	redacted.Header = t.redactor.header(res.Header)
+	entry := harResponse(&redacted, t.redactor.body(resBody))
+	if entry.Content.Encoding == "" {
+		entry.Content.Text = scrub(entry.Content.Text, secrets)
+	}
+	return entry
+}
+
+func harRequest(req *http.Request, body []byte) HARRequest {
+	r := HARRequest{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		HTTPVersion: req.Proto,
+		Cookies:     []HARCookie{},
+		Headers:     harHeaders(req.Header),
+		QueryString: harHeaders(req.URL.Query()),
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+
+	if req.Host != "" && req.Header.Get("Host") == "" {
+		r.Headers = append(r.Headers, HARNameValue{Name: "Host", Value: req.Host})
+	}
+
+	for _, cookie := range req.Cookies() {
+		r.Cookies = append(r.Cookies, HARCookie{Name: cookie.Name, Value: cookie.Value})
+	}
+
+	if body != nil {
+		r.PostData = &HARPostData{
+			MimeType: req.Header.Get("Content-Type"),
+			Text:     string(body),
+		}
+	}
+
+	return r
+}
+
+func harResponse(res *http.Response, body []byte) HARResponse {
+	r := HARResponse{
+		Status:      res.StatusCode,
+		StatusText:  http.StatusText(res.StatusCode),
+		HTTPVersion: res.Proto,
+		Cookies:     []HARCookie{},
+		Headers:     harHeaders(res.Header),
+		Content: HARContent{
+			Size:     len(body),
+			MimeType: res.Header.Get("Content-Type"),
+		},
+		RedirectURL: res.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+
+	for _, cookie := range res.Cookies() {
+		r.Cookies = append(r.Cookies, HARCookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Path:     cookie.Path,
+			Domain:   cookie.Domain,
+			HTTPOnly: cookie.HttpOnly,
+			Secure:   cookie.Secure,
+		})
+	}
+
+	if utf8.Valid(body) {
+		r.Content.Text = string(body)
+	} else {
+		r.Content.Text = base64.StdEncoding.EncodeToString(body)
+		r.Content.Encoding = "base64"
+	}
+
+	return r
+}
+
+// HARReplayer is a transport that serves responses from a recorded HAR file
+// instead of hitting the network. Entries are matched by method, URL and a
+// hash of the request body. Repeated requests are served the recorded
+// responses in order, the last one being reused once they run out.
+type HARReplayer struct {
+	mu      sync.Mutex
+	entries map[string][]HAREntry
+}
+
+func NewHARReplayer(har *HAR) *HARReplayer {
+	entries := map[string][]HAREntry{}
+	for _, entry := range har.Log.Entries {
+		var body []byte
+		if entry.Request.PostData != nil {
+			body = []byte(entry.Request.PostData.Text)
+		}
+		key := harKey(entry.Request.Method, entry.Request.URL, body)
+		entries[key] = append(entries[key], entry)
+	}
+
+	return &HARReplayer{
+		entries: entries,
+	}
+}
+
+func LoadHARReplayer(path string) (*HARReplayer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	har := &HAR{}
+	if err := json.Unmarshal(data, har); err != nil {
+		return nil, fmt.Errorf("invalid HAR file %s: %w", path, err)
+	}
+
+	return NewHARReplayer(har), nil
+}
+
+func (h *HARReplayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	entry, ok := h.next(harKey(req.Method, req.URL.String(), body))
+	if !ok {
+		return nil, fmt.Errorf("no recorded response for %s %s", req.Method, req.URL.String())
+	}
+
+	resBody := []byte(entry.Response.Content.Text)
+	if entry.Response.Content.Encoding == "base64" {
+		var err error
+		resBody, err = base64.StdEncoding.DecodeString(entry.Response.Content.Text)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	header := http.Header{}
+	for _, nv := range entry.Response.Headers {
+		header.Add(nv.Name, nv.Value)
+	}
+
+	proto := entry.Response.HTTPVersion
+	major, minor, ok := http.ParseHTTPVersion(proto)
+	if !ok {
+		proto, major, minor = "HTTP/1.1", 1, 1
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.Response.Status, entry.Response.StatusText),
+		StatusCode:    entry.Response.Status,
+		Proto:         proto,
+		ProtoMajor:    major,
+		ProtoMinor:    minor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(resBody)),
+		ContentLength: int64(len(resBody)),
+		Request:       req,
+	}, nil
+}
+
+func (h *HARReplayer) next(key string) (HAREntry, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entries := h.entries[key]
+	if len(entries) == 0 {
+		return HAREntry{}, false
+	}
+
+	entry := entries[0]
+	if len(entries) > 1 {
+		h.entries[key] = entries[1:]
+	}
+
+	return entry, true
+}
+
+func harKey(method string, url string, body []byte) string {
+	hash := sha256.Sum256(body)
+	return method + " " + url + " " + hex.EncodeToString(hash[:])
+}
+
+func harHeaders(header map[string][]string) []HARNameValue {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	headers := []HARNameValue{}
+	for _, k := range keys {
+		for _, v := range header[k] {
+			headers = append(headers, HARNameValue{Name: k, Value: v})
+		}
+	}
+	return headers
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
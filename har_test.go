@@ -0,0 +1,121 @@
+package httptester_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestHARRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(201)
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer server.Close()
+
+	fatal := func(err error) {
+		t.Fatal(err)
+	}
+
+	recorder := httptester.NewHARRecorder()
+
+	httptester.NewReqBuilder(server.URL, http.DefaultClient, fatal).Record(recorder).
+		POST("/articles").Q("draft", "1").JSON(map[string]string{"title": "Test"}).
+		Do().Status(201).JSONPath("$.id", 1)
+
+	path := filepath.Join(t.TempDir(), "test.har")
+	if err := recorder.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	har := &httptester.HAR{}
+	if err := json.Unmarshal(data, har); err != nil {
+		t.Fatal(err)
+	}
+
+	if har.Log.Version != "1.2" || len(har.Log.Entries) != 1 {
+		t.Fatalf("unexpected HAR: %s", data)
+	}
+
+	entry := har.Log.Entries[0]
+	if entry.Request.Method != "POST" || entry.Request.PostData.Text != `{"title":"Test"}` ||
+		entry.Request.QueryString[0].Value != "1" {
+		t.Fatalf("unexpected request: %+v", entry.Request)
+	}
+	if entry.Response.Status != 201 || entry.Response.Content.Text != `{"id":1}` {
+		t.Fatalf("unexpected response: %+v", entry.Response)
+	}
+}
+
+func TestRecordHAR(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "run.har")
+
+	t.Run("run", func(t *testing.T) {
+		c := httptester.New(t, server.URL, httptester.WithHAR(httptester.RecordHAR(t, path)))
+		c.GET("/a").Do().Status(200)
+		c.GET("/b").Do().Status(200)
+	})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	har := &httptester.HAR{}
+	if err := json.Unmarshal(data, har); err != nil {
+		t.Fatal(err)
+	}
+	if len(har.Log.Entries) != 2 || !strings.HasSuffix(har.Log.Entries[1].Request.URL, "/b") {
+		t.Fatalf("unexpected HAR: %s", data)
+	}
+}
+
+func TestHARReplayer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(append([]byte("hello "), body...))
+	}))
+
+	fatal := func(err error) {
+		t.Fatal(err)
+	}
+
+	recorder := httptester.NewHARRecorder()
+	httptester.NewReqBuilder(server.URL, http.DefaultClient, fatal).Record(recorder).
+		POST("/echo").Body(strings.NewReader("alice")).Do().Eq("hello alice")
+	httptester.NewReqBuilder(server.URL, http.DefaultClient, fatal).Record(recorder).
+		POST("/echo").Body(strings.NewReader("bob")).Do().Eq("hello bob")
+
+	server.Close()
+
+	replayer := httptester.NewHARReplayer(recorder.HAR())
+
+	httptester.NewReqBuilder(server.URL, http.DefaultClient, fatal).Replay(replayer).
+		POST("/echo").Body(strings.NewReader("bob")).Do().Status(200).Eq("hello bob")
+	httptester.NewReqBuilder(server.URL, http.DefaultClient, fatal).Replay(replayer).
+		POST("/echo").Body(strings.NewReader("alice")).Do().Status(200).Eq("hello alice")
+
+	var errs []error
+	collectErrors(server.URL, &errs).Replay(replayer).POST("/echo").Body(strings.NewReader("carol")).Do()
+	if len(errs) != 1 {
+		t.Fatalf("expected missing recording error got %v", errs)
+	}
+}
@@ -0,0 +1,400 @@
+package httptester
+
+import (
+	"fmt"
+	"html"
+	"mime"
+	"strings"
+)
+
+// htmlVoidElements have no end tag.
+var htmlVoidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// htmlRawTextElements contain text up to their end tag.
+var htmlRawTextElements = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true,
+}
+
+var htmlBlockElements = []string{
+	"address", "article", "aside", "blockquote", "div", "dl", "fieldset",
+	"footer", "form", "h1", "h2", "h3", "h4", "h5", "h6", "header", "hr",
+	"main", "nav", "ol", "p", "pre", "section", "table", "ul",
+}
+
+// htmlImpliedEnd lists for a start tag the open elements it closes, e.g. a
+// new <li> closes the previous <li>.
+var htmlImpliedEnd = func() map[string]map[string]bool {
+	implied := map[string]map[string]bool{
+		"li":       {"li": true},
+		"option":   {"option": true},
+		"optgroup": {"option": true, "optgroup": true},
+		"dt":       {"dt": true, "dd": true},
+		"dd":       {"dt": true, "dd": true},
+		"tr":       {"tr": true, "td": true, "th": true},
+		"td":       {"td": true, "th": true},
+		"th":       {"td": true, "th": true},
+		"thead":    {"tbody": true, "tr": true, "td": true, "th": true},
+		"tbody":    {"thead": true, "tbody": true, "tr": true, "td": true, "th": true},
+		"tfoot":    {"thead": true, "tbody": true, "tr": true, "td": true, "th": true},
+	}
+	for _, tag := range htmlBlockElements {
+		if implied[tag] == nil {
+			implied[tag] = map[string]bool{}
+		}
+		implied[tag]["p"] = true
+	}
+	return implied
+}()
+
+// parseHTMLDocument leniently parses an HTML document into a tree. It
+// handles void and raw text elements and the common implied end tags but
+// is not a complete HTML5 parser.
+func parseHTMLDocument(data string) *domNode {
+	doc := &domNode{}
+	current := doc
+
+	for len(data) > 0 {
+		lt := strings.IndexByte(data, '<')
+		if lt < 0 {
+			lt = len(data)
+		}
+		if lt > 0 {
+			if text := data[:lt]; strings.TrimSpace(text) != "" {
+				current.appendChild(&domNode{kind: textNode, text: html.UnescapeString(text)})
+			}
+			data = data[lt:]
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(data, "<!--"):
+			end := strings.Index(data, "-->")
+			if end < 0 {
+				return doc
+			}
+			data = data[end+3:]
+
+		case strings.HasPrefix(data, "<!"), strings.HasPrefix(data, "<?"):
+			end := strings.IndexByte(data, '>')
+			if end < 0 {
+				return doc
+			}
+			data = data[end+1:]
+
+		case strings.HasPrefix(data, "</"):
+			end := strings.IndexByte(data, '>')
+			if end < 0 {
+				return doc
+			}
+			name := strings.ToLower(strings.TrimSpace(data[2:end]))
+			data = data[end+1:]
+			for n := current; n != doc; n = n.parent {
+				if n.name == name {
+					current = n.parent
+					break
+				}
+			}
+
+		case len(data) > 1 && isASCIILetter(data[1]):
+			element, selfClosing, rest := parseHTMLStartTag(data)
+			data = rest
+
+			for implied := htmlImpliedEnd[element.name]; current != doc && implied[current.name]; {
+				current = current.parent
+			}
+			current.appendChild(element)
+
+			if htmlRawTextElements[element.name] {
+				end := strings.Index(strings.ToLower(data), "</"+element.name)
+				if end < 0 {
+					end = len(data)
+				}
+				if text := data[:end]; text != "" {
+					if element.name == "script" || element.name == "style" {
+						element.appendChild(&domNode{kind: textNode, text: text})
+					} else {
+						element.appendChild(&domNode{kind: textNode, text: html.UnescapeString(text)})
+					}
+				}
+				data = data[end:]
+				if gt := strings.IndexByte(data, '>'); gt >= 0 {
+					data = data[gt+1:]
+				}
+				continue
+			}
+
+			if !selfClosing && !htmlVoidElements[element.name] {
+				current = element
+			}
+
+		default:
+			current.appendChild(&domNode{kind: textNode, text: "<"})
+			data = data[1:]
+		}
+	}
+
+	return doc
+}
+
+func isASCIILetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// parseHTMLStartTag parses the start tag data begins with and returns the
+// element and the remaining data.
+func parseHTMLStartTag(data string) (*domNode, bool, string) {
+	i := 1
+	for i < len(data) && !strings.ContainsRune(" \t\r\n/>", rune(data[i])) {
+		i++
+	}
+	element := &domNode{name: strings.ToLower(data[1:i])}
+
+	for i < len(data) {
+		for i < len(data) && strings.ContainsRune(" \t\r\n", rune(data[i])) {
+			i++
+		}
+		if i >= len(data) {
+			break
+		}
+		if data[i] == '>' {
+			return element, false, data[i+1:]
+		}
+		if strings.HasPrefix(data[i:], "/>") {
+			return element, true, data[i+2:]
+		}
+		if data[i] == '/' {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(data) && !strings.ContainsRune(" \t\r\n/>=", rune(data[i])) {
+			i++
+		}
+		name := strings.ToLower(data[start:i])
+
+		for i < len(data) && strings.ContainsRune(" \t\r\n", rune(data[i])) {
+			i++
+		}
+
+		value := ""
+		if i < len(data) && data[i] == '=' {
+			i++
+			for i < len(data) && strings.ContainsRune(" \t\r\n", rune(data[i])) {
+				i++
+			}
+			if i < len(data) && (data[i] == '"' || data[i] == '\'') {
+				quote := data[i]
+				end := strings.IndexByte(data[i+1:], quote)
+				if end < 0 {
+					end = len(data) - i - 1
+				}
+				value = data[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				start := i
+				for i < len(data) && !strings.ContainsRune(" \t\r\n>", rune(data[i])) {
+					i++
+				}
+				value = data[start:i]
+			}
+		}
+
+		if _, ok := element.attr(name); !ok && name != "" {
+			element.attrs = append(element.attrs, &domNode{kind: attributeNode, name: name, text: html.UnescapeString(value), parent: element})
+		}
+	}
+
+	return element, false, ""
+}
+
+// HTMLDocument is a parsed HTML response body queried with CSS selectors.
+// Failed assertions are reported through the response.
+type HTMLDocument struct {
+	r   *Response
+	doc *domNode
+}
+
+// HTML parses the body as an HTML document.
+func (r *Response) HTML() *HTMLDocument {
+	r.helper()
+
+	d := &HTMLDocument{
+		r:   r,
+		doc: &domNode{},
+	}
+
+	if !r.hasBody() {
+		return d
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		r.err(fmt.Errorf("Content-Type is not text/html, got %s: %s", contentType, r.bodyExcerpt()))
+	}
+
+	d.doc = parseHTMLDocument(string(r.Body))
+	return d
+}
+
+// Response returns the response the document was parsed from.
+func (d *HTMLDocument) Response() *Response {
+	return d.r
+}
+
+// Select returns the elements matching a CSS selector. Supported are type,
+// universal, #id, .class and attribute ([a], [a=v], [a~=v], [a^=v], [a$=v],
+// [a*=v]) selectors, the :first-child, :last-child, :nth-child(n) and
+// :not(...) pseudo-classes, descendant, >, + and ~ combinators and
+// selector lists separated by commas.
+func (d *HTMLDocument) Select(selector string) *Selection {
+	d.r.helper()
+
+	s := &Selection{
+		r:        d.r,
+		selector: selector,
+	}
+
+	sel, err := compileCSSSelector(selector)
+	if err != nil {
+		d.r.err(fmt.Errorf("invalid selector %s: %w", selector, err))
+		return s
+	}
+
+	s.nodes = sel.selectFrom(d.doc)
+	return s
+}
+
+// ElementCount checks the number of elements matching a CSS selector.
+func (d *HTMLDocument) ElementCount(selector string, count int) *HTMLDocument {
+	d.r.helper()
+
+	d.Select(selector).Count(count)
+	return d
+}
+
+// Title returns the text of the document's <title>.
+func (d *HTMLDocument) Title() string {
+	sel, _ := compileCSSSelector("title")
+	if nodes := sel.selectFrom(d.doc); len(nodes) > 0 {
+		return strings.TrimSpace(nodes[0].textContent())
+	}
+	return ""
+}
+
+// Selection is the result of HTMLDocument.Select.
+type Selection struct {
+	r        *Response
+	selector string
+	nodes    []*domNode
+}
+
+func (s *Selection) Len() int {
+	return len(s.nodes)
+}
+
+// Text returns the text of the first matched element with whitespace
+// collapsed.
+func (s *Selection) Text() string {
+	if len(s.nodes) == 0 {
+		return ""
+	}
+	return strings.Join(strings.Fields(s.nodes[0].textContent()), " ")
+}
+
+// Texts returns the text of each matched element.
+func (s *Selection) Texts() []string {
+	texts := make([]string, len(s.nodes))
+	for i, n := range s.nodes {
+		texts[i] = strings.Join(strings.Fields(n.textContent()), " ")
+	}
+	return texts
+}
+
+// Attr returns an attribute of the first matched element.
+func (s *Selection) Attr(name string) (string, bool) {
+	if len(s.nodes) == 0 {
+		return "", false
+	}
+	return s.nodes[0].attr(strings.ToLower(name))
+}
+
+// first returns the first matched element and reports an error if there is
+// none.
+func (s *Selection) first() (*domNode, bool) {
+	s.r.helper()
+
+	if len(s.nodes) == 0 {
+		s.r.err(fmt.Errorf("selector %s matched no elements", s.selector))
+		return nil, false
+	}
+
+	return s.nodes[0], true
+}
+
+func (s *Selection) Exists() *Selection {
+	s.r.helper()
+	defer s.r.track("Exists", s.selector)()
+
+	s.first()
+	return s
+}
+
+func (s *Selection) Count(count int) *Selection {
+	s.r.helper()
+	defer s.r.track("ElementCount", s.selector, count)()
+
+	if len(s.nodes) != count {
+		s.r.err(fmt.Errorf("selector %s: expected %d elements got %d", s.selector, count, len(s.nodes)))
+	}
+
+	return s
+}
+
+// TextEq checks the text of the first matched element with whitespace
+// collapsed.
+func (s *Selection) TextEq(text string) *Selection {
+	s.r.helper()
+	defer s.r.track("TextEq", s.selector, text)()
+
+	if _, ok := s.first(); ok && s.Text() != text {
+		s.r.err(fmt.Errorf("selector %s: expected text %q got %q", s.selector, text, s.Text()))
+	}
+
+	return s
+}
+
+func (s *Selection) TextContains(substr string) *Selection {
+	s.r.helper()
+	defer s.r.track("TextContains", s.selector, substr)()
+
+	if _, ok := s.first(); ok && !strings.Contains(s.Text(), substr) {
+		s.r.err(fmt.Errorf("selector %s: expected text to contain %q got %q", s.selector, substr, s.Text()))
+	}
+
+	return s
+}
+
+func (s *Selection) AttrEq(name string, value string) *Selection {
+	s.r.helper()
+	defer s.r.track("AttrEq", s.selector, name, value)()
+
+	if _, ok := s.first(); !ok {
+		return s
+	}
+
+	actual, ok := s.Attr(name)
+	if !ok {
+		s.r.err(fmt.Errorf("selector %s: element has no attribute %s", s.selector, name))
+		return s
+	}
+	if actual != value {
+		s.r.err(fmt.Errorf("selector %s: expected attribute %s=%q got %q", s.selector, name, value, actual))
+	}
+
+	return s
+}
@@ -0,0 +1,106 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+const testPage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>Users &amp; Groups</title>
+  <script>if (a < b) { document.write("<h1>x</h1>") }</script>
+</head>
+<body>
+  <!-- <h1>comment</h1> -->
+  <h1 class="title main">Welcome</h1>
+  <p>First<br>paragraph
+  <p id=second>Second <b>bold</b> paragraph
+  <ul class="nav">
+    <li><a href="/users" class="active">Users</a>
+    <li><a href="/groups">Groups</a>
+    <li><a href="https://example.com/help" target=_blank>Help</a>
+  </ul>
+  <table>
+    <tr><th>Name<th>Email
+    <tr><td>alice<td>alice@example.com
+    <tr><td>bob<td>bob@example.com
+  </table>
+  <input type="checkbox" checked disabled/>
+</body>
+</html>`
+
+func TestResponseHTML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(testPage))
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	doc := c.GET("/").Do().Status(200).HTML()
+
+	doc.Select("h1").TextEq("Welcome").AttrEq("class", "title main").Count(1)
+	doc.Select("h1.main.title").Exists()
+	doc.Select("#second").TextEq("Second bold paragraph")
+	doc.Select("h1 + p br, p + p b").Count(2)
+	doc.Select("p#second > b").TextEq("bold")
+	doc.Select("ul.nav li").Count(3)
+	doc.Select("ul > li:last-child a").AttrEq("target", "_blank")
+	doc.Select("li:nth-child(2) a").TextEq("Groups")
+	doc.Select("a[href^='/']").Count(2)
+	doc.Select(`a[href$="help"], a.active`).Count(2)
+	doc.Select("li:not(:first-child) a").Count(2)
+	doc.Select("li ~ li").Count(2)
+	doc.Select("input[checked][type=checkbox]").Exists()
+	doc.Select("table tr td:first-child").TextContains("ali")
+	doc.ElementCount("table tr", 3).
+		ElementCount("tr:nth-child(odd) td", 2).
+		ElementCount("td", 4).
+		ElementCount("script", 1)
+
+	if title := doc.Title(); title != "Users & Groups" {
+		t.Fatalf("unexpected title %q", title)
+	}
+
+	if texts := doc.Select("td:first-child").Texts(); len(texts) != 2 || texts[1] != "bob" {
+		t.Fatalf("unexpected texts %q", texts)
+	}
+
+	if href, ok := doc.Select("a.active").Attr("href"); !ok || href != "/users" {
+		t.Fatalf("unexpected href %q", href)
+	}
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	doc = b.GET("/").Do().HTML()
+	doc.Select("h1").TextEq("Goodbye")
+	doc.Select("h2").TextEq("Goodbye")
+	doc.ElementCount("li", 2)
+	doc.Select("a.active").AttrEq("href", "/groups").AttrEq("rel", "x")
+	doc.Select("li >")
+
+	if len(errs) != 6 {
+		t.Fatalf("expected 6 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		`selector h1: expected text "Goodbye" got "Welcome"`,
+		"selector h2 matched no elements",
+		"selector li: expected 2 elements got 3",
+		`selector a.active: expected attribute href="/groups" got "/users"`,
+		"selector a.active: element has no attribute rel",
+		"invalid selector li >",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
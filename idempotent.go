@@ -0,0 +1,103 @@
+package httptester
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// IdempotencyKeyHeader is the default header of idempotency keys, see
+// WithIdempotencyKey.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type idempotentCheck struct {
+	keyHeader   string
+	masks       []string
+	sideEffects func(first *Response, second *Response) error
+}
+
+type IdempotentOption func(c *idempotentCheck)
+
+// WithIdempotencyKey sends a generated key in header, Idempotency-Key if
+// header is empty, with both requests unless the builder already sets one.
+func WithIdempotencyKey(header string) IdempotentOption {
+	return func(c *idempotentCheck) {
+		if header == "" {
+			header = IdempotencyKeyHeader
+		}
+		c.keyHeader = header
+	}
+}
+
+// WithIdempotentMask ignores the JSON values at paths when comparing the
+// responses, see Response.Mask.
+func WithIdempotentMask(paths ...string) IdempotentOption {
+	return func(c *idempotentCheck) {
+		c.masks = append(c.masks, paths...)
+	}
+}
+
+// WithSideEffects calls check after both requests were sent to verify that
+// the second one had no side effects, e.g. by counting the created records.
+// An error returned by check is reported as a failure.
+func WithSideEffects(check func(first *Response, second *Response) error) IdempotentOption {
+	return func(c *idempotentCheck) {
+		c.sideEffects = check
+	}
+}
+
+// AssertIdempotent sends the request of b twice and checks that the second
+// response has the same status and body as the first one. JSON bodies are
+// compared ignoring formatting, object key order and masked values. Failures
+// are reported to the error handler of the second response. b is not
+// modified. It returns both responses, nil if a request failed.
+func AssertIdempotent(b *ReqBuilder, opts ...IdempotentOption) (first *Response, second *Response) {
+	b.helper()
+
+	c := &idempotentCheck{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	template := b.Clone()
+	if c.keyHeader != "" && template.headers.Get(c.keyHeader) == "" {
+		template.Header(c.keyHeader, newRequestID())
+	}
+
+	first = template.Clone().Do()
+	if first == nil {
+		return nil, nil
+	}
+	second = template.Clone().Do()
+	if second == nil {
+		return first, nil
+	}
+
+	second.helper()
+	defer second.track("AssertIdempotent")()
+
+	if first.StatusCode != second.StatusCode {
+		second.err(fmt.Errorf("request is not idempotent: first response has status %d, second %d: %s", first.StatusCode, second.StatusCode, second.bodyExcerpt()))
+		return first, second
+	}
+
+	first.Mask(c.masks...)
+	second.Mask(c.masks...)
+
+	var expected, actual interface{}
+	if json.Unmarshal(first.Body, &expected) == nil && json.Unmarshal(second.Body, &actual) == nil {
+		if diff := jsonDiff("$", first.maskJSON(expected), second.maskJSON(actual)); len(diff) > 0 {
+			second.err(second.bodyMismatch("AssertIdempotent", first.BodyStr(), "request is not idempotent: second response body differs from the first:%s", formatJSONDiff(diff)))
+		}
+	} else if !bytes.Equal(first.Body, second.Body) {
+		second.err(second.bodyMismatch("AssertIdempotent", first.BodyStr(), "request is not idempotent: second response body differs from the first %s: %s", bodyExcerpt(first.Body), second.bodyExcerpt()))
+	}
+
+	if c.sideEffects != nil {
+		if err := c.sideEffects(first, second); err != nil {
+			second.err(fmt.Errorf("request is not idempotent: %w", err))
+		}
+	}
+
+	return first, second
+}
@@ -0,0 +1,99 @@
+package httptester_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestAssertIdempotent(t *testing.T) {
+	var mu sync.Mutex
+	orders := map[string]int{}
+	created := 0
+	var keys []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		key := r.Header.Get("Idempotency-Key")
+		keys = append(keys, key)
+
+		switch r.URL.Path {
+		case "/orders":
+			id, ok := orders[key]
+			if !ok || key == "" {
+				created++
+				id = created
+				orders[key] = id
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"id":%d,"requested_at":%d}`, id, len(keys))
+		case "/counter":
+			fmt.Fprintf(w, "request %d", len(keys))
+		}
+	}))
+	defer server.Close()
+
+	var errs []error
+	newRequest := func() *httptester.ReqBuilder {
+		return collectErrors(server.URL, &errs)
+	}
+
+	first, second := httptester.AssertIdempotent(
+		newRequest().POST("/orders"),
+		httptester.WithIdempotencyKey(""),
+		httptester.WithIdempotentMask("$.requested_at"),
+		httptester.WithSideEffects(func(first *httptester.Response, second *httptester.Response) error {
+			if created != 1 {
+				return fmt.Errorf("expected 1 order got %d", created)
+			}
+			return nil
+		}),
+	)
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors got %v", errs)
+	}
+	if first == nil || second == nil {
+		t.Fatal("expected both responses")
+	}
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Fatalf("expected the same generated key got %q", keys)
+	}
+
+	b := newRequest().POST("/orders").Header("Idempotency-Key", "explicit")
+	httptester.AssertIdempotent(b, httptester.WithIdempotencyKey(""), httptester.WithIdempotentMask("$.requested_at"))
+	if keys[2] != "explicit" || keys[3] != "explicit" {
+		t.Fatalf("expected the explicit key got %q", keys[2:])
+	}
+
+	httptester.AssertIdempotent(newRequest().POST("/orders"), httptester.WithIdempotentMask("$.requested_at"))
+	httptester.AssertIdempotent(newRequest().GET("/counter"))
+	httptester.AssertIdempotent(
+		newRequest().POST("/orders"),
+		httptester.WithIdempotencyKey(""),
+		httptester.WithIdempotentMask("$.requested_at"),
+		httptester.WithSideEffects(func(first *httptester.Response, second *httptester.Response) error {
+			return fmt.Errorf("2 emails sent")
+		}),
+	)
+
+	expected := []string{
+		"request is not idempotent: second response body differs from the first:\n  ~ $.id: ",
+		"request is not idempotent: second response body differs from the first request 7: request 8",
+		"request is not idempotent: 2 emails sent",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors got %d: %v", len(expected), len(errs), errs)
+	}
+	for i, err := range errs {
+		if !strings.Contains(err.Error(), expected[i]) {
+			t.Errorf("expected error %d to contain %q got %q", i, expected[i], err)
+		}
+	}
+}
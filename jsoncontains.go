@@ -0,0 +1,197 @@
+package httptester
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type jsonMatchOptions struct {
+	ignoreArrayOrder   bool
+	allowExtraElements bool
+}
+
+// JSONOption changes how JSONContains compares arrays.
+type JSONOption func(o *jsonMatchOptions)
+
+// IgnoreArrayOrder matches elements of expected arrays with elements of the
+// actual array at any position.
+func IgnoreArrayOrder() JSONOption {
+	return func(o *jsonMatchOptions) {
+		o.ignoreArrayOrder = true
+	}
+}
+
+// AllowExtraElements lets actual arrays have more elements than expected
+// arrays. Without IgnoreArrayOrder the expected elements must be a prefix.
+func AllowExtraElements() JSONOption {
+	return func(o *jsonMatchOptions) {
+		o.allowExtraElements = true
+	}
+}
+
+func jsonPathKey(key string) string {
+	for _, c := range key {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return "[" + jsonString(key) + "]"
+		}
+	}
+	return "." + key
+}
+
+// jsonContains returns a description of the first difference between actual
+// and the fragment expected, or an empty string if actual contains it.
+// Objects may have extra fields, arrays are compared according to opts.
+func jsonContains(path string, actual interface{}, expected interface{}, opts *jsonMatchOptions) string {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return fmt.Sprintf("%s: expected object got %s", path, jsonString(actual))
+		}
+		for _, key := range sortedMapKeys(e) {
+			value, ok := a[key]
+			if !ok {
+				return fmt.Sprintf("%s: missing %s", path+jsonPathKey(key), jsonString(e[key]))
+			}
+			if diff := jsonContains(path+jsonPathKey(key), value, e[key], opts); diff != "" {
+				return diff
+			}
+		}
+		return ""
+
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			return fmt.Sprintf("%s: expected array got %s", path, jsonString(actual))
+		}
+		if len(a) < len(e) || (!opts.allowExtraElements && len(a) != len(e)) {
+			return fmt.Sprintf("%s: expected %d elements got %d", path, len(e), len(a))
+		}
+		if opts.ignoreArrayOrder {
+			if i, ok := matchJSONElements(a, e, opts); !ok {
+				return fmt.Sprintf("%s: no element matches %s", path, jsonString(e[i]))
+			}
+			return ""
+		}
+		for i := range e {
+			if diff := jsonContains(fmt.Sprintf("%s[%d]", path, i), a[i], e[i], opts); diff != "" {
+				return diff
+			}
+		}
+		return ""
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		return fmt.Sprintf("%s: expected %s got %s", path, jsonString(expected), jsonString(actual))
+	}
+
+	return ""
+}
+
+// matchJSONElements assigns each expected element a distinct actual element
+// containing it. It returns the index of an expected element that could not
+// be matched.
+func matchJSONElements(actual []interface{}, expected []interface{}, opts *jsonMatchOptions) (int, bool) {
+	used := make([]bool, len(actual))
+	failed := 0
+
+	var assign func(i int) bool
+	assign = func(i int) bool {
+		if i == len(expected) {
+			return true
+		}
+		for j := range actual {
+			if !used[j] && jsonContains("$", actual[j], expected[i], opts) == "" {
+				used[j] = true
+				if assign(i + 1) {
+					return true
+				}
+				used[j] = false
+			}
+		}
+		failed = max(failed, i)
+		return false
+	}
+
+	return failed, assign(0)
+}
+
+func sortedMapKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// JSONContains checks that the body contains the JSON fragment partial (a
+// value marshaled to JSON or a string or []byte of JSON): objects may have
+// fields partial does not mention. Arrays must match element by element
+// unless IgnoreArrayOrder or AllowExtraElements are given.
+func (r *Response) JSONContains(partial interface{}, opts ...JSONOption) *Response {
+	r.helper()
+	defer r.track("JSONContains", jsonFragment(partial))()
+
+	if !r.hasBody() {
+		return r
+	}
+
+	options := &jsonMatchOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	expected, err := decodeJSONFragment(partial)
+	if err != nil {
+		r.err(err)
+		return r
+	}
+
+	var actual interface{}
+	if err := json.Unmarshal(r.Body, &actual); err != nil {
+		r.err(err)
+		return r
+	}
+
+	if diff := jsonContains("$", actual, expected, options); diff != "" {
+		r.err(fmt.Errorf("body does not contain JSON fragment: %s", diff))
+	}
+
+	return r
+}
+
+// decodeJSONFragment decodes JSON given as a string or []byte and
+// normalizes other values.
+func decodeJSONFragment(v interface{}) (interface{}, error) {
+	var data []byte
+	switch v := v.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	case json.RawMessage:
+		data = v
+	default:
+		return normalizeJSON(v)
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("invalid JSON fragment: %w", err)
+	}
+	return decoded, nil
+}
+
+func jsonFragment(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case []byte:
+		return strings.TrimSpace(string(v))
+	}
+	return jsonString(v)
+}
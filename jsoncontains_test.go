@@ -0,0 +1,73 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestResponseJSONContains(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"id": 1,
+			"name": "alice",
+			"created_at": "2024-01-01T00:00:00Z",
+			"roles": ["admin", "dev", "ops"],
+			"groups": [{"id": 2, "name": "b"}, {"id": 1, "name": "a"}],
+			"meta": {"x-version": 3, "tags": null}
+		}`))
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	type user struct {
+		Name string `json:"name"`
+	}
+
+	c.GET("/").Do().
+		JSONContains(user{Name: "alice"}).
+		JSONContains(map[string]interface{}{"id": 1, "meta": map[string]interface{}{"tags": nil}}).
+		JSONContains(`{"roles": ["admin", "dev", "ops"]}`).
+		JSONContains(`{"roles": ["admin", "dev"]}`, httptester.AllowExtraElements()).
+		JSONContains(`{"roles": ["ops", "admin"]}`, httptester.IgnoreArrayOrder(), httptester.AllowExtraElements()).
+		JSONContains(`{"groups": [{"id": 1}, {"id": 2}]}`, httptester.IgnoreArrayOrder()).
+		JSONContains([]byte(`{"meta": {"x-version": 3}}`))
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.GET("/").Do().
+		JSONContains(`{"name": "bob"}`).
+		JSONContains(`{"email": "a@example.com"}`).
+		JSONContains(`{"roles": ["admin", "dev"]}`).
+		JSONContains(`{"groups": [{"id": 1}, {"id": 2}]}`).
+		JSONContains(`{"groups": [{"id": 1}, {"id": 3}]}`, httptester.IgnoreArrayOrder()).
+		JSONContains(`{"meta": {"x-version": "3"}}`).
+		JSONContains(`{"name": {"first": "alice"}}`).
+		JSONContains(`{`)
+
+	if len(errs) != 8 {
+		t.Fatalf("expected 8 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		`body does not contain JSON fragment: $.name: expected "bob" got "alice"`,
+		`$.email: missing "a@example.com"`,
+		"$.roles: expected 2 elements got 3",
+		"$.groups[0].id: expected 1 got 2",
+		`$.groups: no element matches {"id":3}`,
+		`$.meta["x-version"]: expected "3" got 3`,
+		`$.name: expected object got "alice"`,
+		"invalid JSON fragment",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package httptester
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// jsonDiffLimit is the maximum number of differences shown in a failure
+// message.
+const jsonDiffLimit = 20
+
+// jsonDiff returns the differences between two decoded JSON values, one per
+// line: "- path: value" for values only in expected, "+ path: value" for
+// values only in actual and "~ path: expected -> actual" for changed
+// values. Arrays are compared by index.
+func jsonDiff(path string, expected interface{}, actual interface{}) []string {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			break
+		}
+		diff := []string{}
+		for _, key := range sortedMapKeys(e) {
+			value, ok := a[key]
+			if !ok {
+				diff = append(diff, fmt.Sprintf("- %s: %s", path+jsonPathKey(key), jsonString(e[key])))
+				continue
+			}
+			diff = append(diff, jsonDiff(path+jsonPathKey(key), e[key], value)...)
+		}
+		for _, key := range sortedMapKeys(a) {
+			if _, ok := e[key]; !ok {
+				diff = append(diff, fmt.Sprintf("+ %s: %s", path+jsonPathKey(key), jsonString(a[key])))
+			}
+		}
+		return diff
+
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			break
+		}
+		diff := []string{}
+		for i := 0; i < len(e) || i < len(a); i++ {
+			elementPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(a):
+				diff = append(diff, fmt.Sprintf("- %s: %s", elementPath, jsonString(e[i])))
+			case i >= len(e):
+				diff = append(diff, fmt.Sprintf("+ %s: %s", elementPath, jsonString(a[i])))
+			default:
+				diff = append(diff, jsonDiff(elementPath, e[i], a[i])...)
+			}
+		}
+		return diff
+	}
+
+	if reflect.DeepEqual(expected, actual) {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("~ %s: %s -> %s", path, jsonString(expected), jsonString(actual))}
+}
+
+// formatJSONDiff formats the differences for a failure message.
+func formatJSONDiff(diff []string) string {
+	if len(diff) > jsonDiffLimit {
+		diff = append(diff[:jsonDiffLimit:jsonDiffLimit], fmt.Sprintf("... and %d more", len(diff)-jsonDiffLimit))
+	}
+	return "\n  " + strings.Join(diff, "\n  ")
+}
+
+// isJSONContainer reports whether v is a decoded JSON object or array,
+// whose differences are easier to read as a diff.
+func isJSONContainer(v interface{}) bool {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
+
+// JSONEq checks that the body is semantically equal to expected (a value
+// marshaled to JSON or a string or []byte of JSON), ignoring formatting and
+// object key order and masked values (see Mask). Failures list the
+// differing fields.
+func (r *Response) JSONEq(expected interface{}) *Response {
+	r.helper()
+	defer r.track("JSONEq", jsonFragment(expected))()
+
+	if !r.hasBody() {
+		return r
+	}
+
+	e, err := decodeJSONFragment(expected)
+	if err != nil {
+		r.err(err)
+		return r
+	}
+
+	var actual interface{}
+	if err := json.Unmarshal(r.Body, &actual); err != nil {
+		r.err(fmt.Errorf("body is not JSON: %w: %s", err, r.bodyExcerpt()))
+		return r
+	}
+
+	if diff := jsonDiff("$", r.maskJSON(e), r.maskJSON(actual)); len(diff) > 0 {
+		r.err(r.bodyMismatch("JSONEq", jsonFragment(expected), "body does not equal expected JSON:%s", formatJSONDiff(diff)))
+	}
+
+	return r
+}
@@ -0,0 +1,62 @@
+package httptester_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestResponseJSONDiff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/large" {
+			items := []string{}
+			for i := 0; i < 30; i++ {
+				items = append(items, fmt.Sprintf(`{"id":%d}`, i))
+			}
+			w.Write([]byte(`[` + strings.Join(items, ",") + `]`))
+			return
+		}
+		w.Write([]byte(`{"id":1,"name":"alice","tags":["a","b"],"profile":{"age":30,"city":"Paris"}}`))
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	c.GET("/").Do().
+		JSONEq(`{"name":"alice","id":1,"profile":{"city":"Paris","age":30},"tags":["a","b"]}`).
+		JSONEq(map[string]interface{}{"id": 1, "name": "alice", "tags": []string{"a", "b"}, "profile": map[string]interface{}{"age": 30, "city": "Paris"}})
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.GET("/").Do().
+		JSONEq(`{"id":2,"name":"alice","email":"a@example.com","tags":["a"],"profile":{"age":30,"city":"Berlin"}}`).
+		Eq(`{"id":1,"name":"bob","tags":["a","b"],"profile":{"age":30,"city":"Paris"}}`).
+		JSONPath("$.profile", map[string]interface{}{"age": 31, "city": "Paris"})
+	b.GET("/large").Do().JSONEq(`[]`)
+
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		"body does not equal expected JSON:\n" +
+			"  - $.email: \"a@example.com\"\n" +
+			"  ~ $.id: 2 -> 1\n" +
+			"  ~ $.profile.city: \"Berlin\" -> \"Paris\"\n" +
+			"  + $.tags[1]: \"b\"\n",
+		"body does not equal expected JSON:\n  ~ $.name: \"bob\" -> \"alice\"\n",
+		"JSONPath $.profile: does not equal expected value:\n  ~ $.profile.age: 31 -> 30\n",
+		"  + $[19]: {\"id\":19}\n  ... and 10 more\n",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package httptester
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type jsonPathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+func (s jsonPathSegment) String() string {
+	if s.isIndex {
+		return "[" + strconv.Itoa(s.index) + "]"
+	}
+	return "." + s.key
+}
+
+// parseJSONPath parses a subset of JSONPath: $, .key, ['key'], ["key"] and
+// [index] (negative indexes count from the end of the array).
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("invalid JSONPath %s: must start with $", path)
+	}
+
+	segments := []jsonPathSegment{}
+	rest := path[1:]
+
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid JSONPath %s: empty key", path)
+			}
+			segments = append(segments, jsonPathSegment{key: rest[:end]})
+			rest = rest[end:]
+
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("invalid JSONPath %s: missing ]", path)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				segments = append(segments, jsonPathSegment{key: inner[1 : len(inner)-1]})
+				continue
+			}
+
+			index, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSONPath %s: invalid index %s", path, inner)
+			}
+			segments = append(segments, jsonPathSegment{index: index, isIndex: true})
+
+		default:
+			return nil, fmt.Errorf("invalid JSONPath %s: unexpected %q", path, rest[0])
+		}
+	}
+
+	return segments, nil
+}
+
+func evalJSONPath(v interface{}, path string) (interface{}, error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := "$"
+
+	for _, segment := range segments {
+		if segment.isIndex {
+			arr, ok := v.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s is not an array", current)
+			}
+			index := segment.index
+			if index < 0 {
+				index += len(arr)
+			}
+			if index < 0 || index >= len(arr) {
+				return nil, fmt.Errorf("%s%s is out of range (length %d)", current, segment, len(arr))
+			}
+			v = arr[index]
+		} else {
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s is not an object", current)
+			}
+			value, ok := obj[segment.key]
+			if !ok {
+				return nil, fmt.Errorf("%s%s does not exist", current, segment)
+			}
+			v = value
+		}
+
+		current += segment.String()
+	}
+
+	return v, nil
+}
+
+// normalizeJSON converts v into the same shape json.Unmarshal produces for
+// interface{} so it can be compared against decoded values.
+func normalizeJSON(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+func jsonString(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
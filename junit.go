@@ -0,0 +1,252 @@
+package httptester
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JUnitReporter records every assertion made on responses of builders it is
+// attached to (see ReqBuilder.Reporter) with its result and timing and
+// writes them as a JUnit XML report. Each request endpoint becomes a test
+// suite and each assertion a test case so CI dashboards show results per
+// assertion.
+type JUnitReporter struct {
+	mu      sync.Mutex
+	name    string
+	results []AssertionResult
+}
+
+type AssertionResult struct {
+	// Request is the method and URL template of the request, e.g.
+	// GET /users/{id}.
+	Request string
+	// Assertion is the assertion with its arguments, e.g. Status(200).
+	Assertion string
+	Time      time.Time
+	Duration  time.Duration
+	Errors    []error
+}
+
+func (a AssertionResult) Failed() bool {
+	return len(a.Errors) > 0
+}
+
+func NewJUnitReporter(name string) *JUnitReporter {
+	return &JUnitReporter{
+		name: name,
+	}
+}
+
+func (j *JUnitReporter) Results() []AssertionResult {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return append([]AssertionResult(nil), j.results...)
+}
+
+func (j *JUnitReporter) add(result AssertionResult) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.results = append(j.results, result)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// Write writes the report as JUnit XML to w.
+func (j *JUnitReporter) Write(w io.Writer) error {
+	results := j.Results()
+
+	suites := junitTestSuites{
+		Name: j.name,
+	}
+	index := map[string]int{}
+	durations := map[string]time.Duration{}
+	var total time.Duration
+
+	for _, result := range results {
+		i, ok := index[result.Request]
+		if !ok {
+			i = len(suites.Suites)
+			index[result.Request] = i
+			suites.Suites = append(suites.Suites, junitTestSuite{
+				Name:      result.Request,
+				Timestamp: result.Time.UTC().Format("2006-01-02T15:04:05"),
+			})
+		}
+		suite := &suites.Suites[i]
+
+		testCase := junitTestCase{
+			Name:      result.Assertion,
+			ClassName: result.Request,
+			Time:      junitSeconds(result.Duration),
+		}
+		if result.Failed() {
+			msgs := make([]string, len(result.Errors))
+			for k, err := range result.Errors {
+				msgs[k] = err.Error()
+			}
+			testCase.Failure = &junitFailure{
+				Message: firstLine(msgs[0]),
+				Type:    "AssertionError",
+				Text:    strings.Join(msgs, "\n\n"),
+			}
+			suite.Failures++
+			suites.Failures++
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+		suite.Tests++
+		suites.Tests++
+		durations[result.Request] += result.Duration
+		total += result.Duration
+	}
+
+	for i := range suites.Suites {
+		suites.Suites[i].Time = junitSeconds(durations[suites.Suites[i].Name])
+	}
+	suites.Time = junitSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func (j *JUnitReporter) WriteFile(path string) error {
+	f, err := createFile(path)
+	if err != nil {
+		return err
+	}
+
+	err = j.Write(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+func (b *ReqBuilder) Reporter(reporter *JUnitReporter) *ReqBuilder {
+	b.reporter = reporter
+	return b
+}
+
+// track records the assertion name(args) to the reporter when the returned
+// function is called. It is meant to be deferred at the start of every
+// assertion so failures are recorded even if onError stops the goroutine.
+// Assertions called by other assertions are not recorded separately.
+func (r *Response) track(name string, args ...interface{}) func() {
+	if r.reporter == nil && r.failures == nil {
+		return func() {}
+	}
+
+	r.tracking++
+	if r.tracking > 1 {
+		return func() {
+			r.tracking--
+		}
+	}
+
+	r.assertion = name
+	if r.reporter == nil {
+		return func() {
+			r.tracking--
+			r.assertion = ""
+		}
+	}
+
+	start := time.Now()
+	errs := len(r.errs)
+
+	return func() {
+		r.tracking--
+		r.assertion = ""
+		r.reporter.add(AssertionResult{
+			Request:   r.reportName,
+			Assertion: name + "(" + formatAssertionArgs(args) + ")",
+			Time:      start,
+			Duration:  time.Since(start),
+			Errors:    append([]error(nil), r.errs[errs:]...),
+		})
+	}
+}
+
+func formatAssertionArgs(args []interface{}) string {
+	formatted := []string{}
+	for _, arg := range args {
+		if ints, ok := arg.([]int); ok {
+			for _, i := range ints {
+				formatted = append(formatted, formatAssertionArg(i))
+			}
+			continue
+		}
+		if strs, ok := arg.([]string); ok {
+			for _, s := range strs {
+				formatted = append(formatted, formatAssertionArg(s))
+			}
+			continue
+		}
+		formatted = append(formatted, formatAssertionArg(arg))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func formatAssertionArg(arg interface{}) string {
+	if s, ok := arg.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%v", arg)
+}
@@ -0,0 +1,101 @@
+package httptester_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestJUnitReporter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name": "Ann"}`))
+	}))
+	defer server.Close()
+
+	reporter := httptester.NewJUnitReporter("users")
+
+	var errs []error
+	c := httptester.NewClient(
+		httptester.WithBaseURL(server.URL),
+		httptester.WithReporter(reporter),
+		httptester.WithOnError(func(err error) {
+			errs = append(errs, err)
+		}),
+	)
+
+	c.GET("/users/{id}").Param("id", "42").Do().
+		Status(200, 201).
+		JSONPath("$.name", "Ann").
+		JSONSchemaFile("testdata/missing.json").
+		HeaderEq("Content-Type", "text/plain")
+	c.POST("/users").Do().Status(201)
+
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors got %v", errs)
+	}
+
+	results := reporter.Results()
+	expected := []struct {
+		request   string
+		assertion string
+		failed    bool
+	}{
+		{"GET /users/{id}", "Status(200, 201)", false},
+		{"GET /users/{id}", `JSONPath("$.name", "Ann")`, false},
+		{"GET /users/{id}", `JSONSchemaFile("testdata/missing.json")`, true},
+		{"GET /users/{id}", `HeaderEq("Content-Type", "text/plain")`, true},
+		{"POST /users", "Status(201)", true},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results got %d: %v", len(expected), len(results), results)
+	}
+	for i, e := range expected {
+		r := results[i]
+		if r.Request != e.request || r.Assertion != e.assertion || r.Failed() != e.failed {
+			t.Errorf("result %d: expected %s %s failed=%t got %s %s failed=%t", i, e.request, e.assertion, e.failed, r.Request, r.Assertion, r.Failed())
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := reporter.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var report struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Name     string `xml:"name,attr"`
+			Tests    int    `xml:"tests,attr"`
+			Failures int    `xml:"failures,attr"`
+			Cases    []struct {
+				Name    string `xml:"name,attr"`
+				Failure *struct {
+					Message string `xml:"message,attr"`
+					Text    string `xml:",chardata"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Tests != 5 || report.Failures != 3 || len(report.Suites) != 2 {
+		t.Fatalf("unexpected report %s", buf.String())
+	}
+	if s := report.Suites[0]; s.Name != "GET /users/{id}" || s.Tests != 4 || s.Failures != 2 {
+		t.Fatalf("unexpected suite %s", buf.String())
+	}
+
+	failure := report.Suites[0].Cases[3].Failure
+	if failure == nil || failure.Message != "header Content-Type: expected application/json to equal text/plain" || !strings.Contains(failure.Text, failure.Message) {
+		t.Fatalf("unexpected failure %s", buf.String())
+	}
+}
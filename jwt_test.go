@@ -0,0 +1,129 @@
+package httptester_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bancek/httptester"
+)
+
+func TestSignJWT(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPublic, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := map[string]interface{}{"sub": "ann", "admin": true}
+
+	for _, tc := range []struct {
+		alg    string
+		key    interface{}
+		verify func(input []byte, signature []byte) bool
+	}{
+		{"HS256", "secret", func(input []byte, signature []byte) bool {
+			mac := hmac.New(sha256.New, []byte("secret"))
+			mac.Write(input)
+			return hmac.Equal(mac.Sum(nil), signature)
+		}},
+		{"RS256", rsaKey, func(input []byte, signature []byte) bool {
+			digest := sha256.Sum256(input)
+			return rsa.VerifyPKCS1v15(&rsaKey.PublicKey, crypto.SHA256, digest[:], signature) == nil
+		}},
+		{"PS256", rsaKey, func(input []byte, signature []byte) bool {
+			digest := sha256.Sum256(input)
+			return rsa.VerifyPSS(&rsaKey.PublicKey, crypto.SHA256, digest[:], signature, nil) == nil
+		}},
+		{"ES256", ecKey, func(input []byte, signature []byte) bool {
+			digest := sha256.Sum256(input)
+			r := new(big.Int).SetBytes(signature[:32])
+			s := new(big.Int).SetBytes(signature[32:])
+			return len(signature) == 64 && ecdsa.Verify(&ecKey.PublicKey, digest[:], r, s)
+		}},
+		{"EdDSA", edKey, func(input []byte, signature []byte) bool {
+			return ed25519.Verify(edPublic, input, signature)
+		}},
+	} {
+		t.Run(tc.alg, func(t *testing.T) {
+			token, err := httptester.SignJWT(claims, tc.key, tc.alg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parts := strings.Split(token, ".")
+			if len(parts) != 3 {
+				t.Fatalf("invalid token %s", token)
+			}
+
+			header, _ := base64.RawURLEncoding.DecodeString(parts[0])
+			if string(header) != `{"alg":"`+tc.alg+`","typ":"JWT"}` {
+				t.Fatalf("unexpected header %s", header)
+			}
+
+			payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+			if string(payload) != `{"admin":true,"sub":"ann"}` {
+				t.Fatalf("unexpected payload %s", payload)
+			}
+
+			signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+			if !tc.verify([]byte(parts[0]+"."+parts[1]), signature) {
+				t.Fatalf("invalid signature")
+			}
+		})
+	}
+
+	if _, err := httptester.SignJWT(claims, "secret", "RS256"); err == nil || err.Error() != "JWT alg RS256 requires an *rsa.PrivateKey got string" {
+		t.Fatalf("expected key type error got %v", err)
+	}
+	if _, err := httptester.SignJWT(claims, "secret", "none"); err == nil {
+		t.Fatalf("expected unsupported alg error")
+	}
+}
+
+func TestReqBuilderBearerJWT(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		parts := strings.Split(token, ".")
+		payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+
+		var claims struct {
+			Exp int64 `json:"exp"`
+		}
+		json.Unmarshal(payload, &claims)
+		if claims.Exp < time.Now().Unix() {
+			w.WriteHeader(401)
+			return
+		}
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	c.GET("/").BearerJWT(map[string]interface{}{"sub": "ann", "exp": time.Now().Add(time.Hour).Unix()}, []byte("key"), "HS256").
+		Do().Status(200).JSONPath("$.sub", "ann")
+
+	c.GET("/").BearerJWT(map[string]interface{}{"sub": "ann", "exp": time.Now().Add(-time.Hour).Unix()}, []byte("key"), "HS256").
+		Do().Status(401)
+}
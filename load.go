@@ -0,0 +1,379 @@
+package httptester
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoadRunner sends copies of a template request at a fixed rate or with a
+// fixed number of concurrent workers for a duration and collects Stats.
+type LoadRunner struct {
+	template    *ReqBuilder
+	rps         float64
+	concurrency int
+	duration    time.Duration
+}
+
+// Load returns a LoadRunner for template. By default it runs one worker
+// for 10 seconds. Errors are counted in Stats instead of being reported.
+func Load(template *ReqBuilder) *LoadRunner {
+	return &LoadRunner{
+		template: template,
+		duration: 10 * time.Second,
+	}
+}
+
+// RPS starts rps requests per second regardless of how long they take. The
+// number of requests in flight is limited by Concurrency only if it is set.
+func (l *LoadRunner) RPS(rps float64) *LoadRunner {
+	l.rps = rps
+	return l
+}
+
+// Concurrency sets the number of workers sending requests back to back, or
+// the limit of requests in flight when used with RPS.
+func (l *LoadRunner) Concurrency(n int) *LoadRunner {
+	l.concurrency = n
+	return l
+}
+
+func (l *LoadRunner) Duration(d time.Duration) *LoadRunner {
+	l.duration = d
+	return l
+}
+
+func (l *LoadRunner) Run() *Stats {
+	// Cloning buffers the body once so the copies can be sent concurrently.
+	proto := l.template.Clone()
+
+	rec := newStatsRecorder(proto)
+
+	deadline := time.Now().Add(l.duration)
+	wg := &sync.WaitGroup{}
+
+	if l.rps > 0 {
+		var sem chan struct{}
+		if l.concurrency > 0 {
+			sem = make(chan struct{}, l.concurrency)
+		}
+
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / l.rps))
+		defer ticker.Stop()
+
+		for now := time.Now(); now.Before(deadline); now = <-ticker.C {
+			if sem != nil {
+				sem <- struct{}{}
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rec.record(proto.Clone().exec())
+				if sem != nil {
+					<-sem
+				}
+			}()
+		}
+	} else {
+		workers := l.concurrency
+		if workers <= 0 {
+			workers = 1
+		}
+
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for time.Now().Before(deadline) {
+					rec.record(proto.Clone().exec())
+				}
+			}()
+		}
+	}
+
+	wg.Wait()
+
+	return rec.stats()
+}
+
+// Repeater sends copies of a request a fixed number of times.
+type Repeater struct {
+	req         *ReqBuilder
+	n           int
+	concurrency int
+}
+
+// Repeat returns a Repeater that sends the request n times. Errors are
+// counted in Stats instead of being reported.
+func (b *ReqBuilder) Repeat(n int) *Repeater {
+	return &Repeater{
+		req:         b,
+		n:           n,
+		concurrency: 1,
+	}
+}
+
+func (r *Repeater) Concurrency(n int) *Repeater {
+	if n < 1 {
+		n = 1
+	}
+	r.concurrency = n
+	return r
+}
+
+func (r *Repeater) Collect() *Stats {
+	proto := r.req.Clone()
+
+	rec := newStatsRecorder(proto)
+
+	jobs := make(chan struct{}, r.n)
+	for i := 0; i < r.n; i++ {
+		jobs <- struct{}{}
+	}
+	close(jobs)
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < r.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				rec.record(proto.Clone().exec())
+			}
+		}()
+	}
+	wg.Wait()
+
+	return rec.stats()
+}
+
+// exec sends the request and discards the body without reporting errors.
+func (b *ReqBuilder) exec() (int, time.Duration, error) {
+	ctx, cancel := b.requestContext()
+	defer cancel()
+
+	start := time.Now()
+
+	sent, err := b.send(ctx)
+	if err != nil {
+		return 0, time.Since(start), err
+	}
+	defer sent.res.Body.Close()
+
+	_, err = io.Copy(io.Discard, sent.res.Body)
+
+	return sent.res.StatusCode, time.Since(sent.start), err
+}
+
+// Stats are the results of sending a request many times. Latencies are
+// sorted in ascending order and only include requests that got a response.
+type Stats struct {
+	Requests  int
+	Errors    int
+	Statuses  map[int]int
+	Latencies []time.Duration
+	Duration  time.Duration
+
+	name    string
+	onError func(error)
+	helper  func()
+}
+
+type statsRecorder struct {
+	mu    sync.Mutex
+	start time.Time
+	s     *Stats
+}
+
+func newStatsRecorder(b *ReqBuilder) *statsRecorder {
+	method := b.method
+	if method == "" {
+		method = "GET"
+	}
+
+	return &statsRecorder{
+		start: time.Now(),
+		s: &Stats{
+			Statuses: map[int]int{},
+			name:     method + " " + b.endpoint(),
+			onError:  b.onError,
+			helper:   b.helper,
+		},
+	}
+}
+
+func (r *statsRecorder) record(status int, d time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.s.Requests++
+	if err != nil {
+		r.s.Errors++
+		return
+	}
+	r.s.Statuses[status]++
+	r.s.Latencies = append(r.s.Latencies, d)
+}
+
+func (r *statsRecorder) stats() *Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.s.Duration = time.Since(r.start)
+	sort.Slice(r.s.Latencies, func(i, j int) bool {
+		return r.s.Latencies[i] < r.s.Latencies[j]
+	})
+
+	return r.s
+}
+
+// RPS returns the achieved number of requests per second.
+func (s *Stats) RPS() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Requests) / s.Duration.Seconds()
+}
+
+func (s *Stats) Min() time.Duration {
+	if len(s.Latencies) == 0 {
+		return 0
+	}
+	return s.Latencies[0]
+}
+
+func (s *Stats) Max() time.Duration {
+	if len(s.Latencies) == 0 {
+		return 0
+	}
+	return s.Latencies[len(s.Latencies)-1]
+}
+
+func (s *Stats) Mean() time.Duration {
+	if len(s.Latencies) == 0 {
+		return 0
+	}
+	var sum time.Duration
+	for _, d := range s.Latencies {
+		sum += d
+	}
+	return sum / time.Duration(len(s.Latencies))
+}
+
+// Percentile returns the latency below which p percent of the latencies
+// fall using the nearest-rank method.
+func (s *Stats) Percentile(p float64) time.Duration {
+	if len(s.Latencies) == 0 {
+		return 0
+	}
+
+	i := int(math.Ceil(p/100*float64(len(s.Latencies)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(s.Latencies) {
+		i = len(s.Latencies) - 1
+	}
+
+	return s.Latencies[i]
+}
+
+func (s *Stats) P50() time.Duration {
+	return s.Percentile(50)
+}
+
+func (s *Stats) P95() time.Duration {
+	return s.Percentile(95)
+}
+
+func (s *Stats) P99() time.Duration {
+	return s.Percentile(99)
+}
+
+// ErrorRate returns the fraction of requests that failed or got a 5xx
+// response.
+func (s *Stats) ErrorRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+
+	failed := s.Errors
+	for status, n := range s.Statuses {
+		if status >= 500 {
+			failed += n
+		}
+	}
+
+	return float64(failed) / float64(s.Requests)
+}
+
+func (s *Stats) err(err error) {
+	s.helper()
+
+	s.onError(fmt.Errorf("%s: %s\n%s", s.name, err, s))
+}
+
+func (s *Stats) latencyUnder(name string, got time.Duration, d time.Duration) *Stats {
+	s.helper()
+
+	if got >= d {
+		s.err(fmt.Errorf("expected %s latency under %s got %s", name, d, got))
+	}
+
+	return s
+}
+
+func (s *Stats) P50Under(d time.Duration) *Stats {
+	s.helper()
+
+	return s.latencyUnder("p50", s.P50(), d)
+}
+
+func (s *Stats) P95Under(d time.Duration) *Stats {
+	s.helper()
+
+	return s.latencyUnder("p95", s.P95(), d)
+}
+
+func (s *Stats) P99Under(d time.Duration) *Stats {
+	s.helper()
+
+	return s.latencyUnder("p99", s.P99(), d)
+}
+
+func (s *Stats) MaxUnder(d time.Duration) *Stats {
+	s.helper()
+
+	return s.latencyUnder("max", s.Max(), d)
+}
+
+func (s *Stats) ErrorRateBelow(rate float64) *Stats {
+	s.helper()
+
+	if got := s.ErrorRate(); got >= rate {
+		s.err(fmt.Errorf("expected error rate below %.2f%% got %.2f%%", rate*100, got*100))
+	}
+
+	return s
+}
+
+func (s *Stats) String() string {
+	statuses := make([]int, 0, len(s.Statuses))
+	for status := range s.Statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+
+	counts := make([]string, len(statuses))
+	for i, status := range statuses {
+		counts[i] = fmt.Sprintf("%d=%d", status, s.Statuses[status])
+	}
+
+	return fmt.Sprintf("%d requests in %s (%.1f rps), %d errors, statuses [%s], latency min %s mean %s p50 %s p95 %s p99 %s max %s",
+		s.Requests, s.Duration.Round(time.Millisecond), s.RPS(), s.Errors, strings.Join(counts, " "),
+		s.Min(), s.Mean(), s.P50(), s.P95(), s.P99(), s.Max())
+}
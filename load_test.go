@@ -0,0 +1,89 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bancek/httptester"
+)
+
+func TestLoad(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1)%5 == 0 {
+			w.WriteHeader(503)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	stats := httptester.Load(c.POST("/orders").JSON(map[string]int{"qty": 1})).
+		Concurrency(4).
+		Duration(100 * time.Millisecond).
+		Run()
+
+	if stats.Requests < 20 || stats.Errors != 0 || stats.Statuses[200]+stats.Statuses[503] != stats.Requests || stats.Statuses[503] == 0 {
+		t.Fatalf("unexpected stats %s", stats)
+	}
+	if len(stats.Latencies) != stats.Requests || stats.Min() < 5*time.Millisecond || stats.Max() < stats.Mean() {
+		t.Fatalf("unexpected latencies %s", stats)
+	}
+
+	stats = httptester.Load(c.GET("/")).RPS(100).Duration(200 * time.Millisecond).Run()
+	if stats.Requests < 15 || stats.Requests > 25 {
+		t.Fatalf("unexpected number of requests %s", stats)
+	}
+	if !strings.Contains(stats.String(), " requests in ") {
+		t.Fatalf("unexpected summary %s", stats)
+	}
+
+	stats = httptester.Load(httptester.NewReqBuilder("http://127.0.0.1:1", http.DefaultClient, func(err error) {
+		t.Fatal(err)
+	}).GET("/")).Duration(20 * time.Millisecond).Run()
+	if stats.Requests == 0 || stats.Errors != stats.Requests {
+		t.Fatalf("expected only errors got %s", stats)
+	}
+}
+
+func TestRepeat(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 10 {
+			w.WriteHeader(500)
+		}
+		time.Sleep(time.Duration(n%5) * time.Millisecond)
+	}))
+	defer server.Close()
+
+	var errs []error
+	stats := collectErrors(server.URL, &errs).GET("/items/{id}").Param("id", "1").
+		Repeat(20).Concurrency(4).Collect().
+		P50Under(time.Second).
+		P99Under(time.Second).
+		MaxUnder(time.Second).
+		ErrorRateBelow(0.1)
+
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	if stats.Requests != 20 || stats.Statuses[500] != 1 || stats.ErrorRate() != 0.05 {
+		t.Fatalf("unexpected stats %s", stats)
+	}
+	if stats.P50() > stats.P95() || stats.P95() > stats.P99() || stats.P99() != stats.Max() {
+		t.Fatalf("unexpected percentiles %s", stats)
+	}
+
+	stats.P99Under(time.Nanosecond).ErrorRateBelow(0.01)
+	if len(errs) != 2 ||
+		!strings.Contains(errs[0].Error(), "GET /items/{id}: expected p99 latency under 1ns got") ||
+		!strings.Contains(errs[1].Error(), "expected error rate below 1.00% got 5.00%") {
+		t.Fatalf("expected threshold errors got %v", errs)
+	}
+}
@@ -0,0 +1,112 @@
+package httptester
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaskedValue replaces masked JSON values.
+const MaskedValue = "<masked>"
+
+// Mask replaces the JSON values at paths with MaskedValue before the body
+// is compared by MatchSnapshot and JSONEq, so volatile values such as
+// generated ids and timestamps do not break the comparison. JSONEq masks
+// the expected value too. Paths are JSONPaths as in JSONPath plus [*] for
+// every element of an array, e.g. $.items[*].created_at. Paths that do not
+// exist are ignored.
+func (r *Response) Mask(paths ...string) *Response {
+	r.helper()
+
+	for _, path := range paths {
+		if _, err := parseMaskPath(path); err != nil {
+			r.err(err)
+			return r
+		}
+	}
+
+	r.masks = append(r.masks, paths...)
+	return r
+}
+
+// parseMaskPath splits a mask path at [*] wildcards into JSONPath segments.
+func parseMaskPath(path string) ([][]jsonPathSegment, error) {
+	parts := strings.Split(path, "[*]")
+	groups := make([][]jsonPathSegment, len(parts))
+
+	for i, part := range parts {
+		if i > 0 {
+			part = "$" + part
+		}
+		segments, err := parseJSONPath(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mask path %s: %w", path, err)
+		}
+		groups[i] = segments
+	}
+
+	return groups, nil
+}
+
+// maskJSON replaces the values at the response's mask paths in v, a value
+// decoded from JSON, and returns the result.
+func (r *Response) maskJSON(v interface{}) interface{} {
+	for _, path := range r.masks {
+		groups, _ := parseMaskPath(path)
+		v = replaceJSONPath(v, groups, MaskedValue)
+	}
+	return v
+}
+
+// replaceJSONPath replaces the values at the mask path groups in v with
+// value.
+func replaceJSONPath(v interface{}, groups [][]jsonPathSegment, value interface{}) interface{} {
+	return walkJSONPath(v, groups, func(interface{}) interface{} {
+		return value
+	})
+}
+
+// walkJSONPath calls visit with the values at the mask path groups in v and
+// replaces them with the results.
+func walkJSONPath(v interface{}, groups [][]jsonPathSegment, visit func(value interface{}) interface{}) interface{} {
+	segments := groups[0]
+	if len(segments) == 0 {
+		if len(groups) == 1 {
+			return visit(v)
+		}
+		arr, ok := v.([]interface{})
+		if !ok {
+			return v
+		}
+		for i := range arr {
+			arr[i] = walkJSONPath(arr[i], groups[1:], visit)
+		}
+		return arr
+	}
+
+	rest := append([][]jsonPathSegment{segments[1:]}, groups[1:]...)
+	segment := segments[0]
+
+	if segment.isIndex {
+		arr, ok := v.([]interface{})
+		if !ok {
+			return v
+		}
+		index := segment.index
+		if index < 0 {
+			index += len(arr)
+		}
+		if index >= 0 && index < len(arr) {
+			arr[index] = walkJSONPath(arr[index], rest, visit)
+		}
+		return arr
+	}
+
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	if elem, ok := obj[segment.key]; ok {
+		obj[segment.key] = walkJSONPath(elem, rest, visit)
+	}
+	return obj
+}
@@ -0,0 +1,72 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bancek/httptester"
+)
+
+func TestResponseMask(t *testing.T) {
+	dir := t.TempDir()
+	defer func(old string) { httptester.SnapshotDir = old }(httptester.SnapshotDir)
+	httptester.SnapshotDir = dir
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		now := time.Now().Format(time.RFC3339Nano)
+		w.Write([]byte(`{"id":"` + now + `","name":"alice","items":[{"id":1,"at":"` + now + `"},{"id":2,"at":"` + now + `"}]}`))
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	t.Setenv("HTTPTESTER_UPDATE", "1")
+	c.GET("/").Do().
+		Mask("$.id", "$.items[*].at", "$.missing", "$.items[5].at").
+		JSONEq(`{"id":"x","name":"alice","items":[{"id":1,"at":"y"},{"id":2,"at":"z"}]}`).
+		MatchSnapshot(t)
+
+	c.GET("/").Do().
+		Mask("$.id", "$.items[*].at").
+		MatchSnapshot(t)
+	os.Unsetenv("HTTPTESTER_UPDATE")
+
+	data, err := os.ReadFile(filepath.Join(dir, "TestResponseMask.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"at": "<masked>"`) || !strings.Contains(string(data), `"id": "<masked>"`) {
+		t.Fatalf("expected masked snapshot got %s", data)
+	}
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.GET("/").Do().
+		Mask("$.id").
+		JSONEq(`{"id":"x","name":"bob","items":[{"id":1,"at":"y"},{"id":2,"at":"z"}]}`).
+		Mask("id")
+
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		"body does not equal expected JSON:\n  ~ $.items[0].at:",
+		"invalid mask path id: invalid JSONPath id: must start with $",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+	if strings.Contains(errs[0].Error(), "$.id") {
+		t.Errorf("expected masked $.id not to be reported got %s", errs[0])
+	}
+}
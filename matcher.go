@@ -0,0 +1,75 @@
+package httptester
+
+import (
+	"fmt"
+)
+
+// Matcher is a reusable assertion on a response, e.g. a check of a
+// response envelope shared by the tests of a team. Matchers that implement
+// fmt.Stringer are reported by that name.
+type Matcher interface {
+	Match(r *Response) error
+}
+
+// MatcherFunc adapts a function to a Matcher.
+type MatcherFunc func(r *Response) error
+
+func (f MatcherFunc) Match(r *Response) error {
+	return f(r)
+}
+
+// NamedMatcher returns a Matcher calling f that is reported as name.
+func NamedMatcher(name string, f func(r *Response) error) Matcher {
+	return namedMatcher{name: name, f: f}
+}
+
+type namedMatcher struct {
+	name string
+	f    func(r *Response) error
+}
+
+func (m namedMatcher) Match(r *Response) error {
+	return m.f(r)
+}
+
+func (m namedMatcher) String() string {
+	return m.name
+}
+
+func matcherName(m Matcher) string {
+	if s, ok := m.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", m)
+}
+
+// Assert calls f with the response and fails with the error it returns.
+func (r *Response) Assert(f func(r *Response) error) *Response {
+	r.helper()
+	defer r.track("Assert")()
+
+	if err := f(r); err != nil {
+		r.err(err)
+	}
+
+	return r
+}
+
+// Satisfies checks the response with each of the matchers. The error of a
+// failed matcher is prefixed with its name.
+func (r *Response) Satisfies(matchers ...Matcher) *Response {
+	r.helper()
+
+	for _, m := range matchers {
+		name := matcherName(m)
+		func() {
+			defer r.track("Satisfies", name)()
+
+			if err := m.Match(r); err != nil {
+				r.err(fmt.Errorf("%s: %w", name, err))
+			}
+		}()
+	}
+
+	return r
+}
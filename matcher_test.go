@@ -0,0 +1,95 @@
+package httptester_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+// envelope checks the {"data": ..., "tenant": ...} envelope of responses.
+type envelope struct {
+	tenant string
+}
+
+func (e envelope) Match(r *httptester.Response) error {
+	var body struct {
+		Data   json.RawMessage `json:"data"`
+		Tenant string          `json:"tenant"`
+	}
+	if err := json.Unmarshal(r.Body, &body); err != nil {
+		return err
+	}
+	if body.Data == nil {
+		return errors.New("missing data")
+	}
+	if body.Tenant != e.tenant {
+		return fmt.Errorf("expected tenant %s got %s", e.tenant, body.Tenant)
+	}
+	return nil
+}
+
+func (e envelope) String() string {
+	return "envelope(" + e.tenant + ")"
+}
+
+func TestResponseMatcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"id":1},"tenant":"acme"}`))
+	}))
+	defer server.Close()
+
+	hasJSON := httptester.NamedMatcher("hasJSON", func(r *httptester.Response) error {
+		if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+			return errors.New("not JSON")
+		}
+		return nil
+	})
+
+	c := httptester.New(t, server.URL)
+
+	c.GET("/").Do().
+		Assert(func(r *httptester.Response) error {
+			if r.StatusCode != 200 {
+				return fmt.Errorf("status %d", r.StatusCode)
+			}
+			return nil
+		}).
+		Satisfies(envelope{tenant: "acme"}, hasJSON)
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.GET("/").Do().
+		Assert(func(r *httptester.Response) error {
+			return errors.New("custom failure")
+		}).
+		Satisfies(
+			envelope{tenant: "other"},
+			httptester.MatcherFunc(func(r *httptester.Response) error {
+				return errors.New("func failure")
+			}),
+		)
+
+	expected := []string{
+		"custom failure",
+		"envelope(other): expected tenant other got acme",
+		"httptester.MatcherFunc: func failure",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors got %v", len(expected), errs)
+	}
+	for i, msg := range expected {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package httptester
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMetricsBuckets are the latency histogram buckets in seconds.
+var DefaultMetricsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Metrics collects request counts, status codes and latency histograms of
+// builders it is attached to (see ReqBuilder.Metrics) and exposes them in
+// the Prometheus text format. Endpoints are labeled with the URL template
+// passed to the builder (e.g. /users/{id}) to keep the cardinality low.
+// Every attempt of a retried request is counted and latency is measured
+// until the response headers are received.
+type Metrics struct {
+	mu      sync.Mutex
+	buckets []float64
+	series  map[metricsKey]*metricsSeries
+}
+
+type metricsKey struct {
+	method   string
+	endpoint string
+}
+
+type metricsSeries struct {
+	codes   map[string]uint64
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+// NewMetrics returns a Metrics using buckets (in seconds) for the latency
+// histogram or DefaultMetricsBuckets if none are given.
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultMetricsBuckets
+	}
+
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &Metrics{
+		buckets: buckets,
+		series:  map[metricsKey]*metricsSeries{},
+	}
+}
+
+func (m *Metrics) observe(method string, endpoint string, res *http.Response, err error, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := metricsKey{method: method, endpoint: endpoint}
+
+	s, ok := m.series[key]
+	if !ok {
+		s = &metricsSeries{
+			codes:   map[string]uint64{},
+			buckets: make([]uint64, len(m.buckets)),
+		}
+		m.series[key] = s
+	}
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(res.StatusCode)
+	}
+	s.codes[code]++
+
+	seconds := d.Seconds()
+	for i, le := range m.buckets {
+		if seconds <= le {
+			s.buckets[i]++
+		}
+	}
+	s.count++
+	s.sum += seconds
+}
+
+// Write writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) Write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]metricsKey, 0, len(m.series))
+	for key := range m.series {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].endpoint != keys[j].endpoint {
+			return keys[i].endpoint < keys[j].endpoint
+		}
+		return keys[i].method < keys[j].method
+	})
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "# HELP httptester_requests_total Number of requests sent.")
+	fmt.Fprintln(bw, "# TYPE httptester_requests_total counter")
+	for _, key := range keys {
+		s := m.series[key]
+
+		codes := make([]string, 0, len(s.codes))
+		for code := range s.codes {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+
+		for _, code := range codes {
+			fmt.Fprintf(bw, "httptester_requests_total{%s,code=%s} %d\n", key.labels(), metricsLabel(code), s.codes[code])
+		}
+	}
+
+	fmt.Fprintln(bw, "# HELP httptester_request_duration_seconds Request latency in seconds.")
+	fmt.Fprintln(bw, "# TYPE httptester_request_duration_seconds histogram")
+	for _, key := range keys {
+		s := m.series[key]
+		labels := key.labels()
+
+		for i, le := range m.buckets {
+			fmt.Fprintf(bw, "httptester_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, strconv.FormatFloat(le, 'g', -1, 64), s.buckets[i])
+		}
+		fmt.Fprintf(bw, "httptester_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, s.count)
+		fmt.Fprintf(bw, "httptester_request_duration_seconds_sum{%s} %s\n", labels, strconv.FormatFloat(s.sum, 'g', -1, 64))
+		fmt.Fprintf(bw, "httptester_request_duration_seconds_count{%s} %d\n", labels, s.count)
+	}
+
+	return bw.Flush()
+}
+
+// ServeHTTP serves the metrics so they can be scraped by Prometheus.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.Write(w)
+}
+
+func (k metricsKey) labels() string {
+	return "method=" + metricsLabel(k.method) + ",endpoint=" + metricsLabel(k.endpoint)
+}
+
+var metricsLabelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func metricsLabel(value string) string {
+	return `"` + metricsLabelReplacer.Replace(value) + `"`
+}
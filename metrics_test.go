@@ -0,0 +1,46 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/users/2" {
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	metrics := httptester.NewMetrics(0.5, 0.1)
+	c := httptester.New(t, server.URL, httptester.WithMetrics(metrics))
+
+	c.GET("/users/{id}").Param("id", "1").Do().Status(200)
+	c.GET("/users/{id}").Param("id", "2").Do().Status(404)
+	c.POST("/users?notify=1").Do().Status(200)
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, line := range []string{
+		"# TYPE httptester_requests_total counter",
+		`httptester_requests_total{method="GET",endpoint="/users/{id}",code="200"} 1`,
+		`httptester_requests_total{method="GET",endpoint="/users/{id}",code="404"} 1`,
+		`httptester_requests_total{method="POST",endpoint="/users",code="200"} 1`,
+		"# TYPE httptester_request_duration_seconds histogram",
+		`httptester_request_duration_seconds_bucket{method="GET",endpoint="/users/{id}",le="0.1"} 2`,
+		`httptester_request_duration_seconds_bucket{method="GET",endpoint="/users/{id}",le="0.5"} 2`,
+		`httptester_request_duration_seconds_bucket{method="GET",endpoint="/users/{id}",le="+Inf"} 2`,
+		`httptester_request_duration_seconds_count{method="GET",endpoint="/users/{id}"} 2`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatalf("metrics do not contain %s:\n%s", line, body)
+		}
+	}
+}
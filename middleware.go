@@ -0,0 +1,30 @@
+package httptester
+
+import (
+	"net/http"
+)
+
+// RoundTripFunc sends a request, like http.RoundTripper.
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps the sending of each attempt of a request, e.g. to log,
+// authenticate or measure requests. It can change the request, the
+// response or the error, or return a response without calling next.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// Use appends middleware to the chain of the builder. Middleware run in the
+// order they were added, the first being the outermost. BeforeRequest and
+// AfterRequest add middleware too.
+func (b *ReqBuilder) Use(mw ...Middleware) *ReqBuilder {
+	// Builders cloned from b must not share the appended middleware.
+	b.middleware = append(b.middleware[:len(b.middleware):len(b.middleware)], mw...)
+	return b
+}
+
+// roundTrip sends req through the middleware chain ending with send.
+func (b *ReqBuilder) roundTrip(req *http.Request, send RoundTripFunc) (*http.Response, error) {
+	for i := len(b.middleware) - 1; i >= 0; i-- {
+		send = b.middleware[i](send)
+	}
+	return send(req)
+}
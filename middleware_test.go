@@ -0,0 +1,74 @@
+package httptester_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Join(r.Header.Values("X-Trail"), ",")))
+	}))
+	defer server.Close()
+
+	var log []string
+	trail := func(name string) httptester.Middleware {
+		return func(next httptester.RoundTripFunc) httptester.RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				req.Header.Add("X-Trail", name)
+				log = append(log, "> "+name)
+				res, err := next(req)
+				log = append(log, "< "+name)
+				return res, err
+			}
+		}
+	}
+
+	c := httptester.New(t, server.URL,
+		httptester.WithMiddleware(trail("a")),
+		httptester.WithBeforeRequest(func(req *http.Request) {
+			req.Header.Add("X-Trail", "before1")
+		}),
+		httptester.WithAfterRequest(func(req *http.Request, res *http.Response, err error) {
+			log = append(log, "after1")
+		}),
+	)
+
+	c.GET("/").
+		BeforeRequest(func(req *http.Request) {
+			req.Header.Add("X-Trail", "before2")
+		}).
+		AfterRequest(func(req *http.Request, res *http.Response, err error) {
+			log = append(log, "after2")
+		}).
+		Use(trail("b")).
+		Do().
+		Eq("a,before1,before2,b")
+
+	expected := "> a,> b,< b,after2,after1,< a"
+	if got := strings.Join(log, ","); got != expected {
+		t.Errorf("expected %s got %s", expected, got)
+	}
+
+	base := c.GET("/").Use(trail("base"))
+	first := base.Clone().Use(trail("first"))
+	second := base.Clone().Use(trail("second"))
+	first.Do().Eq("a,before1,base,first")
+	second.Do().Eq("a,before1,base,second")
+
+	stub := func(next httptester.RoundTripFunc) httptester.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusTeapot,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader("stubbed")),
+			}, nil
+		}
+	}
+	c.GET("/").Use(stub).Do().Status(http.StatusTeapot).Eq("stubbed")
+}
@@ -0,0 +1,278 @@
+package httptester
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// MockServer is an httptest.Server that answers requests matching declared
+// expectations with canned responses. Requests that match no expectation
+// fail the test and get a 404 response. Verify fails the test for
+// expectations that were not met.
+type MockServer struct {
+	*httptest.Server
+
+	t            testing.TB
+	mu           sync.Mutex
+	expectations []*Expectation
+}
+
+// NewMockServer starts a MockServer that is closed when the test ends.
+func NewMockServer(t testing.TB) *MockServer {
+	m := &MockServer{
+		t: t,
+	}
+
+	m.Server = httptest.NewServer(http.HandlerFunc(m.serveHTTP))
+	t.Cleanup(m.Close)
+
+	return m
+}
+
+// Expectation matches requests by method and path and replies with a canned
+// response. The path may contain {name} placeholders matching a single path
+// segment. An expectation must be met exactly once unless configured with
+// Times or AnyTimes.
+type Expectation struct {
+	t        testing.TB
+	method   string
+	path     string
+	matchers []requestMatcher
+	times    int
+	count    int
+
+	status  int
+	headers http.Header
+	body    []byte
+	delay   time.Duration
+	// replyErr is the error of building the reply, the request is answered
+	// with 500 Internal Server Error.
+	replyErr error
+}
+
+type requestMatcher struct {
+	desc  string
+	match func(r *http.Request, body []byte) bool
+}
+
+func (m *MockServer) Expect(method string, path string) *Expectation {
+	e := &Expectation{
+		t:       m.t,
+		method:  method,
+		path:    path,
+		times:   1,
+		status:  http.StatusOK,
+		headers: http.Header{},
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expectations = append(m.expectations, e)
+
+	return e
+}
+
+func (e *Expectation) match(desc string, f func(r *http.Request, body []byte) bool) *Expectation {
+	e.matchers = append(e.matchers, requestMatcher{desc: desc, match: f})
+	return e
+}
+
+func (e *Expectation) Header(key string, value string) *Expectation {
+	return e.match(fmt.Sprintf("header %s: %s", key, value), func(r *http.Request, body []byte) bool {
+		return r.Header.Get(key) == value
+	})
+}
+
+func (e *Expectation) Query(key string, value string) *Expectation {
+	return e.match(fmt.Sprintf("query %s=%s", key, value), func(r *http.Request, body []byte) bool {
+		return r.URL.Query().Get(key) == value
+	})
+}
+
+func (e *Expectation) Body(body string) *Expectation {
+	return e.match(fmt.Sprintf("body %q", body), func(r *http.Request, b []byte) bool {
+		return string(b) == body
+	})
+}
+
+func (e *Expectation) BodyContains(substr string) *Expectation {
+	return e.match(fmt.Sprintf("body containing %q", substr), func(r *http.Request, b []byte) bool {
+		return bytes.Contains(b, []byte(substr))
+	})
+}
+
+// JSONBody matches requests whose body is JSON equal to v.
+func (e *Expectation) JSONBody(v interface{}) *Expectation {
+	expected, err := normalizeJSON(v)
+
+	return e.match(fmt.Sprintf("JSON body %s", jsonString(expected)), func(r *http.Request, b []byte) bool {
+		var actual interface{}
+		if err != nil || json.Unmarshal(b, &actual) != nil {
+			return false
+		}
+		return reflect.DeepEqual(actual, expected)
+	})
+}
+
+// Match adds a custom matcher described by desc.
+func (e *Expectation) Match(desc string, f func(r *http.Request, body []byte) bool) *Expectation {
+	return e.match(desc, f)
+}
+
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// AnyTimes allows the expectation to be met any number of times, including
+// never.
+func (e *Expectation) AnyTimes() *Expectation {
+	e.times = -1
+	return e
+}
+
+func (e *Expectation) Reply(status int, body string) *Expectation {
+	e.status = status
+	e.body = []byte(body)
+	return e
+}
+
+func (e *Expectation) ReplyJSON(status int, v interface{}) *Expectation {
+	data, err := json.Marshal(v)
+	if err != nil {
+		e.t.Helper()
+		e.t.Errorf("mock server: cannot marshal reply of %s: %s", e, err)
+		e.replyErr = err
+		return e
+	}
+
+	e.status = status
+	e.body = data
+	e.headers.Set("Content-Type", "application/json")
+	return e
+}
+
+func (e *Expectation) ReplyHeader(key string, value string) *Expectation {
+	e.headers.Set(key, value)
+	return e
+}
+
+// Delay delays the response, e.g. to test client timeouts.
+func (e *Expectation) Delay(d time.Duration) *Expectation {
+	e.delay = d
+	return e
+}
+
+func (e *Expectation) String() string {
+	s := e.method + " " + e.path
+	for _, m := range e.matchers {
+		s += ", " + m.desc
+	}
+	return s
+}
+
+func (e *Expectation) matches(r *http.Request, body []byte) bool {
+	if r.Method != e.method || !pathMatches(e.path, r.URL.Path) {
+		return false
+	}
+
+	for _, m := range e.matchers {
+		if !m.match(r, body) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// pathMatches reports whether path matches the template where {name}
+// placeholders match a single segment.
+func pathMatches(template string, path string) bool {
+	ts := strings.Split(template, "/")
+	ps := strings.Split(path, "/")
+
+	if len(ts) != len(ps) {
+		return false
+	}
+
+	for i, t := range ts {
+		if strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}") && ps[i] != "" {
+			continue
+		}
+		if t != ps[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m *MockServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		m.t.Errorf("mock server: %s %s: %s", r.Method, r.URL.RequestURI(), err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	m.mu.Lock()
+	var matched *Expectation
+	for _, e := range m.expectations {
+		if (e.times < 0 || e.count < e.times) && e.matches(r, body) {
+			matched = e
+			matched.count++
+			break
+		}
+	}
+	m.mu.Unlock()
+
+	if matched == nil {
+		m.t.Errorf("mock server: unexpected request %s %s: %s", r.Method, r.URL.RequestURI(), bodyExcerpt(body))
+		http.Error(w, "no expectation matches the request", http.StatusNotFound)
+		return
+	}
+
+	if matched.delay > 0 {
+		select {
+		case <-time.After(matched.delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if matched.replyErr != nil {
+		http.Error(w, matched.replyErr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for k, vs := range matched.headers {
+		w.Header()[k] = vs
+	}
+	w.WriteHeader(matched.status)
+	w.Write(matched.body)
+}
+
+// Verify fails the test for every expectation that was not met the
+// expected number of times.
+func (m *MockServer) Verify() {
+	m.t.Helper()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.expectations {
+		if e.times >= 0 && e.count != e.times {
+			m.t.Errorf("mock server: expected %d requests matching %s got %d", e.times, e, e.count)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package httptester_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bancek/httptester"
+)
+
+// recordingT collects errors reported through Errorf instead of failing the
+// test.
+type recordingT struct {
+	testing.TB
+	mu   sync.Mutex
+	errs []string
+}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.errs = append(t.errs, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) Errors() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]string(nil), t.errs...)
+}
+
+func TestMockServer(t *testing.T) {
+	m := httptester.NewMockServer(t)
+
+	m.Expect("POST", "/users").
+		Header("Content-Type", "application/json").
+		JSONBody(map[string]interface{}{"name": "Ann"}).
+		ReplyJSON(201, map[string]interface{}{"id": 1}).
+		ReplyHeader("Location", "/users/1")
+	m.Expect("GET", "/users/{id}").Query("expand", "roles").Times(2).Reply(200, "user")
+	m.Expect("GET", "/health").AnyTimes()
+
+	c := httptester.New(t, m.URL)
+
+	c.POST("/users").JSON(map[string]string{"name": "Ann"}).Do().
+		Status(201).JSONPath("$.id", 1).HeaderEq("Location", "/users/1")
+	c.GET("/users/1").Q("expand", "roles").Do().Status(200).Eq("user")
+	c.GET("/users/2").Q("expand", "roles").Do().Status(200).Eq("user")
+
+	m.Verify()
+}
+
+func TestMockServerFailures(t *testing.T) {
+	rt := &recordingT{TB: t}
+	m := httptester.NewMockServer(rt)
+
+	m.Expect("GET", "/users/{id}").Reply(200, "user")
+	m.Expect("DELETE", "/users/{id}")
+	m.Expect("GET", "/slow").Delay(200 * time.Millisecond)
+	m.Expect("GET", "/invalid").ReplyJSON(200, map[string]interface{}{"ch": make(chan int)})
+
+	c := httptester.New(t, m.URL)
+
+	c.GET("/users/1").Do().Status(200)
+	c.GET("/users/2").Do().Status(404).Contains("no expectation matches the request")
+	c.GET("/slow").Timeout(20 * time.Millisecond).Soft(httptester.NewSoftAssertions()).Do()
+	c.GET("/invalid").Do().Status(500).Contains("json: unsupported type: chan int")
+
+	m.Verify()
+
+	errs := rt.Errors()
+	if len(errs) != 3 ||
+		errs[0] != "mock server: cannot marshal reply of GET /invalid: json: unsupported type: chan int" ||
+		!strings.Contains(errs[1], "unexpected request GET /users/2") ||
+		errs[2] != "mock server: expected 1 requests matching DELETE /users/{id} got 0" {
+		t.Fatalf("unexpected errors %q", errs)
+	}
+}
@@ -0,0 +1,386 @@
+package httptester
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"mime"
+)
+
+// msgpackContentTypes are the media types MessagePack bodies are sent with.
+var msgpackContentTypes = []string{
+	"application/msgpack",
+	"application/x-msgpack",
+	"application/vnd.msgpack",
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	return marshalMsgPack(v)
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	return unmarshalMsgPack(data, v)
+}
+
+func init() {
+	for _, contentType := range msgpackContentTypes {
+		RegisterCodec(contentType, msgpackCodec{})
+	}
+}
+
+// marshalMsgPack encodes v as MessagePack. Values are converted like
+// encoding/json does, so struct fields are named by their json tags and
+// []byte is encoded as a base64 string.
+func marshalMsgPack(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := encodeMsgPack(buf, generic); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalMsgPack decodes MessagePack into v, see marshalMsgPack. Binary
+// values decode into []byte fields.
+func unmarshalMsgPack(data []byte, v interface{}) error {
+	d := &msgpackDecoder{data: data}
+	generic, err := d.decode()
+	if err != nil {
+		return fmt.Errorf("invalid MessagePack: %w", err)
+	}
+	if d.pos != len(data) {
+		return fmt.Errorf("invalid MessagePack: %d trailing bytes", len(data)-d.pos)
+	}
+
+	jsonBytes, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonBytes, v)
+}
+
+func encodeMsgPack(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			encodeMsgPackInt(buf, i)
+			return nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return err
+		}
+		buf.WriteByte(0xcb)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(f))
+
+	case string:
+		n := len(v)
+		switch {
+		case n < 32:
+			buf.WriteByte(0xa0 | byte(n))
+		case n <= math.MaxUint8:
+			buf.WriteByte(0xd9)
+			buf.WriteByte(byte(n))
+		case n <= math.MaxUint16:
+			buf.WriteByte(0xda)
+			binary.Write(buf, binary.BigEndian, uint16(n))
+		default:
+			buf.WriteByte(0xdb)
+			binary.Write(buf, binary.BigEndian, uint32(n))
+		}
+		buf.WriteString(v)
+
+	case []interface{}:
+		encodeMsgPackLen(buf, len(v), 0x90, 0xdc, 0xdd)
+		for _, elem := range v {
+			if err := encodeMsgPack(buf, elem); err != nil {
+				return err
+			}
+		}
+
+	case map[string]interface{}:
+		encodeMsgPackLen(buf, len(v), 0x80, 0xde, 0xdf)
+		for _, key := range sortedMapKeys(v) {
+			encodeMsgPack(buf, key)
+			if err := encodeMsgPack(buf, v[key]); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("cannot encode %T as MessagePack", v)
+	}
+
+	return nil
+}
+
+func encodeMsgPackInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i <= 127:
+		buf.WriteByte(byte(i))
+	case i >= -32 && i < 0:
+		buf.WriteByte(byte(int8(i)))
+	case i >= 0 && i <= math.MaxUint8:
+		buf.WriteByte(0xcc)
+		buf.WriteByte(byte(i))
+	case i >= 0 && i <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		binary.Write(buf, binary.BigEndian, uint16(i))
+	case i >= 0 && i <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		binary.Write(buf, binary.BigEndian, uint32(i))
+	case i >= 0:
+		buf.WriteByte(0xcf)
+		binary.Write(buf, binary.BigEndian, uint64(i))
+	case i >= math.MinInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt16:
+		buf.WriteByte(0xd1)
+		binary.Write(buf, binary.BigEndian, int16(i))
+	case i >= math.MinInt32:
+		buf.WriteByte(0xd2)
+		binary.Write(buf, binary.BigEndian, int32(i))
+	default:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, i)
+	}
+}
+
+func encodeMsgPackLen(buf *bytes.Buffer, n int, fix byte, len16 byte, len32 byte) {
+	switch {
+	case n < 16:
+		buf.WriteByte(fix | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(len16)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(len32)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+var errMsgPackShort = errors.New("unexpected end of data")
+
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *msgpackDecoder) read(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, errMsgPackShort
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *msgpackDecoder) uint(n int) (uint64, error) {
+	b, err := d.read(n)
+	if err != nil {
+		return 0, err
+	}
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u, nil
+}
+
+func (d *msgpackDecoder) decode() (interface{}, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return d.decodeMap(int(c & 0x0f))
+	case c&0xf0 == 0x90:
+		return d.decodeArray(int(c & 0x0f))
+	case c&0xe0 == 0xa0:
+		return d.decodeString(int(c & 0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		return d.read(int(n))
+	case 0xca:
+		u, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 0xcb:
+		u, err := d.uint(8)
+		return math.Float64frombits(u), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.uint(1 << (c - 0xcc))
+		if u > math.MaxInt64 {
+			return u, err
+		}
+		return int64(u), err
+	case 0xd0:
+		u, err := d.uint(1)
+		return int64(int8(u)), err
+	case 0xd1:
+		u, err := d.uint(2)
+		return int64(int16(u)), err
+	case 0xd2:
+		u, err := d.uint(4)
+		return int64(int32(u)), err
+	case 0xd3:
+		u, err := d.uint(8)
+		return int64(u), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(int(n))
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(int(n))
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(int(n))
+	}
+
+	return nil, fmt.Errorf("unsupported type 0x%02x at offset %d", c, d.pos-1)
+}
+
+func (d *msgpackDecoder) decodeString(n int) (interface{}, error) {
+	b, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (d *msgpackDecoder) decodeArray(n int) (interface{}, error) {
+	if n > len(d.data)-d.pos {
+		return nil, errMsgPackShort
+	}
+	arr := make([]interface{}, n)
+	for i := range arr {
+		v, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		arr[i] = v
+	}
+	return arr, nil
+}
+
+func (d *msgpackDecoder) decodeMap(n int) (interface{}, error) {
+	if n > len(d.data)-d.pos {
+		return nil, errMsgPackShort
+	}
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		key, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := key.(string); ok {
+			m[s] = value
+		} else {
+			m[fmt.Sprint(key)] = value
+		}
+	}
+	return m, nil
+}
+
+// MsgPack sets the body to v encoded as MessagePack and the Content-Type to
+// application/msgpack. Struct fields are named by their json tags.
+func (b *ReqBuilder) MsgPack(v interface{}) *ReqBuilder {
+	b.helper()
+
+	b.Header("Content-Type", "application/msgpack")
+	data, err := marshalMsgPack(v)
+	if err != nil {
+		b.onError(err)
+	}
+	return b.Body(bytes.NewReader(data))
+}
+
+func isMsgPackContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range msgpackContentTypes {
+		if mediaType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// MsgPack decodes the MessagePack body into v, see ReqBuilder.MsgPack.
+func (r *Response) MsgPack(v interface{}) interface{} {
+	r.helper()
+
+	if !r.hasBody() {
+		return nil
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if !isMsgPackContentType(contentType) {
+		r.err(fmt.Errorf("Content-Type is not application/msgpack, got %s: %s", contentType, r.bodyExcerpt()))
+	}
+	if err := unmarshalMsgPack(r.Body, v); err != nil {
+		r.err(err)
+		return nil
+	}
+	return v
+}
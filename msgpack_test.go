@@ -0,0 +1,101 @@
+package httptester_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestMsgPack(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/echo":
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+			w.Write(body)
+		case "/fixed":
+			w.Header().Set("Content-Type", "application/x-msgpack")
+			// {"a": 1, "b": [true, nil], "c": -200, "d": bin "hi"}
+			w.Write([]byte{0x84, 0xa1, 'a', 0x01, 0xa1, 'b', 0x92, 0xc3, 0xc0, 0xa1, 'c', 0xd1, 0xff, 0x38, 0xa1, 'd', 0xc4, 0x02, 'h', 'i'})
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{}`))
+		case "/truncated":
+			w.Header().Set("Content-Type", "application/msgpack")
+			w.Write([]byte{0x92, 0x01})
+		}
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	type item struct {
+		Name   string            `json:"name"`
+		Count  int64             `json:"count"`
+		Price  float64           `json:"price"`
+		Tags   []string          `json:"tags"`
+		Labels map[string]string `json:"labels"`
+		Note   *string           `json:"note"`
+		OK     bool              `json:"ok"`
+	}
+	in := item{
+		Name:   strings.Repeat("x", 40),
+		Count:  -1 << 40,
+		Price:  1.5,
+		Tags:   []string{"a", "b"},
+		Labels: map[string]string{"k": "v"},
+		OK:     true,
+	}
+
+	var out item
+	c.POST("/echo").MsgPack(in).Do().
+		HeaderEq("Content-Type", "application/msgpack").
+		MsgPack(&out)
+	if out.Name != in.Name || out.Count != in.Count || out.Price != in.Price || len(out.Tags) != 2 || out.Labels["k"] != "v" || out.Note != nil || !out.OK {
+		t.Fatalf("unexpected item %+v", out)
+	}
+
+	out = item{}
+	c.POST("/echo").Header("Content-Type", "application/msgpack").Encode(in).Do().Decode(&out)
+	if out.Name != in.Name {
+		t.Fatalf("unexpected item %+v", out)
+	}
+
+	var fixed struct {
+		A int           `json:"a"`
+		B []interface{} `json:"b"`
+		C int           `json:"c"`
+		D []byte        `json:"d"`
+	}
+	c.GET("/fixed").Do().MsgPack(&fixed)
+	if fixed.A != 1 || len(fixed.B) != 2 || fixed.B[0] != true || fixed.B[1] != nil || fixed.C != -200 || !bytes.Equal(fixed.D, []byte("hi")) {
+		t.Fatalf("unexpected value %+v", fixed)
+	}
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	var v interface{}
+	b.GET("/json").Do().MsgPack(&v)
+	b.GET("/truncated").Do().MsgPack(&v)
+
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		"Content-Type is not application/msgpack, got application/json: {}",
+		"invalid MessagePack: 1 trailing bytes",
+		"invalid MessagePack: unexpected end of data",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package httptester
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+)
+
+type multipartPart struct {
+	header textproto.MIMEHeader
+	reader io.Reader
+}
+
+// MultipartBuilder builds a multipart/form-data body. Parts are written in
+// the order they are added. Call Done to set the body on the request.
+type MultipartBuilder struct {
+	req   *ReqBuilder
+	parts []multipartPart
+}
+
+func (b *ReqBuilder) Multipart() *MultipartBuilder {
+	return &MultipartBuilder{
+		req: b,
+	}
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func (m *MultipartBuilder) Field(name string, value string) *MultipartBuilder {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(name)))
+	return m.Part(header, strings.NewReader(value))
+}
+
+func (m *MultipartBuilder) File(fieldName string, fileName string, reader io.Reader) *MultipartBuilder {
+	return m.FileWithType(fieldName, fileName, "application/octet-stream", reader)
+}
+
+func (m *MultipartBuilder) FileWithType(fieldName string, fileName string, contentType string, reader io.Reader) *MultipartBuilder {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(fieldName), quoteEscaper.Replace(fileName)))
+	header.Set("Content-Type", contentType)
+	return m.Part(header, reader)
+}
+
+// Part adds a raw part with arbitrary headers.
+func (m *MultipartBuilder) Part(header textproto.MIMEHeader, reader io.Reader) *MultipartBuilder {
+	m.parts = append(m.parts, multipartPart{
+		header: header,
+		reader: reader,
+	})
+	return m
+}
+
+func (m *MultipartBuilder) Done() *ReqBuilder {
+	b := m.req
+	b.helper()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	for _, p := range m.parts {
+		part, err := writer.CreatePart(p.header)
+		if err != nil {
+			b.onError(err)
+			return b
+		}
+		if _, err := io.Copy(part, p.reader); err != nil {
+			b.onError(err)
+			return b
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		b.onError(err)
+		return b
+	}
+
+	b.Header("Content-Type", writer.FormDataContentType())
+
+	return b.Body(body)
+}
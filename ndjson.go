@@ -0,0 +1,149 @@
+package httptester
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"reflect"
+)
+
+func isNDJSON(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines":
+		return true
+	}
+	return false
+}
+
+// ndjsonLines returns the non-empty lines of a newline delimited JSON body,
+// see NDJSONLines.
+func (r *Response) ndjsonLines() [][]byte {
+	r.helper()
+
+	if !r.hasBody() {
+		return nil
+	}
+
+	if contentType := r.Header.Get("Content-Type"); !isNDJSON(contentType) {
+		r.err(fmt.Errorf("Content-Type is not application/x-ndjson, got %s: %s", contentType, r.bodyExcerpt()))
+	}
+
+	lines := [][]byte{}
+	for _, line := range bytes.Split(r.Body, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// NDJSON decodes each line of a newline delimited JSON body into a new
+// element of the slice v points to.
+func (r *Response) NDJSON(v interface{}) interface{} {
+	r.helper()
+
+	slice := reflect.ValueOf(v)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		r.err(fmt.Errorf("NDJSON requires a pointer to a slice got %T", v))
+		return nil
+	}
+	slice = slice.Elem()
+
+	for i, line := range r.ndjsonLines() {
+		item := reflect.New(slice.Type().Elem())
+		if err := json.Unmarshal(line, item.Interface()); err != nil {
+			r.err(fmt.Errorf("NDJSON line %d: %w: %s", i, err, bodyExcerpt(line)))
+			return nil
+		}
+		slice.Set(reflect.Append(slice, item.Elem()))
+	}
+
+	return v
+}
+
+func (r *Response) NDJSONLen(n int) *Response {
+	r.helper()
+	defer r.track("NDJSONLen", n)()
+
+	actual := 0
+	for range r.ndjsonLines() {
+		actual++
+	}
+
+	if actual != n {
+		r.err(fmt.Errorf("expected %d NDJSON lines got %d", n, actual))
+	}
+
+	return r
+}
+
+// ndjsonPath checks the value at path of a single line.
+func (r *Response) ndjsonPath(i int, line []byte, path string, expected interface{}) bool {
+	r.helper()
+
+	var v interface{}
+	if err := json.Unmarshal(line, &v); err != nil {
+		r.err(fmt.Errorf("NDJSON line %d: %w: %s", i, err, bodyExcerpt(line)))
+		return false
+	}
+
+	value, err := evalJSONPath(v, path)
+	if err != nil {
+		r.err(fmt.Errorf("NDJSON line %d: %w", i, err))
+		return false
+	}
+
+	if !reflect.DeepEqual(value, expected) {
+		r.err(fmt.Errorf("NDJSON line %d: JSONPath %s: expected %s got %s", i, path, jsonString(expected), jsonString(value)))
+		return false
+	}
+
+	return true
+}
+
+// NDJSONLine checks the value at a JSONPath of line i.
+func (r *Response) NDJSONLine(i int, path string, expected interface{}) *Response {
+	r.helper()
+	defer r.track("NDJSONLine", i, path, expected)()
+
+	normalized, err := normalizeJSON(expected)
+	if err != nil {
+		r.err(err)
+		return r
+	}
+
+	for j, line := range r.ndjsonLines() {
+		if j == i {
+			r.ndjsonPath(i, line, path, normalized)
+			return r
+		}
+	}
+
+	r.err(fmt.Errorf("NDJSON has no line %d", i))
+	return r
+}
+
+// NDJSONEach checks the value at a JSONPath of every line, e.g. that every
+// exported record has the requested status. Only the first mismatch is
+// reported.
+func (r *Response) NDJSONEach(path string, expected interface{}) *Response {
+	r.helper()
+	defer r.track("NDJSONEach", path, expected)()
+
+	normalized, err := normalizeJSON(expected)
+	if err != nil {
+		r.err(err)
+		return r
+	}
+
+	for i, line := range r.ndjsonLines() {
+		if !r.ndjsonPath(i, line, path, normalized) {
+			return r
+		}
+	}
+
+	return r
+}
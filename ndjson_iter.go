@@ -0,0 +1,23 @@
+//go:build go1.23
+
+package httptester
+
+import (
+	"iter"
+)
+
+// NDJSONLines iterates over the non-empty lines of a newline delimited JSON
+// body with their zero based index. It requires Go 1.23 or newer.
+func (r *Response) NDJSONLines() iter.Seq2[int, []byte] {
+	r.helper()
+
+	lines := r.ndjsonLines()
+
+	return func(yield func(int, []byte) bool) {
+		for i, line := range lines {
+			if !yield(i, line) {
+				return
+			}
+		}
+	}
+}
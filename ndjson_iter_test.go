@@ -0,0 +1,32 @@
+//go:build go1.23
+
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestResponseNDJSONLines(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Write([]byte("{\"id\":1}\n\n{\"id\":2}\n{\"id\":3}\n"))
+	}))
+	defer server.Close()
+
+	res := httptester.New(t, server.URL).GET("/export").Do()
+
+	ids := []string{}
+	for i, line := range res.NDJSONLines() {
+		if i == 2 {
+			break
+		}
+		ids = append(ids, string(line))
+	}
+	if len(ids) != 2 || ids[1] != `{"id":2}` {
+		t.Fatalf("unexpected lines %q", ids)
+	}
+}
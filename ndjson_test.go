@@ -0,0 +1,62 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestResponseNDJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		if r.URL.Path == "/invalid" {
+			w.Write([]byte("{\"id\":1}\nnot json\n"))
+			return
+		}
+		w.Write([]byte("{\"id\":1,\"status\":\"active\"}\n\n{\"id\":2,\"status\":\"active\"}\n{\"id\":3,\"status\":\"deleted\"}\n"))
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	type item struct {
+		ID     int    `json:"id"`
+		Status string `json:"status"`
+	}
+
+	res := c.GET("/export").Do().
+		Status(200).
+		NDJSONLen(3).
+		NDJSONLine(2, "$.status", "deleted")
+
+	var items []item
+	res.NDJSON(&items)
+	if len(items) != 3 || items[1].ID != 2 || items[2].Status != "deleted" {
+		t.Fatalf("unexpected items %+v", items)
+	}
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.GET("/export").Do().NDJSONEach("$.status", "active").NDJSONLine(5, "$.id", 1).NDJSONLen(2)
+	b.GET("/invalid").Do().NDJSON(&items)
+
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		`NDJSON line 2: JSONPath $.status: expected "active" got "deleted"`,
+		"NDJSON has no line 5",
+		"expected 2 NDJSON lines got 3",
+		"NDJSON line 1: invalid character",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
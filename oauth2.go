@@ -0,0 +1,242 @@
+package httptester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OAuth2 obtains access tokens from a token endpoint, caches them until
+// they expire and adds them as Bearer tokens to requests of builders it is
+// attached to (see ReqBuilder.OAuth2). When a request is rejected with 401
+// the token is refreshed (using the refresh token if one was issued) and
+// the request is retried once.
+type OAuth2 struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	username     string
+	password     string
+	grantType    string
+
+	mu    sync.Mutex
+	token *OAuth2Token
+}
+
+type OAuth2Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresIn    int       `json:"expires_in"`
+	Expiry       time.Time `json:"-"`
+}
+
+// valid reports whether the token can be used, leaving a margin for clock
+// skew and request latency.
+func (t *OAuth2Token) valid() bool {
+	return t != nil && t.AccessToken != "" && (t.Expiry.IsZero() || time.Now().Add(10*time.Second).Before(t.Expiry))
+}
+
+// OAuth2ClientCredentials uses the client credentials grant.
+func OAuth2ClientCredentials(tokenURL string, clientID string, clientSecret string, scopes ...string) *OAuth2 {
+	return &OAuth2{
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		scopes:       scopes,
+		grantType:    "client_credentials",
+	}
+}
+
+// OAuth2Password uses the resource owner password credentials grant.
+func OAuth2Password(tokenURL string, clientID string, clientSecret string, username string, password string, scopes ...string) *OAuth2 {
+	return &OAuth2{
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		scopes:       scopes,
+		username:     username,
+		password:     password,
+		grantType:    "password",
+	}
+}
+
+// Token returns the cached access token or obtains a new one.
+func (o *OAuth2) Token(ctx context.Context) (*OAuth2Token, error) {
+	return o.cachedToken(ctx, http.DefaultTransport)
+}
+
+func (o *OAuth2) cachedToken(ctx context.Context, transport http.RoundTripper) (*OAuth2Token, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.token.valid() {
+		return o.token, nil
+	}
+
+	token, err := o.fetch(ctx, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	o.token = token
+	return token, nil
+}
+
+// refresh replaces the token if it is still the rejected one.
+func (o *OAuth2) refresh(ctx context.Context, transport http.RoundTripper, rejected *OAuth2Token) (*OAuth2Token, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.token != rejected && o.token.valid() {
+		return o.token, nil
+	}
+
+	var token *OAuth2Token
+	var err error
+	if rejected != nil && rejected.RefreshToken != "" {
+		token, err = o.request(ctx, transport, url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {rejected.RefreshToken},
+		})
+	}
+	if token == nil || err != nil {
+		token, err = o.fetch(ctx, transport)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	o.token = token
+	return token, nil
+}
+
+// Invalidate forgets the cached token.
+func (o *OAuth2) Invalidate() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.token = nil
+}
+
+func (o *OAuth2) fetch(ctx context.Context, transport http.RoundTripper) (*OAuth2Token, error) {
+	form := url.Values{
+		"grant_type": {o.grantType},
+	}
+	if o.grantType == "password" {
+		form.Set("username", o.username)
+		form.Set("password", o.password)
+	}
+
+	return o.request(ctx, transport, form)
+}
+
+func (o *OAuth2) request(ctx context.Context, transport http.RoundTripper, form url.Values) (*OAuth2Token, error) {
+	if len(o.scopes) > 0 {
+		form.Set("scope", strings.Join(o.scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", o.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(o.clientID), url.QueryEscape(o.clientSecret))
+
+	client := &http.Client{Transport: transport}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: token request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: token request failed: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oauth2: token request failed: status %d: %s", res.StatusCode, bodyExcerpt(body))
+	}
+
+	token := &OAuth2Token{}
+	if err := json.Unmarshal(body, token); err != nil {
+		return nil, fmt.Errorf("oauth2: invalid token response: %w: %s", err, bodyExcerpt(body))
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("oauth2: token response has no access_token: %s", bodyExcerpt(body))
+	}
+	if token.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+
+	return token, nil
+}
+
+// Transport returns an http.RoundTripper that authorizes requests sent
+// through next. Tokens are also requested through next.
+func (o *OAuth2) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &oauth2Transport{
+		oauth2: o,
+		next:   next,
+	}
+}
+
+type oauth2Transport struct {
+	oauth2 *OAuth2
+	next   http.RoundTripper
+}
+
+func (t *oauth2Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.oauth2.cachedToken(req.Context(), t.next)
+	if err != nil {
+		return nil, err
+	}
+
+	retry := req.Clone(req.Context())
+	canRetry := true
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			canRetry = false
+		} else if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized || !canRetry {
+		return res, err
+	}
+
+	token, err = t.oauth2.refresh(req.Context(), t.next, token)
+	if err != nil {
+		return res, nil
+	}
+
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+
+	retry.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	return t.next.RoundTrip(retry)
+}
+
+func (b *ReqBuilder) OAuth2(oauth2 *OAuth2) *ReqBuilder {
+	b.oauth2 = oauth2
+	return b
+}
@@ -0,0 +1,77 @@
+package httptester_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestOAuth2(t *testing.T) {
+	mu := sync.Mutex{}
+	grants := []string{}
+	valid := ""
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.URL.Path == "/token" {
+			r.ParseForm()
+			clientID, clientSecret, _ := r.BasicAuth()
+			if clientID != "app" || clientSecret != "s3cret" {
+				w.WriteHeader(401)
+				return
+			}
+			grant := r.PostForm.Get("grant_type")
+			if grant == "password" && (r.PostForm.Get("username") != "ann" || r.PostForm.Get("password") != "pass") {
+				w.WriteHeader(400)
+				return
+			}
+			grants = append(grants, grant+" "+r.PostForm.Get("scope"))
+			valid = fmt.Sprintf("token%d", len(grants))
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"access_token": %q, "token_type": "Bearer", "expires_in": 3600, "refresh_token": "refresh"}`, valid)
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer "+valid {
+			w.WriteHeader(401)
+			return
+		}
+		w.Write([]byte(valid))
+	}))
+	defer server.Close()
+
+	oauth2 := httptester.OAuth2ClientCredentials(server.URL+"/token", "app", "s3cret", "read", "write")
+	c := httptester.New(t, server.URL, httptester.WithOAuth2(oauth2))
+
+	c.GET("/me").Do().Status(200).Eq("token1")
+	c.POST("/me").JSON(map[string]string{}).Do().Status(200).Eq("token1")
+
+	mu.Lock()
+	valid = "revoked"
+	mu.Unlock()
+
+	c.GET("/me").Do().Status(200).Eq("token2")
+
+	expected := []string{"client_credentials read write", "refresh_token read write"}
+	if fmt.Sprint(grants) != fmt.Sprint(expected) {
+		t.Fatalf("expected grants %v got %v", expected, grants)
+	}
+
+	password := httptester.OAuth2Password(server.URL+"/token", "app", "s3cret", "ann", "pass")
+	c.GET("/me").OAuth2(password).Do().Status(200).Eq("token3")
+
+	var errs []error
+	failing := httptester.OAuth2Password(server.URL+"/token", "app", "s3cret", "ann", "wrong")
+	httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	})).GET("/me").OAuth2(failing).Do()
+	if len(errs) != 1 {
+		t.Fatalf("expected token error got %v", errs)
+	}
+}
@@ -0,0 +1,261 @@
+package httptester
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// OpenAPI is a parsed OpenAPI 3 document (JSON or YAML).
+type OpenAPI struct {
+	doc map[string]interface{}
+}
+
+func ParseOpenAPI(data []byte) (*OpenAPI, error) {
+	doc, err := parseJSONOrYAML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid OpenAPI document: expected an object")
+	}
+
+	if _, ok := m["openapi"].(string); !ok {
+		return nil, fmt.Errorf("invalid OpenAPI document: missing openapi version")
+	}
+
+	return &OpenAPI{
+		doc: m,
+	}, nil
+}
+
+func LoadOpenAPI(path string) (*OpenAPI, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := ParseOpenAPI(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return spec, nil
+}
+
+type openAPIOperation struct {
+	method    string
+	path      string
+	operation map[string]interface{}
+}
+
+func (o *OpenAPI) operations() []openAPIOperation {
+	paths, _ := o.doc["paths"].(map[string]interface{})
+
+	keys := make([]string, 0, len(paths))
+	for path := range paths {
+		keys = append(keys, path)
+	}
+	sort.Strings(keys)
+
+	operations := []openAPIOperation{}
+	for _, path := range keys {
+		item, _ := o.resolve(paths[path]).(map[string]interface{})
+		for _, method := range openAPIMethods {
+			if operation, ok := item[method].(map[string]interface{}); ok {
+				operations = append(operations, openAPIOperation{
+					method:    strings.ToUpper(method),
+					path:      path,
+					operation: operation,
+				})
+			}
+		}
+	}
+	return operations
+}
+
+func (o *OpenAPI) operation(operationID string) (openAPIOperation, bool) {
+	for _, op := range o.operations() {
+		if id, _ := op.operation["operationId"].(string); id == operationID {
+			return op, true
+		}
+	}
+	return openAPIOperation{}, false
+}
+
+// resolve follows a $ref (if any) to the referenced object.
+func (o *OpenAPI) resolve(v interface{}) interface{} {
+	for i := 0; i < 32; i++ {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return v
+		}
+		resolved, err := (&schemaValidator{root: o.doc}).resolveRef(ref)
+		if err != nil {
+			return v
+		}
+		v = resolved
+	}
+	return v
+}
+
+func (o *OpenAPI) validateResponse(operationID string, r *Response) []string {
+	op, ok := o.operation(operationID)
+	if !ok {
+		return []string{fmt.Sprintf("operation %s not found in OpenAPI document", operationID)}
+	}
+
+	responses, _ := op.operation["responses"].(map[string]interface{})
+
+	status := strconv.Itoa(r.StatusCode)
+	spec, ok := responses[status]
+	if !ok {
+		spec, ok = responses[status[:1]+"XX"]
+	}
+	if !ok {
+		spec, ok = responses["default"]
+	}
+	if !ok {
+		return []string{fmt.Sprintf("status %d is not documented for operation %s", r.StatusCode, operationID)}
+	}
+
+	response, _ := o.resolve(spec).(map[string]interface{})
+
+	errs := []string{}
+
+	headers, _ := response["headers"].(map[string]interface{})
+	for name, h := range headers {
+		header, _ := o.resolve(h).(map[string]interface{})
+		values := r.Header.Values(name)
+
+		if len(values) == 0 {
+			if required, _ := header["required"].(bool); required {
+				errs = append(errs, fmt.Sprintf("missing required header %s", name))
+			}
+			continue
+		}
+
+		if schema, ok := header["schema"]; ok {
+			for _, err := range validateSchema(o.doc, schema, headerValue(o.resolve(schema), values[0])) {
+				errs = append(errs, "header "+name+" "+err)
+			}
+		}
+	}
+
+	content, _ := response["content"].(map[string]interface{})
+	if len(content) == 0 {
+		return errs
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return append(errs, fmt.Sprintf("invalid Content-Type %s", contentType))
+	}
+
+	media, ok := content[mediaType]
+	if !ok {
+		media, ok = content[strings.SplitN(mediaType, "/", 2)[0]+"/*"]
+	}
+	if !ok {
+		media, ok = content["*/*"]
+	}
+	if !ok {
+		documented := make([]string, 0, len(content))
+		for k := range content {
+			documented = append(documented, k)
+		}
+		sort.Strings(documented)
+		return append(errs, fmt.Sprintf("Content-Type %s is not documented, expected one of %v", mediaType, documented))
+	}
+
+	mediaObject, ok := media.(map[string]interface{})
+	if !ok {
+		return append(errs, fmt.Sprintf("invalid OpenAPI document: media type %s of operation %s is not an object", mediaType, operationID))
+	}
+
+	schema, ok := mediaObject["schema"]
+	if !ok || !isJSONMediaType(mediaType) {
+		return errs
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(r.Body, &body); err != nil {
+		return append(errs, fmt.Sprintf("invalid JSON body: %s", err))
+	}
+
+	return append(errs, validateSchema(o.doc, schema, body)...)
+}
+
+// headerValue converts a header string into the JSON type the header schema
+// expects so it can be validated.
+func headerValue(schema interface{}, value string) interface{} {
+	s, _ := schema.(map[string]interface{})
+	switch s["type"] {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// parseJSONOrYAML decodes data into the same shape json.Unmarshal produces
+// for interface{}, accepting YAML as well.
+func parseJSONOrYAML(data []byte) (interface{}, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err == nil {
+		return v, nil
+	}
+
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	return normalizeJSON(yamlToJSON(v))
+}
+
+func yamlToJSON(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(vv))
+		for k, x := range vv {
+			m[k] = yamlToJSON(x)
+		}
+		return m
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(vv))
+		for k, x := range vv {
+			m[fmt.Sprint(k)] = yamlToJSON(x)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(vv))
+		for i, x := range vv {
+			l[i] = yamlToJSON(x)
+		}
+		return l
+	default:
+		return v
+	}
+}
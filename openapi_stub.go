@@ -0,0 +1,320 @@
+package httptester
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// StubServer starts a server that answers every operation of the document
+// with its documented example or, if there is none, a value generated from
+// the response schema. It is closed when the test ends.
+func (o *OpenAPI) StubServer(t testing.TB) *httptest.Server {
+	server := httptest.NewServer(o.StubHandler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+// StubHandler returns the handler used by StubServer. The response of an
+// operation is its lowest documented 2xx status (or default) using the
+// application/json media type if documented.
+func (o *OpenAPI) StubHandler() http.Handler {
+	basePath := o.basePath()
+	operations := o.operations()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, basePath)
+
+		pathFound := false
+		for _, op := range operations {
+			if !pathMatches(op.path, path) {
+				continue
+			}
+			pathFound = true
+			if op.method == r.Method {
+				o.writeStubResponse(w, op)
+				return
+			}
+		}
+
+		if pathFound {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		http.NotFound(w, r)
+	})
+}
+
+// basePath returns the path of the first server URL, which prefixes the
+// paths of all operations.
+func (o *OpenAPI) basePath() string {
+	if servers, _ := o.doc["servers"].([]interface{}); len(servers) > 0 {
+		if server, ok := servers[0].(map[string]interface{}); ok {
+			if u, err := url.Parse(fmt.Sprint(server["url"])); err == nil {
+				return strings.TrimSuffix(u.Path, "/")
+			}
+		}
+	}
+	return ""
+}
+
+func (o *OpenAPI) writeStubResponse(w http.ResponseWriter, op openAPIOperation) {
+	responses, _ := op.operation["responses"].(map[string]interface{})
+
+	codes := []string{}
+	for code := range responses {
+		if len(code) == 3 && code[0] == '2' {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	statusCode := "default"
+	if len(codes) > 0 {
+		statusCode = codes[0]
+	}
+
+	status, err := strconv.Atoi(strings.Replace(statusCode, "XX", "00", 1))
+	if err != nil {
+		status = http.StatusOK
+	}
+
+	response, _ := o.resolve(responses[statusCode]).(map[string]interface{})
+
+	headers, _ := response["headers"].(map[string]interface{})
+	for name, h := range headers {
+		header, _ := o.resolve(h).(map[string]interface{})
+		if example, ok := o.example(header, header["schema"], 0); ok {
+			w.Header().Set(name, fmt.Sprint(example))
+		}
+	}
+
+	content, _ := response["content"].(map[string]interface{})
+	if len(content) == 0 {
+		w.WriteHeader(status)
+		return
+	}
+
+	mediaType := "application/json"
+	if _, ok := content[mediaType]; !ok {
+		mediaTypes := make([]string, 0, len(content))
+		for k := range content {
+			mediaTypes = append(mediaTypes, k)
+		}
+		sort.Strings(mediaTypes)
+		mediaType = mediaTypes[0]
+	}
+
+	media, _ := content[mediaType].(map[string]interface{})
+	example, _ := o.example(media, media["schema"], 0)
+
+	var body []byte
+	if s, ok := example.(string); ok && !isJSONMediaType(mediaType) {
+		body = []byte(s)
+	} else {
+		body, _ = json.Marshal(example)
+	}
+
+	if strings.Contains(mediaType, "*") {
+		mediaType = "application/octet-stream"
+	}
+
+	w.Header().Set("Content-Type", mediaType)
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// example returns the example of a media type, parameter or header object,
+// falling back to a value generated from schema.
+func (o *OpenAPI) example(obj map[string]interface{}, schema interface{}, depth int) (interface{}, bool) {
+	if example, ok := obj["example"]; ok {
+		return example, true
+	}
+
+	if examples, ok := obj["examples"].(map[string]interface{}); ok {
+		names := make([]string, 0, len(examples))
+		for name := range examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if example, ok := o.resolve(examples[name]).(map[string]interface{}); ok {
+				if value, ok := example["value"]; ok {
+					return value, true
+				}
+			}
+		}
+	}
+
+	if schema == nil {
+		return nil, false
+	}
+
+	return o.generate(schema, depth), true
+}
+
+// generate returns a value that is valid against schema for the keywords
+// commonly used in API documents.
+func (o *OpenAPI) generate(schema interface{}, depth int) interface{} {
+	s, ok := o.resolve(schema).(map[string]interface{})
+	if !ok || depth > 16 {
+		return nil
+	}
+
+	for _, key := range []string{"example", "default", "const"} {
+		if v, ok := s[key]; ok {
+			return v
+		}
+	}
+	if examples, ok := s["examples"].([]interface{}); ok && len(examples) > 0 {
+		return examples[0]
+	}
+	if enum, ok := s["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+
+	if allOf, ok := s["allOf"].([]interface{}); ok {
+		merged := map[string]interface{}{}
+		for _, sub := range allOf {
+			if m, ok := o.generate(sub, depth+1).(map[string]interface{}); ok {
+				for k, v := range m {
+					merged[k] = v
+				}
+			}
+		}
+		if props, ok := o.generate(withoutKey(s, "allOf"), depth+1).(map[string]interface{}); ok {
+			for k, v := range props {
+				merged[k] = v
+			}
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if list, ok := s[key].([]interface{}); ok && len(list) > 0 {
+			return o.generate(list[0], depth+1)
+		}
+	}
+
+	switch schemaType(s) {
+	case "object":
+		obj := map[string]interface{}{}
+		props, _ := s["properties"].(map[string]interface{})
+		for name, prop := range props {
+			obj[name] = o.generate(prop, depth+1)
+		}
+		return obj
+	case "array":
+		minItems, _ := schemaNumber(s["minItems"])
+		n := int(math.Max(1, minItems))
+		items := make([]interface{}, n)
+		for i := range items {
+			items[i] = o.generate(s["items"], depth+1)
+		}
+		return items
+	case "string":
+		return generateString(s)
+	case "integer":
+		return generateNumber(s, true)
+	case "number":
+		return generateNumber(s, false)
+	case "boolean":
+		return true
+	case "null":
+		return nil
+	}
+
+	return nil
+}
+
+func schemaType(s map[string]interface{}) string {
+	switch t := s["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, tt := range t {
+			if tt != "null" {
+				return fmt.Sprint(tt)
+			}
+		}
+	}
+
+	if _, ok := s["properties"]; ok {
+		return "object"
+	}
+	if _, ok := s["items"]; ok {
+		return "array"
+	}
+
+	return ""
+}
+
+func withoutKey(m map[string]interface{}, key string) map[string]interface{} {
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if k != key {
+			c[k] = v
+		}
+	}
+	return c
+}
+
+func generateString(s map[string]interface{}) string {
+	var value string
+
+	switch s["format"] {
+	case "date-time":
+		value = "2024-01-01T00:00:00Z"
+	case "date":
+		value = "2024-01-01"
+	case "email":
+		value = "user@example.com"
+	case "uuid":
+		value = "00000000-0000-4000-8000-000000000000"
+	case "uri":
+		value = "https://example.com"
+	case "ipv4":
+		value = "192.0.2.1"
+	case "ipv6":
+		value = "2001:db8::1"
+	default:
+		value = "string"
+	}
+
+	if minLength, ok := schemaNumber(s["minLength"]); ok && len(value) < int(minLength) {
+		value += strings.Repeat("x", int(minLength)-len(value))
+	}
+	if maxLength, ok := schemaNumber(s["maxLength"]); ok && len(value) > int(maxLength) {
+		value = value[:int(maxLength)]
+	}
+
+	return value
+}
+
+func generateNumber(s map[string]interface{}, integer bool) interface{} {
+	value := 0.0
+
+	if min, ok := schemaNumber(s["minimum"]); ok {
+		value = min
+		if exclusive, _ := s["exclusiveMinimum"].(bool); exclusive {
+			value++
+		}
+	} else if min, ok := schemaNumber(s["exclusiveMinimum"]); ok {
+		value = min + 1
+	} else if max, ok := schemaNumber(s["maximum"]); ok && max < 0 {
+		value = max
+	}
+
+	if integer {
+		return int64(math.Ceil(value))
+	}
+	return value
+}
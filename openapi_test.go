@@ -0,0 +1,184 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+const articlesSpec = `
+openapi: 3.0.3
+info:
+  title: Articles
+  version: "1.0"
+paths:
+  /articles/{id}:
+    get:
+      operationId: getArticle
+      responses:
+        200:
+          description: Article
+          headers:
+            X-Rate-Limit:
+              required: true
+              schema:
+                type: integer
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Article"
+        default:
+          description: Error
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [error]
+components:
+  schemas:
+    Article:
+      type: object
+      required: [id, title]
+      additionalProperties: false
+      properties:
+        id:
+          type: integer
+        title:
+          type: string
+          minLength: 1
+        tags:
+          type: array
+          items:
+            type: string
+        publishedAt:
+          type: string
+          format: date-time
+          nullable: true
+`
+
+func TestResponseMatchesOpenAPI(t *testing.T) {
+	body := `{"id":1,"title":"Test","tags":["go"],"publishedAt":null}`
+	rateLimit := "10"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if rateLimit != "" {
+			w.Header().Set("X-Rate-Limit", rateLimit)
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	spec, err := httptester.ParseOpenAPI([]byte(articlesSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	httptester.New(t, server.URL).GET("/articles/1").Do().MatchesOpenAPI(spec, "getArticle")
+
+	body = `{"id":"1","title":"","extra":true}`
+	rateLimit = ""
+
+	var errs []error
+	collectErrors(server.URL, &errs).GET("/articles/1").Do().MatchesOpenAPI(spec, "getArticle")
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error got %v", errs)
+	}
+
+	for _, expected := range []string{
+		"missing required header X-Rate-Limit",
+		"$.id: expected integer got string",
+		"$.title: expected length >= 1 got 0",
+		"additional property extra is not allowed",
+	} {
+		if !strings.Contains(errs[0].Error(), expected) {
+			t.Fatalf("expected %q in %s", expected, errs[0])
+		}
+	}
+}
+
+func TestResponseMatchesOpenAPIInvalidMediaType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	spec, err := httptester.ParseOpenAPI([]byte(`
+openapi: 3.1.0
+info:
+  title: Broken
+  version: "1.0"
+paths:
+  /:
+    get:
+      operationId: get
+      responses:
+        200:
+          description: OK
+          content:
+            application/json: schema
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var errs []error
+	collectErrors(server.URL, &errs).GET("/").Do().MatchesOpenAPI(spec, "get")
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "invalid OpenAPI document: media type application/json of operation get is not an object") {
+		t.Fatalf("expected invalid document error got %v", errs)
+	}
+}
+
+func TestOpenAPIStubServer(t *testing.T) {
+	spec, err := httptester.ParseOpenAPI([]byte(articlesSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := spec.StubServer(t)
+	c := httptester.New(t, server.URL)
+
+	c.GET("/articles/42").Do().Status(200).
+		MatchesOpenAPI(spec, "getArticle").
+		HeaderEq("X-Rate-Limit", "0").
+		JSONPath("$.title", "string").
+		JSONPathLen("$.tags", 1)
+
+	c.DELETE("/articles/42").Do().Status(405)
+	c.GET("/users").Do().Status(404)
+
+	spec, err = httptester.ParseOpenAPI([]byte(`
+openapi: 3.1.0
+info:
+  title: Users
+  version: "1.0"
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /users:
+    post:
+      operationId: createUser
+      responses:
+        "201":
+          description: Created
+          content:
+            application/json:
+              examples:
+                ann:
+                  value: {"id": 7, "name": "Ann"}
+        "400":
+          description: Bad request
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server = spec.StubServer(t)
+	httptester.New(t, server.URL).POST("/v1/users").Do().Status(201).
+		MatchesOpenAPI(spec, "createUser").
+		JSONPath("$", map[string]interface{}{"id": 7, "name": "Ann"})
+}
@@ -0,0 +1,192 @@
+package httptester
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Pact records the requests of builders it is attached to (see
+// ReqBuilder.Pact) and their responses as a Pact v3 consumer contract.
+// Request headers listed in DefaultScrubHeaders are left out and only the
+// Content-Type response header is recorded so the contract does not depend
+// on incidental headers.
+type Pact struct {
+	mu           sync.Mutex
+	consumer     string
+	provider     string
+	interactions []PactInteraction
+}
+
+type PactInteraction struct {
+	Description    string              `json:"description"`
+	ProviderStates []PactProviderState `json:"providerStates,omitempty"`
+	Request        PactRequest         `json:"request"`
+	Response       PactResponse        `json:"response"`
+}
+
+type PactProviderState struct {
+	Name string `json:"name"`
+}
+
+type PactRequest struct {
+	Method  string              `json:"method"`
+	Path    string              `json:"path"`
+	Query   map[string][]string `json:"query,omitempty"`
+	Headers map[string]string   `json:"headers,omitempty"`
+	Body    interface{}         `json:"body,omitempty"`
+}
+
+type PactResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    interface{}       `json:"body,omitempty"`
+}
+
+func NewPact(consumer string, provider string) *Pact {
+	return &Pact{
+		consumer: consumer,
+		provider: provider,
+	}
+}
+
+func (p *Pact) Interactions() []PactInteraction {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]PactInteraction(nil), p.interactions...)
+}
+
+// add records an interaction unless one with the same description and
+// provider states was already recorded.
+func (p *Pact) add(interaction PactInteraction) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, i := range p.interactions {
+		if i.Description == interaction.Description && pactStatesEqual(i.ProviderStates, interaction.ProviderStates) {
+			return
+		}
+	}
+
+	p.interactions = append(p.interactions, interaction)
+}
+
+func pactStatesEqual(a []PactProviderState, b []PactProviderState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Pact) MarshalJSON() ([]byte, error) {
+	interactions := p.Interactions()
+	if interactions == nil {
+		interactions = []PactInteraction{}
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"consumer":     map[string]string{"name": p.consumer},
+		"provider":     map[string]string{"name": p.provider},
+		"interactions": interactions,
+		"metadata": map[string]interface{}{
+			"pactSpecification": map[string]string{"version": "3.0.0"},
+		},
+	})
+}
+
+// WriteFile writes the contract to dir as <consumer>-<provider>.json, the
+// file name expected by Pact brokers and provider verifiers.
+func (p *Pact) WriteFile(dir string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, p.consumer+"-"+p.provider+".json"), data, 0644)
+}
+
+func (b *ReqBuilder) Pact(pact *Pact, description string, providerStates ...string) *ReqBuilder {
+	b.pact = pact
+	b.pactDescription = description
+	b.pactStates = providerStates
+	return b
+}
+
+func (b *ReqBuilder) recordPact(req *http.Request, reqBody []byte, r *Response) {
+	interaction := PactInteraction{
+		Description: b.pactDescription,
+		Request: PactRequest{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Body:   pactBody(req.Header.Get("Content-Type"), reqBody),
+		},
+		Response: PactResponse{
+			Status: r.StatusCode,
+			Body:   pactBody(r.Header.Get("Content-Type"), r.Body),
+		},
+	}
+
+	for _, state := range b.pactStates {
+		interaction.ProviderStates = append(interaction.ProviderStates, PactProviderState{Name: state})
+	}
+
+	if query := req.URL.Query(); len(query) > 0 {
+		interaction.Request.Query = query
+	}
+
+	headers := map[string]string{}
+	for k, vs := range req.Header {
+		if !isScrubHeader(k) {
+			headers[k] = strings.Join(vs, ", ")
+		}
+	}
+	if len(headers) > 0 {
+		interaction.Request.Headers = headers
+	}
+
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		interaction.Response.Headers = map[string]string{"Content-Type": contentType}
+	}
+
+	b.pact.add(interaction)
+}
+
+func isScrubHeader(name string) bool {
+	for _, h := range DefaultScrubHeaders {
+		if http.CanonicalHeaderKey(h) == http.CanonicalHeaderKey(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// pactBody returns JSON bodies as values and other bodies as strings.
+func pactBody(contentType string, body []byte) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if isJSONMediaType(mediaType) {
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err == nil {
+			return v
+		}
+	}
+
+	return string(body)
+}
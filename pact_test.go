@@ -0,0 +1,75 @@
+package httptester_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestPact(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "123")
+		w.WriteHeader(201)
+		w.Write([]byte(`{"id":1,"name":"Ann"}`))
+	}))
+	defer server.Close()
+
+	pact := httptester.NewPact("web", "users-api")
+	c := httptester.New(t, server.URL)
+
+	for i := 0; i < 2; i++ {
+		c.POST("/users").Q("notify", "1").Bearer("secret").
+			JSON(map[string]string{"name": "Ann"}).
+			Pact(pact, "create a user", "no users exist").
+			Do().Status(201)
+	}
+
+	dir := t.TempDir()
+	if err := pact.WriteFile(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "web-users-api.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var actual interface{}
+	if err := json.Unmarshal(data, &actual); err != nil {
+		t.Fatal(err)
+	}
+
+	var expected interface{}
+	json.Unmarshal([]byte(`{
+		"consumer": {"name": "web"},
+		"provider": {"name": "users-api"},
+		"interactions": [{
+			"description": "create a user",
+			"providerStates": [{"name": "no users exist"}],
+			"request": {
+				"method": "POST",
+				"path": "/users",
+				"query": {"notify": ["1"]},
+				"headers": {"Content-Type": "application/json"},
+				"body": {"name": "Ann"}
+			},
+			"response": {
+				"status": 201,
+				"headers": {"Content-Type": "application/json"},
+				"body": {"id": 1, "name": "Ann"}
+			}
+		}],
+		"metadata": {"pactSpecification": {"version": "3.0.0"}}
+	}`), &expected)
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("unexpected pact %s", data)
+	}
+}
@@ -0,0 +1,105 @@
+package httptester
+
+import (
+	"errors"
+	"sync"
+)
+
+// ParallelRunner sends independent requests concurrently, see Parallel.
+type ParallelRunner struct {
+	builders    []*ReqBuilder
+	concurrency int
+}
+
+// Parallel returns a ParallelRunner that sends the requests of builders with
+// at most 8 in flight, e.g. to test concurrent access to an endpoint or to
+// speed up independent setup calls. The builders are not modified.
+func Parallel(builders ...*ReqBuilder) *ParallelRunner {
+	return &ParallelRunner{
+		builders:    builders,
+		concurrency: 8,
+	}
+}
+
+// Concurrency sets the number of requests in flight.
+func (p *ParallelRunner) Concurrency(n int) *ParallelRunner {
+	if n < 1 {
+		n = 1
+	}
+	p.concurrency = n
+	return p
+}
+
+// Do sends the requests and returns their responses in the order of the
+// builders, nil for failed requests. Errors are reported to the error
+// handler of their builder once all requests are done, so handlers such as
+// t.Fatal run on the calling goroutine.
+func (p *ParallelRunner) Do() []*Response {
+	responses, errs := p.run()
+
+	for i, b := range p.builders {
+		b.helper()
+		for _, err := range errs[i] {
+			b.onError(err)
+		}
+	}
+
+	return responses
+}
+
+// Try sends the requests like Do but returns the errors of all requests
+// joined instead of reporting them.
+func (p *ParallelRunner) Try() ([]*Response, error) {
+	responses, errs := p.run()
+
+	var all []error
+	for _, e := range errs {
+		all = append(all, e...)
+	}
+
+	return responses, errors.Join(all...)
+}
+
+func (p *ParallelRunner) run() ([]*Response, [][]error) {
+	responses := make([]*Response, len(p.builders))
+	errs := make([][]error, len(p.builders))
+
+	// Cloning buffers the bodies on the calling goroutine and lets the same
+	// builder be passed more than once.
+	clones := make([]*ReqBuilder, len(p.builders))
+	for i, b := range p.builders {
+		i := i
+		clones[i] = b.Clone().OnError(func(err error) {
+			errs[i] = append(errs[i], err)
+		})
+	}
+
+	jobs := make(chan int, len(clones))
+	for i := range clones {
+		jobs <- i
+	}
+	close(jobs)
+
+	wg := &sync.WaitGroup{}
+	for w := 0; w < p.concurrency && w < len(clones); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				responses[i] = clones[i].Do()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Assertions on the responses and follow up requests report to the
+	// original handlers.
+	for i, res := range responses {
+		clones[i].OnError(p.builders[i].onError)
+		if res != nil {
+			res.onError = p.builders[i].onError
+		}
+	}
+
+	return responses, errs
+}
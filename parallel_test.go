@@ -0,0 +1,77 @@
+package httptester_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bancek/httptester"
+)
+
+func TestParallel(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	var builders []*httptester.ReqBuilder
+	for i := 0; i < 6; i++ {
+		builders = append(builders, c.GET(fmt.Sprintf("/%d", i)))
+	}
+
+	responses := httptester.Parallel(builders...).Concurrency(3).Do()
+	if len(responses) != 6 {
+		t.Fatalf("expected 6 responses got %d", len(responses))
+	}
+	for i, res := range responses {
+		res.Status(200).Eq(fmt.Sprintf("/%d", i))
+	}
+	if maxInFlight != 3 {
+		t.Fatalf("expected 3 requests in flight got %d", maxInFlight)
+	}
+
+	var errs []error
+	responses = httptester.Parallel(
+		collectErrors(server.URL, &errs).GET("/ok"),
+		collectErrors("http://127.0.0.1:1", &errs).GET("/down"),
+	).Do()
+	if responses[0] == nil || responses[1] != nil {
+		t.Fatalf("expected only the second request to fail got %v", responses)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "127.0.0.1:1/down") {
+		t.Fatalf("expected the transport error got %v", errs)
+	}
+
+	responses[0].Eq("/nope")
+	if len(errs) != 2 || !strings.Contains(errs[1].Error(), "body does not equal /nope") {
+		t.Fatalf("expected assertions to report to the builder's handler got %v", errs)
+	}
+
+	_, err := httptester.Parallel(
+		c.GET("/a"),
+		collectErrors("http://127.0.0.1:1", &errs).GET("/b"),
+		collectErrors("http://127.0.0.1:1", &errs).GET("/c"),
+	).Try()
+	if err == nil || !strings.Contains(err.Error(), "/b") || !strings.Contains(err.Error(), "/c") {
+		t.Fatalf("expected joined errors got %v", err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected Try not to report errors got %v", errs[2:])
+	}
+}
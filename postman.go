@@ -0,0 +1,534 @@
+package httptester
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// PostmanCollection is a Postman collection (v2.1). Requests use the
+// collection variables through Vars, which share the {{name}} syntax.
+type PostmanCollection struct {
+	Info     PostmanInfo       `json:"info"`
+	Item     []PostmanItem     `json:"item"`
+	Variable []PostmanVariable `json:"variable,omitempty"`
+	Auth     *PostmanAuth      `json:"auth,omitempty"`
+
+	vars *Vars
+}
+
+type PostmanInfo struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+// PostmanItem is a request or, if Item is set, a folder.
+type PostmanItem struct {
+	Name    string          `json:"name"`
+	Item    []PostmanItem   `json:"item,omitempty"`
+	Request *PostmanRequest `json:"request,omitempty"`
+	Auth    *PostmanAuth    `json:"auth,omitempty"`
+}
+
+type PostmanRequest struct {
+	Method string            `json:"method"`
+	Header []PostmanKeyValue `json:"header,omitempty"`
+	URL    PostmanURL        `json:"url"`
+	Body   *PostmanBody      `json:"body,omitempty"`
+	Auth   *PostmanAuth      `json:"auth,omitempty"`
+}
+
+type PostmanKeyValue struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Type     string `json:"type,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+type PostmanVariable struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+// PostmanURL is a URL that is either a string or an object in the
+// collection file.
+type PostmanURL struct {
+	Raw      string            `json:"raw"`
+	Query    []PostmanKeyValue `json:"query,omitempty"`
+	Variable []PostmanKeyValue `json:"variable,omitempty"`
+}
+
+func (u *PostmanURL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		*u = PostmanURL{Raw: raw}
+		return nil
+	}
+
+	type postmanURL PostmanURL
+	return json.Unmarshal(data, (*postmanURL)(u))
+}
+
+type PostmanBody struct {
+	Mode       string              `json:"mode"`
+	Raw        string              `json:"raw,omitempty"`
+	URLEncoded []PostmanKeyValue   `json:"urlencoded,omitempty"`
+	FormData   []PostmanKeyValue   `json:"formdata,omitempty"`
+	GraphQL    *PostmanGraphQL     `json:"graphql,omitempty"`
+	Options    *PostmanBodyOptions `json:"options,omitempty"`
+}
+
+type PostmanGraphQL struct {
+	Query     string `json:"query"`
+	Variables string `json:"variables,omitempty"`
+}
+
+type PostmanBodyOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+// PostmanAuth is the auth of a request, folder or collection. The values
+// of the auth type are stored under the key of the type, e.g. Bearer.
+type PostmanAuth struct {
+	Type   string            `json:"type"`
+	Bearer []PostmanKeyValue `json:"bearer,omitempty"`
+	Basic  []PostmanKeyValue `json:"basic,omitempty"`
+	APIKey []PostmanKeyValue `json:"apikey,omitempty"`
+}
+
+func ParsePostmanCollection(data []byte) (*PostmanCollection, error) {
+	c := &PostmanCollection{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("invalid Postman collection: %w", err)
+	}
+
+	c.vars = NewVars()
+	for _, v := range c.Variable {
+		c.vars.Set(v.Key, v.Value)
+	}
+
+	return c, nil
+}
+
+func LoadPostmanCollection(path string) (*PostmanCollection, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := ParsePostmanCollection(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Vars returns the collection variables. Set a variable to override it,
+// e.g. the base URL of the server under test.
+func (c *PostmanCollection) Vars() *Vars {
+	return c.vars
+}
+
+type postmanEntry struct {
+	name string
+	req  *PostmanRequest
+	auth *PostmanAuth
+}
+
+// entries returns the requests in order. Names of requests in folders are
+// prefixed by the folder names separated by /.
+func (c *PostmanCollection) entries() []postmanEntry {
+	entries := []postmanEntry{}
+
+	var walk func(items []PostmanItem, prefix string, auth *PostmanAuth)
+	walk = func(items []PostmanItem, prefix string, auth *PostmanAuth) {
+		for _, item := range items {
+			itemAuth := auth
+			if item.Auth != nil {
+				itemAuth = item.Auth
+			}
+
+			if item.Request == nil {
+				walk(item.Item, prefix+item.Name+"/", itemAuth)
+				continue
+			}
+
+			if item.Request.Auth != nil {
+				itemAuth = item.Request.Auth
+			}
+
+			entries = append(entries, postmanEntry{
+				name: prefix + item.Name,
+				req:  item.Request,
+				auth: itemAuth,
+			})
+		}
+	}
+	walk(c.Item, "", c.Auth)
+
+	return entries
+}
+
+func (c *PostmanCollection) Names() []string {
+	names := []string{}
+	for _, entry := range c.entries() {
+		names = append(names, entry.name)
+	}
+	return names
+}
+
+// Request returns a builder from client for the request with name.
+func (c *PostmanCollection) Request(client *Client, name string) *ReqBuilder {
+	b := client.Request()
+	b.helper()
+
+	for _, entry := range c.entries() {
+		if entry.name == name {
+			return c.build(b, entry)
+		}
+	}
+
+	b.onError(fmt.Errorf("request %s not found in Postman collection %s", name, c.Info.Name))
+	return nil
+}
+
+// Each calls f with a builder from client for every request in order.
+func (c *PostmanCollection) Each(client *Client, f func(name string, b *ReqBuilder)) {
+	for _, entry := range c.entries() {
+		f(entry.name, c.build(client.Request(), entry))
+	}
+}
+
+// Run runs a subtest for every request in order with a builder from a
+// Client created by New for the subtest.
+func (c *PostmanCollection) Run(t *testing.T, f func(t *testing.T, b *ReqBuilder), opts ...ClientOption) {
+	for _, entry := range c.entries() {
+		entry := entry
+		t.Run(entry.name, func(t *testing.T) {
+			f(t, c.build(New(t, "", opts...).Request(), entry))
+		})
+	}
+}
+
+var postmanPathVarRegexp = regexp.MustCompile(`/:(\w+)`)
+
+func (c *PostmanCollection) build(b *ReqBuilder, entry postmanEntry) *ReqBuilder {
+	req := entry.req
+
+	method := req.Method
+	if method == "" {
+		method = "GET"
+	}
+
+	b.Vars(c.vars)
+	b.Method(method, postmanPathVarRegexp.ReplaceAllString(req.URL.Raw, "/{$1}"))
+
+	for _, v := range req.URL.Variable {
+		b.Param(v.Key, v.Value)
+	}
+
+	if req.Body != nil {
+		c.buildBody(b, req.Body)
+	}
+
+	for _, h := range req.Header {
+		if !h.Disabled {
+			b.Header(h.Key, h.Value)
+		}
+	}
+
+	if entry.auth != nil {
+		c.buildAuth(b, entry.auth)
+	}
+
+	return b
+}
+
+func (c *PostmanCollection) buildBody(b *ReqBuilder, body *PostmanBody) {
+	switch body.Mode {
+	case "raw":
+		if body.Options != nil && body.Options.Raw.Language == "json" {
+			b.Header("Content-Type", "application/json")
+		}
+		b.Body(strings.NewReader(body.Raw))
+	case "urlencoded":
+		args := []string{}
+		for _, kv := range body.URLEncoded {
+			if !kv.Disabled {
+				args = append(args, kv.Key, kv.Value)
+			}
+		}
+		b.Form(args...)
+	case "formdata":
+		m := b.Multipart()
+		for _, kv := range body.FormData {
+			if !kv.Disabled && kv.Type != "file" {
+				m.Field(kv.Key, kv.Value)
+			}
+		}
+		m.Done()
+	case "graphql":
+		if body.GraphQL != nil {
+			var variables map[string]interface{}
+			if body.GraphQL.Variables != "" {
+				if err := json.Unmarshal([]byte(body.GraphQL.Variables), &variables); err != nil {
+					b.onError(fmt.Errorf("invalid GraphQL variables: %w", err))
+				}
+			}
+			b.GraphQL(body.GraphQL.Query, variables, "")
+		}
+	}
+}
+
+func postmanAuthValue(values []PostmanKeyValue, key string) string {
+	for _, kv := range values {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return ""
+}
+
+func (c *PostmanCollection) expandNow(s string) string {
+	if expanded, err := c.vars.expand(s); err == nil {
+		return expanded
+	}
+	return s
+}
+
+func (c *PostmanCollection) buildAuth(b *ReqBuilder, auth *PostmanAuth) {
+	switch auth.Type {
+	case "bearer":
+		b.Bearer(postmanAuthValue(auth.Bearer, "token"))
+	case "basic":
+		// The credentials are encoded when the request is built so variables
+		// are expanded with the values they have at that time.
+		b.Basic(c.expandNow(postmanAuthValue(auth.Basic, "username")), c.expandNow(postmanAuthValue(auth.Basic, "password")))
+	case "apikey":
+		key := postmanAuthValue(auth.APIKey, "key")
+		value := postmanAuthValue(auth.APIKey, "value")
+		if postmanAuthValue(auth.APIKey, "in") == "query" {
+			b.Q(key, value)
+		} else {
+			b.Header(key, value)
+		}
+	}
+}
+
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// NewPostmanCollection returns an empty collection to Add requests to.
+func NewPostmanCollection(name string) *PostmanCollection {
+	return &PostmanCollection{
+		Info: PostmanInfo{
+			Name:   name,
+			Schema: postmanSchema,
+		},
+		Item: []PostmanItem{},
+		vars: NewVars(),
+	}
+}
+
+// Add adds the request of b under name. A name containing / places the
+// request in folders. URL templates, {{name}} variables and the values of
+// the builder's Vars are kept so the request can be replayed in Postman.
+func (c *PostmanCollection) Add(name string, b *ReqBuilder) *PostmanCollection {
+	b.helper()
+
+	req, err := postmanRequest(b)
+	if err != nil {
+		b.onError(err)
+		return c
+	}
+
+	if b.vars != nil {
+		values := b.vars.all()
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			c.setVariable(k, values[k])
+		}
+	}
+
+	parts := strings.Split(name, "/")
+	items := &c.Item
+	for _, folder := range parts[:len(parts)-1] {
+		items = postmanFolder(items, folder)
+	}
+	*items = append(*items, PostmanItem{
+		Name:    parts[len(parts)-1],
+		Request: req,
+	})
+
+	return c
+}
+
+func (c *PostmanCollection) setVariable(key string, value interface{}) {
+	c.vars.Set(key, value)
+
+	for i, v := range c.Variable {
+		if v.Key == key {
+			c.Variable[i].Value = value
+			return
+		}
+	}
+	c.Variable = append(c.Variable, PostmanVariable{Key: key, Value: value})
+}
+
+func postmanFolder(items *[]PostmanItem, name string) *[]PostmanItem {
+	for i := range *items {
+		if (*items)[i].Name == name && (*items)[i].Request == nil {
+			return &(*items)[i].Item
+		}
+	}
+	*items = append(*items, PostmanItem{Name: name, Item: []PostmanItem{}})
+	return &(*items)[len(*items)-1].Item
+}
+
+// postmanURLRegexp matches {{variables}}, which are kept, and {param} path
+// placeholders, which are converted to Postman's :param syntax.
+var postmanURLRegexp = regexp.MustCompile(`\{\{[^{}]*\}\}|\{([^{}/]+)\}`)
+
+func postmanRequest(b *ReqBuilder) (*PostmanRequest, error) {
+	method := b.method
+	if method == "" {
+		method = "GET"
+	}
+
+	req := &PostmanRequest{
+		Method: method,
+		Header: []PostmanKeyValue{},
+	}
+
+	raw := postmanURLRegexp.ReplaceAllStringFunc(b.baseURL+b.url, func(m string) string {
+		if strings.HasPrefix(m, "{{") {
+			return m
+		}
+		name := m[1 : len(m)-1]
+		req.URL.Variable = append(req.URL.Variable, PostmanKeyValue{Key: name, Value: b.params[name]})
+		return ":" + name
+	})
+
+	keys := make([]string, 0, len(b.query))
+	for k := range b.query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	query := []string{}
+	for _, k := range keys {
+		for _, v := range b.query[k] {
+			req.URL.Query = append(req.URL.Query, PostmanKeyValue{Key: k, Value: v})
+			query = append(query, k+"="+v)
+		}
+	}
+	if len(query) > 0 {
+		sep := "?"
+		if strings.Contains(raw, "?") {
+			sep = "&"
+		}
+		raw += sep + strings.Join(query, "&")
+	}
+	req.URL.Raw = raw
+
+	for _, k := range sortedKeys(b.headers) {
+		for _, v := range b.headers[k] {
+			req.Header = append(req.Header, PostmanKeyValue{Key: k, Value: v, Type: "text"})
+		}
+	}
+
+	body, err := b.readBody()
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Body, err = postmanBody(b.headers.Get("Content-Type"), body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return req, nil
+}
+
+func sortedKeys(header http.Header) []string {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func postmanBody(contentType string, body []byte) (*PostmanBody, error) {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, err
+		}
+		pb := &PostmanBody{Mode: "urlencoded"}
+		for _, k := range sortedKeys(http.Header(values)) {
+			for _, v := range values[k] {
+				pb.URLEncoded = append(pb.URLEncoded, PostmanKeyValue{Key: k, Value: v})
+			}
+		}
+		return pb, nil
+	case mediaType == "multipart/form-data":
+		pb := &PostmanBody{Mode: "formdata"}
+		reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				return pb, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			if part.FileName() != "" {
+				pb.FormData = append(pb.FormData, PostmanKeyValue{Key: part.FormName(), Value: part.FileName(), Type: "file"})
+				continue
+			}
+			value, err := io.ReadAll(part)
+			if err != nil {
+				return nil, err
+			}
+			pb.FormData = append(pb.FormData, PostmanKeyValue{Key: part.FormName(), Value: string(value), Type: "text"})
+		}
+	}
+
+	pb := &PostmanBody{
+		Mode: "raw",
+		Raw:  string(body),
+	}
+	if isJSONMediaType(mediaType) {
+		pb.Options = &PostmanBodyOptions{}
+		pb.Options.Raw.Language = "json"
+	}
+	return pb, nil
+}
+
+// WriteFile writes the collection in the v2.1 format importable by Postman.
+func (c *PostmanCollection) WriteFile(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,205 @@
+package httptester_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+const usersCollection = `{
+  "info": {
+    "name": "Users",
+    "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+  },
+  "auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]},
+  "variable": [
+    {"key": "baseUrl", "value": "https://api.example.com"},
+    {"key": "token", "value": "secret"}
+  ],
+  "item": [
+    {
+      "name": "Users",
+      "item": [
+        {
+          "name": "Get user",
+          "request": {
+            "method": "GET",
+            "header": [
+              {"key": "Accept", "value": "application/json"},
+              {"key": "X-Debug", "value": "1", "disabled": true}
+            ],
+            "url": {
+              "raw": "{{baseUrl}}/users/:id?expand=roles",
+              "variable": [{"key": "id", "value": "42"}]
+            }
+          }
+        },
+        {
+          "name": "Create user",
+          "request": {
+            "method": "POST",
+            "url": "{{baseUrl}}/users",
+            "body": {
+              "mode": "raw",
+              "raw": "{\"name\": \"{{name}}\"}",
+              "options": {"raw": {"language": "json"}}
+            }
+          }
+        }
+      ]
+    },
+    {
+      "name": "Login",
+      "request": {
+        "method": "POST",
+        "auth": {"type": "basic", "basic": [{"key": "username", "value": "ann"}, {"key": "password", "value": "pass"}]},
+        "url": "{{baseUrl}}/login",
+        "body": {"mode": "urlencoded", "urlencoded": [{"key": "remember", "value": "true"}]}
+      }
+    },
+    {
+      "name": "Status",
+      "auth": {"type": "apikey", "apikey": [{"key": "key", "value": "api_key"}, {"key": "value", "value": "k1"}, {"key": "in", "value": "query"}]},
+      "request": {"method": "GET", "url": "{{baseUrl}}/status"}
+    }
+  ]
+}`
+
+func TestPostmanCollection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"uri":         r.URL.RequestURI(),
+			"auth":        r.Header.Get("Authorization"),
+			"accept":      r.Header.Get("Accept"),
+			"debug":       r.Header.Get("X-Debug"),
+			"contentType": r.Header.Get("Content-Type"),
+			"body":        string(body),
+		})
+	}))
+	defer server.Close()
+
+	col, err := httptester.ParsePostmanCollection([]byte(usersCollection))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedNames := []string{"Users/Get user", "Users/Create user", "Login", "Status"}
+	if names := col.Names(); !reflect.DeepEqual(names, expectedNames) {
+		t.Fatalf("unexpected names %v", names)
+	}
+
+	col.Vars().Set("baseUrl", server.URL)
+	col.Vars().Set("name", "Ann")
+
+	c := httptester.New(t, "")
+
+	col.Request(c, "Users/Get user").Do().Status(200).
+		JSONPath("$.uri", "/users/42?expand=roles").
+		JSONPath("$.auth", "Bearer secret").
+		JSONPath("$.accept", "application/json").
+		JSONPath("$.debug", "")
+
+	col.Request(c, "Users/Create user").Do().Status(200).
+		JSONPath("$.contentType", "application/json").
+		JSONPath("$.body", `{"name": "Ann"}`)
+
+	col.Request(c, "Login").Do().Status(200).
+		JSONPath("$.auth", "Basic YW5uOnBhc3M=").
+		JSONPath("$.body", "remember=true")
+
+	col.Request(c, "Status").Do().Status(200).
+		JSONPath("$.uri", "/status?api_key=k1").
+		JSONPath("$.auth", "")
+
+	names := []string{}
+	col.Run(t, func(t *testing.T, b *httptester.ReqBuilder) {
+		b.Do().Status(200)
+		names = append(names, t.Name())
+	})
+	if len(names) != 4 || names[0] != "TestPostmanCollection/Users/Get_user" {
+		t.Fatalf("unexpected subtests %v", names)
+	}
+
+	var errs []error
+	col.Request(httptester.NewClient(httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	})), "Missing")
+	if len(errs) != 1 || errs[0].Error() != "request Missing not found in Postman collection Users" {
+		t.Fatalf("expected not found error got %v", errs)
+	}
+}
+
+func TestPostmanCollectionExport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"method":      r.Method,
+			"uri":         r.URL.RequestURI(),
+			"auth":        r.Header.Get("Authorization"),
+			"contentType": r.Header.Get("Content-Type"),
+			"body":        string(body),
+		})
+	}))
+	defer server.Close()
+
+	vars := httptester.NewVars()
+	vars.Set("baseUrl", server.URL)
+	vars.Set("token", "secret")
+
+	c := httptester.New(t, "")
+
+	col := httptester.NewPostmanCollection("Users").
+		Add("Users/Get user", c.GET("{{baseUrl}}/users/{id}").Param("id", "42").Q("expand", "roles").Bearer("{{token}}").Vars(vars)).
+		Add("Users/Create user", c.POST("{{baseUrl}}/users").JSON(map[string]string{"name": "Ann"}).Vars(vars)).
+		Add("Login", c.POST("{{baseUrl}}/login").Form("remember", "true").Vars(vars))
+
+	path := filepath.Join(t.TempDir(), "users.postman_collection.json")
+	if err := col.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := httptester.LoadPostmanCollection(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedNames := []string{"Users/Get user", "Users/Create user", "Login"}
+	if names := imported.Names(); !reflect.DeepEqual(names, expectedNames) {
+		t.Fatalf("unexpected names %v", names)
+	}
+
+	if imported.Info.Schema != "https://schema.getpostman.com/json/collection/v2.1.0/collection.json" {
+		t.Fatalf("unexpected schema %s", imported.Info.Schema)
+	}
+
+	get := imported.Item[0].Item[0].Request
+	if get.URL.Raw != "{{baseUrl}}/users/:id?expand=roles" {
+		t.Fatalf("unexpected raw URL %s", get.URL.Raw)
+	}
+
+	if token := imported.Vars().String("token"); token != "secret" {
+		t.Fatalf("unexpected token variable %s", token)
+	}
+
+	imported.Request(c, "Users/Get user").Do().Status(200).
+		JSONPath("$.uri", "/users/42?expand=roles").
+		JSONPath("$.auth", "Bearer secret")
+
+	imported.Request(c, "Users/Create user").Do().Status(200).
+		JSONPath("$.method", "POST").
+		JSONPath("$.contentType", "application/json").
+		JSONPath("$.body", `{"name":"Ann"}`)
+
+	imported.Request(c, "Login").Do().Status(200).
+		JSONPath("$.contentType", "application/x-www-form-urlencoded").
+		JSONPath("$.body", "remember=true")
+}
@@ -0,0 +1,101 @@
+package httptester
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ProtoContentType is the Content-Type of protobuf bodies.
+const ProtoContentType = "application/x-protobuf"
+
+// protoContentTypes are the media types protobuf bodies are accepted with.
+var protoContentTypes = []string{
+	ProtoContentType,
+	"application/protobuf",
+	"application/vnd.google.protobuf",
+}
+
+type protoCodec struct{}
+
+func (protoCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a proto.Message", v)
+	}
+	return proto.Marshal(msg)
+}
+
+func (protoCodec) Unmarshal(data []byte, v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%T is not a proto.Message", v)
+	}
+	return proto.Unmarshal(data, msg)
+}
+
+func init() {
+	for _, contentType := range protoContentTypes {
+		RegisterCodec(contentType, protoCodec{})
+	}
+}
+
+// ProtoBody sets the body to the marshaled protobuf message msg and the
+// Content-Type to application/x-protobuf.
+func (b *ReqBuilder) ProtoBody(msg proto.Message) *ReqBuilder {
+	b.helper()
+
+	b.Header("Content-Type", ProtoContentType)
+	codec, err := codecFor(ProtoContentType)
+	if err != nil {
+		b.onError(err)
+		return b
+	}
+	data, err := codec.Marshal(msg)
+	if err != nil {
+		b.onError(fmt.Errorf("cannot marshal %T as protobuf: %w", msg, err))
+		return b
+	}
+	return b.Body(bytes.NewReader(data))
+}
+
+func isProtoContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range protoContentTypes {
+		if mediaType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// ProtoBody unmarshals the protobuf body into msg, see ReqBuilder.ProtoBody.
+func (r *Response) ProtoBody(msg proto.Message) proto.Message {
+	r.helper()
+
+	if !r.hasBody() {
+		return nil
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if !isProtoContentType(contentType) {
+		r.err(fmt.Errorf("Content-Type is not application/x-protobuf, got %s: %s", contentType, r.bodyExcerpt()))
+		return nil
+	}
+
+	codec, err := codecFor(ProtoContentType)
+	if err != nil {
+		r.err(err)
+		return nil
+	}
+	if err := codec.Unmarshal(r.Body, msg); err != nil {
+		r.err(fmt.Errorf("cannot unmarshal %d byte protobuf body into %T: %w", len(r.Body), msg, err))
+		return nil
+	}
+	return msg
+}
@@ -0,0 +1,68 @@
+package httptester_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestProto(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/echo":
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+			w.Write(body)
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{}`))
+		case "/broken":
+			w.Header().Set("Content-Type", "application/protobuf")
+			w.Write([]byte{0x0a, 0x05, 'a'})
+		}
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	out := &wrapperspb.StringValue{}
+	res := c.POST("/echo").ProtoBody(wrapperspb.String("alice")).Do().
+		HeaderEq("Content-Type", "application/x-protobuf").
+		Eq("\n\x05alice")
+	res.ProtoBody(out)
+	if out.Value != "alice" {
+		t.Fatalf("unexpected message %v", out)
+	}
+	if res.Proto != "HTTP/1.1" {
+		t.Fatalf("expected the protocol of the response got %s", res.Proto)
+	}
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.POST("/echo").ProtoBody(wrapperspb.String("\xff"))
+	b.POST("/echo").Header("Content-Type", "application/x-protobuf").Encode(struct{}{})
+	b.GET("/json").Do().ProtoBody(out)
+	b.GET("/broken").Do().ProtoBody(out)
+
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		"cannot marshal *wrapperspb.StringValue as protobuf: ",
+		"struct {} is not a proto.Message",
+		"Content-Type is not application/x-protobuf, got application/json: {}",
+		"cannot unmarshal 3 byte protobuf body into *wrapperspb.StringValue: ",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package httptester
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// HTTP1 restricts the request to HTTP/1.1, also over TLS where HTTP/2
+// would otherwise be negotiated.
+func (b *ReqBuilder) HTTP1() *ReqBuilder {
+	return b.transportOption(func(t *http.Transport) {
+		setHTTP1(t)
+		t.TLSClientConfig.NextProtos = []string{"http/1.1"}
+	})
+}
+
+// HTTP2 restricts the request to HTTP/2 negotiated with ALPN over TLS. The
+// request fails if the server does not support it.
+func (b *ReqBuilder) HTTP2() *ReqBuilder {
+	return b.transportOption(func(t *http.Transport) {
+		setHTTP2(t)
+		t.TLSClientConfig.NextProtos = []string{"h2"}
+	})
+}
+
+// HTTP2PriorKnowledge sends the request over unencrypted HTTP/2 (h2c)
+// without an upgrade, like curl --http2-prior-knowledge. It requires Go
+// 1.24 and reports an error when built with older versions.
+func (b *ReqBuilder) HTTP2PriorKnowledge() *ReqBuilder {
+	b.helper()
+
+	if err := b.http2PriorKnowledge(); err != nil {
+		b.onError(err)
+	}
+	return b
+}
+
+// ProtoEq checks the protocol of the response, e.g. HTTP/2.0.
+func (r *Response) ProtoEq(proto string) *Response {
+	r.helper()
+	defer r.track("ProtoEq", proto)()
+
+	if r.Proto != proto {
+		r.err(fmt.Errorf("expected protocol %s got %s", proto, r.Proto))
+	}
+
+	return r
+}
+
+// ExpectProtoAtLeast checks that the protocol of the response is at least
+// HTTP/major.minor.
+func (r *Response) ExpectProtoAtLeast(major int, minor int) *Response {
+	r.helper()
+	defer r.track("ExpectProtoAtLeast", major, minor)()
+
+	if !r.ProtoAtLeast(major, minor) {
+		r.err(fmt.Errorf("expected protocol at least HTTP/%d.%d got %s", major, minor, r.Proto))
+	}
+
+	return r
+}
+
+// Transport sends the request with rt instead of the client's transport,
+// e.g. quic-go's *http3.Transport for HTTP/3. This package does not depend
+// on a QUIC implementation so an HTTP/3 transport has to be provided.
+// Per-request options that configure an *http.Transport (e.g. ClientCert,
+// Proxy, InsecureTLS or HTTP2) fail the request if rt is not an
+// *http.Transport.
+func (b *ReqBuilder) Transport(rt http.RoundTripper) *ReqBuilder {
+	b.roundTripper = rt
+	return b
+}
+
+// AltSvc checks that the Alt-Svc header advertises protocol (e.g. h3), which
+// clients use to discover HTTP/3 and fall back to HTTP/1.1 or HTTP/2.
+func (r *Response) AltSvc(protocol string) *Response {
+	r.helper()
+	defer r.track("AltSvc", protocol)()
+
+	for _, value := range r.Header.Values("Alt-Svc") {
+		for _, service := range strings.Split(value, ",") {
+			id, _, _ := strings.Cut(strings.TrimSpace(service), "=")
+			if id == protocol {
+				return r
+			}
+		}
+	}
+
+	r.err(r.headerError("AltSvc", "Alt-Svc", protocol, "expected Alt-Svc to advertise %s got %q", protocol, strings.Join(r.Header.Values("Alt-Svc"), ", ")))
+
+	return r
+}
+
+// AltSvcClear checks that Alt-Svc is clear, which makes clients forget the
+// alternative services advertised before and fall back to the origin, e.g.
+// when HTTP/3 is being disabled.
+func (r *Response) AltSvcClear() *Response {
+	r.helper()
+	defer r.track("AltSvcClear")()
+
+	if values := r.Header.Values("Alt-Svc"); len(values) != 1 || strings.TrimSpace(values[0]) != "clear" {
+		r.err(r.headerError("AltSvcClear", "Alt-Svc", "clear", "expected Alt-Svc clear got %q", strings.Join(values, ", ")))
+	}
+
+	return r
+}
@@ -0,0 +1,28 @@
+//go:build go1.24
+
+package httptester
+
+import (
+	"net/http"
+)
+
+// Transports of Go 1.24 and newer select the protocols with
+// http.Transport.Protocols.
+
+func setHTTP1(t *http.Transport) {
+	t.Protocols = &http.Protocols{}
+	t.Protocols.SetHTTP1(true)
+}
+
+func setHTTP2(t *http.Transport) {
+	t.Protocols = &http.Protocols{}
+	t.Protocols.SetHTTP2(true)
+}
+
+func (b *ReqBuilder) http2PriorKnowledge() error {
+	b.transportOption(func(t *http.Transport) {
+		t.Protocols = &http.Protocols{}
+		t.Protocols.SetUnencryptedHTTP2(true)
+	})
+	return nil
+}
@@ -0,0 +1,27 @@
+//go:build go1.24
+
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestReqBuilderHTTP2PriorKnowledge(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	server.Config.Protocols = &http.Protocols{}
+	server.Config.Protocols.SetHTTP1(true)
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	c.GET("/").Do().Status(200).Eq("HTTP/1.1")
+	c.GET("/").HTTP2PriorKnowledge().Do().Status(200).Eq("HTTP/2.0").ProtoEq("HTTP/2.0")
+}
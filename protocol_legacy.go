@@ -0,0 +1,26 @@
+//go:build !go1.24
+
+package httptester
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+)
+
+// Transports older than Go 1.24 have no http.Transport.Protocols, HTTP/2 is
+// disabled with an empty TLSNextProto map and enabled with
+// ForceAttemptHTTP2.
+
+func setHTTP1(t *http.Transport) {
+	t.ForceAttemptHTTP2 = false
+	t.TLSNextProto = map[string]func(authority string, c *tls.Conn) http.RoundTripper{}
+}
+
+func setHTTP2(t *http.Transport) {
+	t.ForceAttemptHTTP2 = true
+}
+
+func (b *ReqBuilder) http2PriorKnowledge() error {
+	return errors.New("HTTP2PriorKnowledge requires Go 1.24 or newer")
+}
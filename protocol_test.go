@@ -0,0 +1,101 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestReqBuilderProtocols(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	})
+
+	tlsServer := httptest.NewUnstartedServer(handler)
+	tlsServer.EnableHTTP2 = true
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+
+	c := httptester.New(t, tlsServer.URL, httptester.WithHTTPClient(tlsServer.Client()))
+
+	c.GET("/").Do().Status(200).ProtoEq("HTTP/2.0")
+	c.GET("/").HTTP1().Do().Status(200).Eq("HTTP/1.1").ProtoEq("HTTP/1.1")
+	c.GET("/").HTTP2().Do().Status(200).Eq("HTTP/2.0").ExpectProtoAtLeast(2, 0).ALPN("h2")
+
+	var errs []error
+	onError := func(err error) {
+		errs = append(errs, err)
+	}
+
+	res := httptester.NewReqBuilder(tlsServer.URL, tlsServer.Client(), onError).GET("/").HTTP1().Do().
+		ProtoEq("HTTP/2.0").
+		ExpectProtoAtLeast(2, 0)
+	if res.ProtoAtLeast(2, 0) || !res.ProtoAtLeast(1, 1) {
+		t.Fatalf("unexpected protocol %s", res.Proto)
+	}
+
+	if len(errs) != 2 || !strings.Contains(errs[0].Error(), "expected protocol HTTP/2.0 got HTTP/1.1") || !strings.Contains(errs[1].Error(), "expected protocol at least HTTP/2.0 got HTTP/1.1") {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+}
+
+type http3Transport struct {
+	handler http.Handler
+}
+
+// RoundTrip serves the request with the handler as if it was received over
+// HTTP/3.
+func (t http3Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/3.0", 3, 0
+	rec := httptest.NewRecorder()
+	t.handler.ServeHTTP(rec, req)
+	res := rec.Result()
+	res.Proto, res.ProtoMajor, res.ProtoMinor = "HTTP/3.0", 3, 0
+	res.Request = req
+	return res, nil
+}
+
+func TestReqBuilderTransport(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/disabled" {
+			w.Header().Set("Alt-Svc", "clear")
+		} else {
+			w.Header().Set("Alt-Svc", `h3=":443"; ma=86400, h3-29=":443"`)
+		}
+		w.Write([]byte(r.Proto))
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	c.GET("/").Do().Status(200).Eq("HTTP/1.1").AltSvc("h3").AltSvc("h3-29")
+	c.GET("/").Transport(http3Transport{handler}).Do().Status(200).Eq("HTTP/3.0").ProtoEq("HTTP/3.0")
+	c.GET("/disabled").Do().Status(200).Eq("HTTP/1.1").AltSvcClear()
+
+	var errs []error
+	onError := func(err error) {
+		errs = append(errs, err)
+	}
+
+	httptester.NewReqBuilder(server.URL, http.DefaultClient, onError).GET("/").Do().AltSvc("h2")
+	httptester.NewReqBuilder(server.URL, http.DefaultClient, onError).GET("/").Do().AltSvcClear()
+	httptester.NewReqBuilder(server.URL, http.DefaultClient, onError).GET("/").Transport(http3Transport{handler}).InsecureTLS().Do()
+
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		"expected Alt-Svc to advertise h2",
+		`expected Alt-Svc clear got "h3=\":443\"; ma=86400, h3-29=\":443\""`,
+		"per-request transport options require an *http.Transport got httptester_test.http3Transport",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package httptester
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Proxy sends the request through the proxy at proxyURL instead of the
+// proxy from the environment. The http, https, socks5 and socks5h schemes
+// are supported and credentials may be set in the URL's user info.
+func (b *ReqBuilder) Proxy(proxyURL string) *ReqBuilder {
+	b.helper()
+
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		b.onError(fmt.Errorf("invalid proxy URL: %w", err))
+		return b
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		b.onError(fmt.Errorf("unsupported proxy scheme %q", u.Scheme))
+		return b
+	}
+
+	return b.transportOption(func(t *http.Transport) {
+		t.Proxy = http.ProxyURL(u)
+	})
+}
@@ -0,0 +1,114 @@
+package httptester_test
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+// socks5Proxy starts a minimal SOCKS5 proxy supporting CONNECT without
+// authentication and counts the connections it proxied.
+func socks5Proxy(t *testing.T) (string, *int32) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	var count int32
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+
+				buf := make([]byte, 262)
+				// Greeting: version, number of methods, methods.
+				if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+					return
+				}
+				io.ReadFull(conn, buf[:buf[1]])
+				conn.Write([]byte{5, 0})
+
+				// Request: version, command, reserved, address type.
+				if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+					return
+				}
+				var host string
+				switch buf[3] {
+				case 1:
+					io.ReadFull(conn, buf[:4])
+					host = net.IP(buf[:4]).String()
+				case 3:
+					io.ReadFull(conn, buf[:1])
+					n := int(buf[0])
+					io.ReadFull(conn, buf[:n])
+					host = string(buf[:n])
+				default:
+					return
+				}
+				io.ReadFull(conn, buf[:2])
+				port := binary.BigEndian.Uint16(buf[:2])
+
+				target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(port))))
+				if err != nil {
+					conn.Write([]byte{5, 1, 0, 1, 0, 0, 0, 0, 0, 0})
+					return
+				}
+				defer target.Close()
+				atomic.AddInt32(&count, 1)
+
+				conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+				go io.Copy(target, conn)
+				io.Copy(conn, target)
+			}()
+		}
+	}()
+
+	return l.Addr().String(), &count
+}
+
+func TestReqBuilderProxy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("direct"))
+	}))
+	defer server.Close()
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set("Authorization", r.Header.Get("Proxy-Authorization"))
+		user, pass, _ := r.BasicAuth()
+		w.Write([]byte("proxied " + r.URL.String() + " " + user + ":" + pass))
+	}))
+	defer proxy.Close()
+
+	c := httptester.New(t, "http://api.example.com")
+
+	c.GET("/users").Proxy(proxy.URL).Do().Status(200).Eq("proxied http://api.example.com/users :")
+	c.GET("/users").Proxy("http://ann:secret@" + proxy.Listener.Addr().String()).Do().Status(200).Eq("proxied http://api.example.com/users ann:secret")
+
+	socksAddr, count := socks5Proxy(t)
+
+	httptester.New(t, server.URL).GET("/").Proxy("socks5://" + socksAddr).Do().Status(200).Eq("direct")
+	if atomic.LoadInt32(count) != 1 {
+		t.Fatalf("expected 1 SOCKS5 connection got %d", atomic.LoadInt32(count))
+	}
+
+	var errs []error
+	httptester.NewClient(httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	})).GET("/").Proxy("ftp://proxy")
+	if len(errs) != 1 || errs[0].Error() != `unsupported proxy scheme "ftp"` {
+		t.Fatalf("expected unsupported scheme error got %v", errs)
+	}
+}
@@ -0,0 +1,123 @@
+package httptester
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Range requests the bytes from start to end inclusive. A negative end
+// requests everything from start.
+func (b *ReqBuilder) Range(start int64, end int64) *ReqBuilder {
+	if end < 0 {
+		return b.Header("Range", fmt.Sprintf("bytes=%d-", start))
+	}
+	return b.Header("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+}
+
+// contentRange parses the Content-Range of a 206 response. size is -1 when
+// the complete length is unknown (*).
+func (r *Response) contentRange(assertion string) (start int64, end int64, size int64, ok bool) {
+	r.helper()
+
+	if r.StatusCode != http.StatusPartialContent {
+		r.err(&StatusError{Expected: []int{http.StatusPartialContent}, Actual: r.StatusCode, Body: r.Body})
+		return 0, 0, 0, false
+	}
+
+	header := r.Header.Get("Content-Range")
+	invalid := func() (int64, int64, int64, bool) {
+		r.err(r.headerError(assertion, "Content-Range", "", "invalid Content-Range %q", header))
+		return 0, 0, 0, false
+	}
+
+	spec, found := strings.CutPrefix(header, "bytes ")
+	if !found {
+		return invalid()
+	}
+	rng, length, found := strings.Cut(spec, "/")
+	if !found {
+		return invalid()
+	}
+	first, last, found := strings.Cut(rng, "-")
+	if !found {
+		return invalid()
+	}
+
+	var err error
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return invalid()
+	}
+	if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+		return invalid()
+	}
+	size = -1
+	if length != "*" {
+		if size, err = strconv.ParseInt(length, 10, 64); err != nil || end >= size {
+			return invalid()
+		}
+	}
+
+	if n := end - start + 1; int64(len(r.Body)) != n {
+		r.err(fmt.Errorf("Content-Range %s: expected %d bytes got %d", header, n, len(r.Body)))
+		return 0, 0, 0, false
+	}
+
+	return start, end, size, true
+}
+
+// ContentRange checks that the response is 206 Partial Content with the
+// bytes from start to end inclusive of a representation of size bytes (-1
+// if the server may not know the size) and that the body has that length.
+func (r *Response) ContentRange(start int64, end int64, size int64) *Response {
+	r.helper()
+	defer r.track("ContentRange", start, end, size)()
+
+	actualStart, actualEnd, actualSize, ok := r.contentRange("ContentRange")
+	if !ok {
+		return r
+	}
+
+	if actualStart != start || actualEnd != end || (size >= 0 && actualSize != size) {
+		expected := fmt.Sprintf("bytes %d-%d/%s", start, end, rangeSize(size))
+		r.err(r.headerError("ContentRange", "Content-Range", expected, "expected Content-Range %s got %q", expected, r.Header.Get("Content-Range")))
+	}
+
+	return r
+}
+
+func rangeSize(size int64) string {
+	if size < 0 {
+		return "*"
+	}
+	return strconv.FormatInt(size, 10)
+}
+
+// MatchesRange checks that the 206 response body is the slice of full, a
+// complete download of the same resource, described by Content-Range.
+func (r *Response) MatchesRange(full []byte) *Response {
+	r.helper()
+	defer r.track("MatchesRange")()
+
+	start, end, size, ok := r.contentRange("MatchesRange")
+	if !ok {
+		return r
+	}
+
+	if size >= 0 && size != int64(len(full)) {
+		r.err(fmt.Errorf("Content-Range size %d does not match full length %d", size, len(full)))
+		return r
+	}
+	if end >= int64(len(full)) {
+		r.err(fmt.Errorf("Content-Range bytes %d-%d exceed full length %d", start, end, len(full)))
+		return r
+	}
+
+	if !bytes.Equal(r.Body, full[start:end+1]) {
+		r.err(fmt.Errorf("body does not match bytes %d-%d of the full download", start, end))
+	}
+
+	return r
+}
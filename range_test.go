@@ -0,0 +1,62 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bancek/httptester"
+)
+
+func TestReqBuilderRange(t *testing.T) {
+	content := "0123456789abcdefghij"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.Header().Set("Content-Range", "bytes 0-3/20")
+			w.WriteHeader(206)
+			w.Write([]byte("abcd"))
+			return
+		}
+		http.ServeContent(w, r, "data.bin", time.Time{}, strings.NewReader(content))
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	full := c.GET("/").Do().Status(200).Body
+
+	c.GET("/").Range(5, 9).Do().
+		Status(206).
+		ContentRange(5, 9, 20).
+		Eq("56789").
+		MatchesRange(full)
+
+	c.GET("/").Range(15, -1).Do().
+		ContentRange(15, 19, -1).
+		MatchesRange(full)
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.GET("/").Do().ContentRange(0, 3, 20)
+	b.GET("/").Range(0, 3).Do().ContentRange(0, 4, 20)
+	b.GET("/broken").Range(0, 3).Do().MatchesRange(full)
+
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		"expected status [206] got 200",
+		`expected Content-Range bytes 0-4/20 got "bytes 0-3/20"`,
+		"body does not match bytes 0-3 of the full download",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package httptester
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RedactedValue replaces redacted secrets.
+const RedactedValue = "<redacted>"
+
+// minSecretLen is the minimum length of secret values replaced in failure
+// messages, shorter values would replace unrelated text.
+const minSecretLen = 4
+
+// Redactor masks secrets in curl commands, dumps, HAR files and failure
+// messages of builders it is attached to (see ReqBuilder.Redact). Values of
+// redacted headers, JSON values at redacted paths of bodies and matches of
+// redacted patterns are replaced with RedactedValue.
+type Redactor struct {
+	headers  map[string]bool
+	paths    []string
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor returns a Redactor of the Authorization, Proxy-Authorization,
+// Cookie and Set-Cookie headers.
+func NewRedactor() *Redactor {
+	return (&Redactor{headers: map[string]bool{}}).
+		Headers("Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie")
+}
+
+// Headers redacts the values of the headers.
+func (r *Redactor) Headers(names ...string) *Redactor {
+	for _, name := range names {
+		r.headers[http.CanonicalHeaderKey(name)] = true
+	}
+	return r
+}
+
+// JSONPaths redacts the values at the paths of JSON bodies. Paths are
+// JSONPaths as in Response.Mask. It panics if a path is invalid.
+func (r *Redactor) JSONPaths(paths ...string) *Redactor {
+	for _, path := range paths {
+		if _, err := parseMaskPath(path); err != nil {
+			panic(err)
+		}
+	}
+	r.paths = append(r.paths, paths...)
+	return r
+}
+
+// Patterns redacts matches of the regular expressions, or only their
+// submatches if they have groups, e.g. `token=(\w+)`. It panics if an
+// expression is invalid.
+func (r *Redactor) Patterns(exprs ...string) *Redactor {
+	for _, expr := range exprs {
+		r.patterns = append(r.patterns, regexp.MustCompile(expr))
+	}
+	return r
+}
+
+// header returns a copy of h with the values of redacted headers replaced.
+// The scheme of authorization headers and the names and attributes of
+// cookies are kept.
+func (r *Redactor) header(h http.Header) http.Header {
+	if r == nil {
+		return h
+	}
+
+	redacted := make(http.Header, len(h))
+	for k, vs := range h {
+		values := make([]string, len(vs))
+		for i, v := range vs {
+			if r.headers[k] {
+				v = redactHeaderValue(k, v)
+			}
+			values[i] = r.text(v)
+		}
+		redacted[k] = values
+	}
+	return redacted
+}
+
+func redactHeaderValue(key string, value string) string {
+	switch key {
+	case "Authorization", "Proxy-Authorization":
+		if scheme, _, ok := strings.Cut(value, " "); ok {
+			return scheme + " " + RedactedValue
+		}
+	case "Cookie":
+		cookies := strings.Split(value, ";")
+		for i, cookie := range cookies {
+			name, _, _ := strings.Cut(cookie, "=")
+			cookies[i] = name + "=" + RedactedValue
+		}
+		return strings.Join(cookies, ";")
+	case "Set-Cookie":
+		cookie, attrs, _ := strings.Cut(value, ";")
+		name, _, _ := strings.Cut(cookie, "=")
+		if attrs != "" {
+			return name + "=" + RedactedValue + ";" + attrs
+		}
+		return name + "=" + RedactedValue
+	}
+	return RedactedValue
+}
+
+// body returns body with the values at redacted JSON paths and matches of
+// the patterns replaced. Bodies that are not JSON keep their formatting.
+func (r *Redactor) body(body []byte) []byte {
+	if r == nil || len(body) == 0 {
+		return body
+	}
+
+	if len(r.paths) > 0 {
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err == nil {
+			for _, path := range r.paths {
+				groups, _ := parseMaskPath(path)
+				v = replaceJSONPath(v, groups, RedactedValue)
+			}
+			buf := &bytes.Buffer{}
+			enc := json.NewEncoder(buf)
+			enc.SetEscapeHTML(false)
+			if err := enc.Encode(v); err == nil {
+				body = bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+			}
+		}
+	}
+
+	if len(r.patterns) == 0 {
+		return body
+	}
+	return []byte(r.text(string(body)))
+}
+
+// text returns s with the matches of the patterns replaced.
+func (r *Redactor) text(s string) string {
+	if r == nil {
+		return s
+	}
+
+	for _, re := range r.patterns {
+		if re.NumSubexp() == 0 {
+			s = re.ReplaceAllLiteralString(s, RedactedValue)
+			continue
+		}
+
+		sb := &strings.Builder{}
+		last := 0
+		for _, match := range re.FindAllStringSubmatchIndex(s, -1) {
+			for g := 2; g < len(match); g += 2 {
+				start, end := match[g], match[g+1]
+				if start < last || start < 0 {
+					continue
+				}
+				sb.WriteString(s[last:start])
+				sb.WriteString(RedactedValue)
+				last = end
+			}
+		}
+		sb.WriteString(s[last:])
+		s = sb.String()
+	}
+
+	return s
+}
+
+// secrets returns the values of redacted headers and the strings at
+// redacted JSON paths of the bodies.
+func (r *Redactor) secrets(headers []http.Header, bodies [][]byte) []string {
+	var secrets []string
+	add := func(s string) {
+		if s = strings.TrimSpace(s); len(s) >= minSecretLen {
+			secrets = append(secrets, s)
+		}
+	}
+
+	for _, h := range headers {
+		for k, vs := range h {
+			if !r.headers[k] {
+				continue
+			}
+			for _, v := range vs {
+				switch k {
+				case "Authorization", "Proxy-Authorization":
+					_, credentials, _ := strings.Cut(v, " ")
+					add(credentials)
+				case "Cookie":
+					for _, cookie := range strings.Split(v, ";") {
+						_, value, _ := strings.Cut(cookie, "=")
+						add(value)
+					}
+				case "Set-Cookie":
+					cookie, _, _ := strings.Cut(v, ";")
+					_, value, _ := strings.Cut(cookie, "=")
+					add(value)
+				}
+				add(v)
+			}
+		}
+	}
+
+	for _, body := range bodies {
+		var v interface{}
+		if len(r.paths) == 0 || json.Unmarshal(body, &v) != nil {
+			continue
+		}
+		for _, path := range r.paths {
+			groups, _ := parseMaskPath(path)
+			walkJSONPath(v, groups, func(value interface{}) interface{} {
+				if s, ok := value.(string); ok {
+					add(s)
+				}
+				return value
+			})
+		}
+	}
+
+	return secrets
+}
+
+// message redacts a failure message of res: the secrets of its request and
+// response and the matches of the patterns.
+func (r *Redactor) message(msg string, res *Response) string {
+	if r == nil {
+		return msg
+	}
+
+	return r.text(scrub(msg, r.responseSecrets(res)))
+}
+
+func (r *Redactor) responseSecrets(res *Response) []string {
+	return r.secrets([]http.Header{res.req.Header, res.Header}, [][]byte{res.reqBody, res.Body})
+}
+
+// error redacts the message of a failure of res and the bodies and header
+// values of StatusError, BodyMismatchError and HeaderError failures, so
+// they are not exposed through errors.As either. Failures of other types
+// only have their message redacted.
+func (r *Redactor) error(err error, res *Response) error {
+	secrets := r.responseSecrets(res)
+	redact := func(s string) string {
+		return r.text(scrub(s, secrets))
+	}
+
+	switch e := err.(type) {
+	case *StatusError:
+		c := *e
+		c.Body = []byte(redact(string(r.body(e.Body))))
+		err = &c
+	case *BodyMismatchError:
+		c := *e
+		c.Expected = redact(string(r.body([]byte(e.Expected))))
+		c.Actual = []byte(redact(string(r.body(e.Actual))))
+		c.msg = redact(e.msg)
+		err = &c
+	case *HeaderError:
+		c := *e
+		key := http.CanonicalHeaderKey(e.Key)
+		c.Actual = make([]string, len(e.Actual))
+		for i, v := range e.Actual {
+			if r.headers[key] {
+				v = redactHeaderValue(key, v)
+			}
+			c.Actual[i] = redact(v)
+		}
+		if r.headers[key] && e.Expected != "" {
+			c.Expected = redactHeaderValue(key, e.Expected)
+		}
+		c.Expected = redact(c.Expected)
+		c.msg = redact(e.msg)
+		err = &c
+	}
+
+	return &redactedError{err: err, msg: redact(err.Error())}
+}
+
+// scrub replaces the secrets in s.
+func scrub(s string, secrets []string) string {
+	// Longer secrets first so a secret containing another is replaced whole.
+	sort.Slice(secrets, func(i, j int) bool {
+		return len(secrets[i]) > len(secrets[j])
+	})
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, RedactedValue)
+	}
+	return s
+}
+
+// redactedError is an error whose message was redacted.
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+func (b *ReqBuilder) Redact(redactor *Redactor) *ReqBuilder {
+	b.redactor = redactor
+	return b
+}
@@ -0,0 +1,127 @@
+package httptester_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestRedactor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3ss10nvalue", Path: "/"})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"user":"alice","token":"tok-abcdef","auth":"` + r.Header.Get("Authorization") + `"}`))
+	}))
+	defer server.Close()
+
+	redactor := httptester.NewRedactor().
+		Headers("X-Api-Key").
+		JSONPaths("$.token", "$.password").
+		Patterns(`api_key=(\w+)`)
+
+	recorder := httptester.NewHARRecorder()
+
+	var errs []error
+	b := httptester.NewClient(
+		httptester.WithBaseURL(server.URL),
+		httptester.WithOnError(func(err error) {
+			errs = append(errs, err)
+		}),
+		httptester.WithRedactor(redactor),
+	)
+
+	req := b.POST("/login").
+		Q("api_key", "querysecret").
+		Header("Authorization", "Bearer b3arertoken").
+		Header("X-Api-Key", "k3yvalue").
+		Header("Cookie", "a=c00kievalue; b=other").
+		Record(recorder).
+		Debug().
+		JSON(map[string]string{"user": "alice", "password": "p4ssword"})
+
+	curl := req.Curl()
+	res := req.Do().Contains("nope")
+
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error got %v", errs)
+	}
+
+	secrets := []string{"querysecret", "b3arertoken", "k3yvalue", "c00kievalue", "p4ssword", "tok-abcdef", "s3ss10nvalue"}
+	outputs := map[string]string{
+		"curl":  curl,
+		"error": errs[0].Error(),
+		"dump":  res.Dump(),
+	}
+	for _, entry := range recorder.Entries() {
+		outputs["har"] = entry.Request.URL + entry.Request.PostData.Text + entry.Response.Content.Text
+		for _, h := range append(entry.Request.Headers, entry.Response.Headers...) {
+			outputs["har"] += "\n" + h.Name + ": " + h.Value
+		}
+		for _, c := range append(entry.Request.Cookies, entry.Response.Cookies...) {
+			outputs["har"] += "\n" + c.Name + "=" + c.Value
+		}
+	}
+	if outputs["har"] == "" {
+		t.Fatal("expected a HAR entry")
+	}
+
+	for name, output := range outputs {
+		for _, secret := range secrets {
+			if strings.Contains(output, secret) {
+				t.Errorf("%s contains %s:\n%s", name, secret, output)
+			}
+		}
+	}
+
+	for _, expected := range []string{
+		"api_key=<redacted>",
+		"Authorization: Bearer <redacted>",
+		"Cookie: a=<redacted>; b=<redacted>",
+		"X-Api-Key: <redacted>",
+		`"password":"<redacted>"`,
+		"Set-Cookie: session=<redacted>; Path=/",
+		`"user": "alice"`,
+	} {
+		if !strings.Contains(outputs["error"], expected) {
+			t.Errorf("expected error to contain %q:\n%s", expected, outputs["error"])
+		}
+	}
+	if !strings.Contains(outputs["har"], "\nsession=<redacted>") {
+		t.Errorf("expected redacted HAR cookie:\n%s", outputs["har"])
+	}
+
+	if res.Header.Get("Set-Cookie") != "session=s3ss10nvalue; Path=/" {
+		t.Errorf("expected response to be unchanged got %s", res.Header.Get("Set-Cookie"))
+	}
+
+	res.Status(500).HeaderEq("Set-Cookie", "session=s3ss10nvalue")
+
+	var bodyErr *httptester.BodyMismatchError
+	var statusErr *httptester.StatusError
+	var headerErr *httptester.HeaderError
+	if len(errs) != 3 || !errors.As(errs[0], &bodyErr) || !errors.As(errs[1], &statusErr) || !errors.As(errs[2], &headerErr) {
+		t.Fatalf("expected typed errors got %v", errs)
+	}
+	fields := map[string]string{
+		"BodyMismatchError.Actual": string(bodyErr.Actual),
+		"StatusError.Body":         string(statusErr.Body),
+		"HeaderError.Expected":     headerErr.Expected,
+		"HeaderError.Actual":       strings.Join(headerErr.Actual, "\n"),
+	}
+	for name, field := range fields {
+		for _, secret := range secrets {
+			if strings.Contains(field, secret) {
+				t.Errorf("%s contains %s: %s", name, secret, field)
+			}
+		}
+	}
+	if !strings.Contains(fields["StatusError.Body"], `"user":"alice"`) {
+		t.Errorf("expected the rest of the body to be kept got %s", fields["StatusError.Body"])
+	}
+}
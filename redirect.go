@@ -0,0 +1,66 @@
+package httptester
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+type Redirect struct {
+	URL        *url.URL
+	StatusCode int
+	Location   string
+}
+
+// Redirects returns the redirect responses that were followed to get to this
+// response, in order.
+func (r *Response) Redirects() []Redirect {
+	redirects := []Redirect{}
+
+	for req := r.Response.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		res := req.Response
+		redirects = append([]Redirect{{
+			URL:        res.Request.URL,
+			StatusCode: res.StatusCode,
+			Location:   res.Header.Get("Location"),
+		}}, redirects...)
+	}
+
+	return redirects
+}
+
+// urlMatches compares absolute URLs fully and paths (starting with /) against
+// the URL path only.
+func urlMatches(u *url.URL, expected string) bool {
+	if strings.HasPrefix(expected, "/") {
+		return u.Path == expected
+	}
+	return u.String() == expected
+}
+
+func (r *Response) RedirectedVia(status int, url string) *Response {
+	r.helper()
+	defer r.track("RedirectedVia", status, url)()
+
+	chain := []string{}
+	for _, redirect := range r.Redirects() {
+		if redirect.StatusCode == status && urlMatches(redirect.URL, url) {
+			return r
+		}
+		chain = append(chain, fmt.Sprintf("%d %s", redirect.StatusCode, redirect.URL))
+	}
+
+	r.err(fmt.Errorf("expected redirect %d via %s, got redirects %v", status, url, chain))
+	return r
+}
+
+func (r *Response) FinalURL(url string) *Response {
+	r.helper()
+	defer r.track("FinalURL", url)()
+
+	if !urlMatches(r.URL, url) {
+		r.err(fmt.Errorf("expected final URL %s got %s", url, r.URL))
+	}
+
+	return r
+}
@@ -3,43 +3,109 @@ package httptester
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
+	"regexp"
 	"strings"
+	"time"
 )
 
 type ReqBuilder struct {
-	baseURL       string
-	url           string
-	method        string
-	query         url.Values
-	headers       http.Header
-	noFollow      bool
-	body          io.Reader
-	client        *http.Client
-	beforeRequest func(req *http.Request) *http.Request
-	afterRequest  func(req *http.Request, res *http.Response, err error)
-	context       context.Context
-	onError       func(error)
+	baseURL         string
+	url             string
+	method          string
+	query           url.Values
+	params          map[string]string
+	pathTemplate    bool
+	vars            *Vars
+	headers         http.Header
+	noFollow        bool
+	debug           bool
+	body            io.Reader
+	bodyBytes       []byte
+	client          *http.Client
+	jar             http.CookieJar
+	harRecorder     *HARRecorder
+	harReplayer     *HARReplayer
+	cassette        *Cassette
+	pact            *Pact
+	pactDescription string
+	pactStates      []string
+	metrics         *Metrics
+	tracer          Tracer
+	faults          *FaultInjector
+	breaker         *CircuitBreaker
+	coverage        *Coverage
+	digest          *digestAuth
+	oauth2          *OAuth2
+	sign            func(req *http.Request, body []byte)
+	transportOpts   []func(t *http.Transport)
+	roundTripper    http.RoundTripper
+	bodyEncoding    string
+	reporter        *JUnitReporter
+	failures        *FailureReport
+	redactor        *Redactor
+	requestIDHeader string
+	traceContext    *TraceContext
+	middleware      []Middleware
+	context         context.Context
+	timeout         time.Duration
+	retryPolicy     *RetryPolicy
+	throttle        *throttlePolicy
+	onError         func(error)
+	helper          func()
 }
 
 func NewReqBuilder(baseURL string, client *http.Client, onError func(error)) *ReqBuilder {
 	return &ReqBuilder{
 		baseURL: baseURL,
 		query:   url.Values{},
+		params:  map[string]string{},
 		headers: http.Header{},
 		client:  client,
 		onError: onError,
+		helper:  func() {},
 	}
 }
 
+// Clone returns an independent copy of the builder so a builder configured
+// with common headers and auth can be used as a template for many requests.
+func (b *ReqBuilder) Clone() *ReqBuilder {
+	b.helper()
+
+	// The body is buffered so that both builders can send it.
+	if _, err := b.readBody(); err != nil {
+		b.onError(err)
+	}
+
+	c := *b
+
+	c.query = url.Values{}
+	for k, vs := range b.query {
+		c.query[k] = append([]string(nil), vs...)
+	}
+
+	c.headers = b.headers.Clone()
+
+	c.params = make(map[string]string, len(b.params))
+	for k, v := range b.params {
+		c.params[k] = v
+	}
+
+	return &c
+}
+
 func (b *ReqBuilder) Method(method string, url string) *ReqBuilder {
 	b.method = method
 	b.url = url
+	b.pathTemplate = false
 	return b
 }
 
@@ -59,11 +125,54 @@ func (b *ReqBuilder) DELETE(url string) *ReqBuilder {
 	return b.Method("DELETE", url)
 }
 
+func (b *ReqBuilder) PATCH(url string) *ReqBuilder {
+	return b.Method("PATCH", url)
+}
+
+func (b *ReqBuilder) HEAD(url string) *ReqBuilder {
+	return b.Method("HEAD", url)
+}
+
+func (b *ReqBuilder) OPTIONS(url string) *ReqBuilder {
+	return b.Method("OPTIONS", url)
+}
+
+func (b *ReqBuilder) TRACE(url string) *ReqBuilder {
+	return b.Method("TRACE", url)
+}
+
+// Path sets a URL template such as /users/{id}. Placeholders in the path are
+// replaced by values set with Param, each escaped as a single path segment,
+// and placeholders without a value are an error. URLs set with GET and the
+// other methods only have the placeholders of set params replaced.
+func (b *ReqBuilder) Path(path string) *ReqBuilder {
+	b.url = path
+	b.pathTemplate = true
+	return b
+}
+
+func (b *ReqBuilder) Param(args ...string) *ReqBuilder {
+	for i := 0; i < len(args)/2; i++ {
+		b.params[args[i*2]] = args[i*2+1]
+	}
+	return b
+}
+
+func (b *ReqBuilder) Vars(vars *Vars) *ReqBuilder {
+	b.vars = vars
+	return b
+}
+
 func (b *ReqBuilder) NoFollow() *ReqBuilder {
 	b.noFollow = true
 	return b
 }
 
+func (b *ReqBuilder) Debug() *ReqBuilder {
+	b.debug = true
+	return b
+}
+
 func (b *ReqBuilder) Q(args ...string) *ReqBuilder {
 	keys := map[string]bool{}
 	for i := 0; i < len(args)/2; i++ {
@@ -79,6 +188,26 @@ func (b *ReqBuilder) Q(args ...string) *ReqBuilder {
 	return b
 }
 
+// QStruct adds query parameters from the fields of a struct using `url`
+// tags, e.g. `url:"status,omitempty"`. Slices produce repeated parameters.
+func (b *ReqBuilder) QStruct(v interface{}) *ReqBuilder {
+	b.helper()
+
+	values, err := structValues(v, "url")
+	if err != nil {
+		b.onError(err)
+		return b
+	}
+
+	for k, vs := range values {
+		for _, value := range vs {
+			b.query.Add(k, value)
+		}
+	}
+
+	return b
+}
+
 func (b *ReqBuilder) Header(args ...string) *ReqBuilder {
 	for i := 0; i < len(args)/2; i++ {
 		b.headers.Set(args[i*2], args[i*2+1])
@@ -101,6 +230,7 @@ func (b *ReqBuilder) Basic(username string, password string) *ReqBuilder {
 
 func (b *ReqBuilder) Body(reader io.Reader) *ReqBuilder {
 	b.body = reader
+	b.bodyBytes = nil
 	return b
 }
 
@@ -113,7 +243,24 @@ func (b *ReqBuilder) Form(args ...string) *ReqBuilder {
 	return b.Body(strings.NewReader(q.Encode()))
 }
 
+// FormStruct encodes the fields of a struct as an
+// application/x-www-form-urlencoded body using `form` tags.
+func (b *ReqBuilder) FormStruct(v interface{}) *ReqBuilder {
+	b.helper()
+
+	values, err := structValues(v, "form")
+	if err != nil {
+		b.onError(err)
+		return b
+	}
+
+	b.Header("Content-Type", "application/x-www-form-urlencoded")
+	return b.Body(strings.NewReader(values.Encode()))
+}
+
 func (b *ReqBuilder) JSON(j interface{}) *ReqBuilder {
+	b.helper()
+
 	b.Header("Content-Type", "application/json")
 	jsonBytes, err := json.Marshal(j)
 	if err != nil {
@@ -167,11 +314,42 @@ func (b *ReqBuilder) File(fieldName string, fileName string, reader io.Reader, e
 	return b.Body(r)
 }
 
+// OnError replaces the error handler, including one inherited from a
+// Client. Use WrapOnError to extend it instead.
 func (b *ReqBuilder) OnError(f func(error)) *ReqBuilder {
 	b.onError = f
 	return b
 }
 
+// WrapOnError replaces the error handler with wrap(current), so the handler
+// inherited from a Client can be extended, e.g. to log errors before they
+// are reported:
+//
+//	b.WrapOnError(func(next func(error)) func(error) {
+//		return func(err error) {
+//			log.Print(err)
+//			next(err)
+//		}
+//	})
+func (b *ReqBuilder) WrapOnError(wrap func(next func(error)) func(error)) *ReqBuilder {
+	b.onError = wrap(b.onError)
+	return b
+}
+
+func (b *ReqBuilder) Soft(s *SoftAssertions) *ReqBuilder {
+	return b.OnError(s.OnError)
+}
+
+// Helper sets a function (usually testing.TB.Helper) that is called by every
+// method that can report an error so that failures point at the test line.
+func (b *ReqBuilder) Helper(f func()) *ReqBuilder {
+	if f == nil {
+		f = func() {}
+	}
+	b.helper = f
+	return b
+}
+
 func (b *ReqBuilder) BeforeRequest(f func(req *http.Request)) *ReqBuilder {
 	return b.BeforeWithRequest(func(req *http.Request) *http.Request {
 		f(req)
@@ -180,12 +358,85 @@ func (b *ReqBuilder) BeforeRequest(f func(req *http.Request)) *ReqBuilder {
 }
 
 func (b *ReqBuilder) BeforeWithRequest(f func(req *http.Request) *http.Request) *ReqBuilder {
-	b.beforeRequest = f
+	return b.Use(func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			return next(f(req))
+		}
+	})
+}
+
+// Sign calls f with the final request and body bytes right before every
+// attempt is sent, after BeforeRequest, so f can add signature headers
+// computed over the body (e.g. X-Hub-Signature-256). The body must not be
+// modified.
+func (b *ReqBuilder) Sign(f func(req *http.Request, body []byte)) *ReqBuilder {
+	b.sign = f
 	return b
 }
 
 func (b *ReqBuilder) AfterRequest(f func(req *http.Request, res *http.Response, err error)) *ReqBuilder {
-	b.afterRequest = f
+	return b.Use(func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			res, err := next(req)
+			f(req, res, err)
+			return res, err
+		}
+	})
+}
+
+func (b *ReqBuilder) Jar() *ReqBuilder {
+	b.helper()
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		b.onError(err)
+		return b
+	}
+	return b.WithCookieJar(jar)
+}
+
+func (b *ReqBuilder) WithCookieJar(jar http.CookieJar) *ReqBuilder {
+	b.jar = jar
+	return b
+}
+
+func (b *ReqBuilder) Record(recorder *HARRecorder) *ReqBuilder {
+	b.harRecorder = recorder
+	return b
+}
+
+func (b *ReqBuilder) Replay(replayer *HARReplayer) *ReqBuilder {
+	b.harReplayer = replayer
+	return b
+}
+
+func (b *ReqBuilder) Metrics(metrics *Metrics) *ReqBuilder {
+	b.metrics = metrics
+	return b
+}
+
+func (b *ReqBuilder) Cassette(cassette *Cassette) *ReqBuilder {
+	b.cassette = cassette
+	return b
+}
+
+func (b *ReqBuilder) Coverage(coverage *Coverage) *ReqBuilder {
+	b.coverage = coverage
+	return b
+}
+
+// Faults injects faults into the requests, see FaultInjector.
+func (b *ReqBuilder) Faults(injector *FaultInjector) *ReqBuilder {
+	b.faults = injector
+	return b
+}
+
+func (b *ReqBuilder) Retry(n int) *ReqBuilder {
+	return b.RetryPolicy(DefaultRetryPolicy(n))
+}
+
+func (b *ReqBuilder) RetryPolicy(policy RetryPolicy) *ReqBuilder {
+	b.retryPolicy = &policy
 	return b
 }
 
@@ -194,17 +445,128 @@ func (b *ReqBuilder) Context(ctx context.Context) *ReqBuilder {
 	return b
 }
 
-func (b *ReqBuilder) Do() *Response {
-	u, err := url.Parse(b.baseURL + b.url)
+func (b *ReqBuilder) Timeout(d time.Duration) *ReqBuilder {
+	b.timeout = d
+	return b
+}
+
+// readBody buffers the request body so it can be sent more than once (for
+// retries) and rendered in curl commands.
+func (b *ReqBuilder) readBody() ([]byte, error) {
+	if b.body != nil {
+		data, err := io.ReadAll(b.body)
+		if err != nil {
+			return nil, err
+		}
+		b.body = nil
+		b.bodyBytes = data
+	}
+
+	return b.bodyBytes, nil
+}
+
+func (b *ReqBuilder) Curl() string {
+	b.helper()
+
+	u, err := b.buildURL()
 	if err != nil {
 		b.onError(err)
-		return nil
+		return ""
+	}
+
+	body, err := b.requestBody()
+	if err != nil {
+		b.onError(err)
+		return ""
+	}
+
+	req, err := b.newRequest(context.Background(), u, nil)
+	if err != nil {
+		b.onError(err)
+		return ""
+	}
+
+	return b.curl(req.Method, u.String(), req.Header, body)
+}
+
+// requestBody returns the buffered body with variables expanded.
+func (b *ReqBuilder) requestBody() ([]byte, error) {
+	body, err := b.readBody()
+	if err != nil || body == nil || b.vars == nil {
+		return body, err
+	}
+
+	expanded, err := b.vars.expand(string(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(expanded), nil
+}
+
+func (b *ReqBuilder) expandVars(s string) (string, error) {
+	if b.vars == nil {
+		return s, nil
+	}
+	return b.vars.expand(s)
+}
+
+var pathParamRegexp = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+// expandPath expands the variables of the URL and replaces placeholders in
+// its path with the values set with Param. The query and fragment are kept
+// as they are, so braces there are not mistaken for placeholders. Unknown
+// placeholders are an error in templates set with Path and kept literally
+// otherwise.
+func (b *ReqBuilder) expandPath() (string, error) {
+	u, err := b.expandVars(b.url)
+	if err != nil {
+		return "", err
+	}
+
+	path, rest := u, ""
+	if i := strings.IndexAny(u, "?#"); i >= 0 {
+		path, rest = u[:i], u[i:]
+	}
+
+	var missing []string
+
+	path = pathParamRegexp.ReplaceAllStringFunc(path, func(m string) string {
+		name := m[1 : len(m)-1]
+		value, ok := b.params[name]
+		if !ok {
+			missing = append(missing, name)
+			return m
+		}
+		return url.PathEscape(value)
+	})
+
+	if len(missing) > 0 && b.pathTemplate {
+		return "", fmt.Errorf("missing path parameters %s for %s", strings.Join(missing, ", "), b.url)
+	}
+
+	return path + rest, nil
+}
+
+func (b *ReqBuilder) buildURL() (*url.URL, error) {
+	path, err := b.expandPath()
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(b.baseURL + path)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(b.query) > 0 {
 		q := u.Query()
 		for k, vs := range b.query {
 			for _, v := range vs {
+				v, err := b.expandVars(v)
+				if err != nil {
+					return nil, err
+				}
 				q.Add(k, v)
 			}
 		}
@@ -212,51 +574,348 @@ func (b *ReqBuilder) Do() *Response {
 		u.RawQuery = q.Encode()
 	}
 
-	ctx := b.context
-	if ctx == nil {
-		ctx = context.Background()
-	}
+	return u, nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, b.method, u.String(), b.body)
+func (b *ReqBuilder) newRequest(ctx context.Context, u *url.URL, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, b.method, u.String(), body)
 	if err != nil {
-		b.onError(err)
-		return nil
+		return nil, err
 	}
 
 	for k, vs := range b.headers {
 		for _, v := range vs {
+			v, err := b.expandVars(v)
+			if err != nil {
+				return nil, err
+			}
 			req.Header.Add(k, v)
 		}
 	}
 
-	if host := b.headers.Get("Host"); host != "" {
+	if host := req.Header.Get("Host"); host != "" {
 		req.Host = host
 	}
 
-	oldCheckRedirect := b.client.CheckRedirect
+	return req, nil
+}
+
+// endpoint returns the URL template without the query, used to label
+// metrics and spans.
+func (b *ReqBuilder) endpoint() string {
+	endpoint, _, _ := strings.Cut(b.url, "?")
+	return endpoint
+}
+
+// httpClient returns the client to use for this request. Per-request options
+// are applied to a shallow copy so the shared client is never mutated.
+func (b *ReqBuilder) httpClient() *http.Client {
+	if b.jar == nil && !b.noFollow && b.harRecorder == nil && b.harReplayer == nil && b.cassette == nil && b.faults == nil && b.breaker == nil && b.digest == nil && b.oauth2 == nil && len(b.transportOpts) == 0 && b.roundTripper == nil {
+		return b.client
+	}
+
+	client := *b.client
+
+	if b.roundTripper != nil {
+		client.Transport = b.roundTripper
+	}
+
+	if len(b.transportOpts) > 0 {
+		client.Transport = b.transport(client.Transport)
+	}
 
 	if b.noFollow {
-		b.client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		}
 	}
 
-	if b.beforeRequest != nil {
-		req = b.beforeRequest(req)
+	if b.jar != nil {
+		client.Jar = b.jar
+	}
+
+	if b.harReplayer != nil {
+		client.Transport = b.harReplayer
+	}
+
+	if b.cassette != nil {
+		client.Transport = b.cassette.transport(client.Transport, b.redactor)
+	}
+
+	if b.faults != nil {
+		client.Transport = b.faults.Transport(client.Transport)
+	}
+
+	if b.breaker != nil {
+		client.Transport = b.breaker.Transport(client.Transport)
+	}
+
+	if b.harRecorder != nil {
+		client.Transport = b.harRecorder.transport(client.Transport, b.redactor)
+	}
+
+	if b.digest != nil {
+		client.Transport = b.digest.Transport(client.Transport)
+	}
+
+	if b.oauth2 != nil {
+		client.Transport = b.oauth2.Transport(client.Transport)
+	}
+
+	return &client
+}
+
+// transportOption adds an option applied to a clone of the client's
+// http.Transport used for this request only.
+func (b *ReqBuilder) transportOption(f func(t *http.Transport)) *ReqBuilder {
+	// Cloned builders share the backing array, so always copy it.
+	b.transportOpts = append(b.transportOpts[:len(b.transportOpts):len(b.transportOpts)], f)
+	return b
+}
+
+// transport returns a clone of base with the transport options applied.
+// Keep-alives are disabled because the transport is not reused by other
+// requests and its idle connections would never be closed.
+func (b *ReqBuilder) transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	t, ok := base.(*http.Transport)
+	if !ok {
+		return errorTransport{fmt.Errorf("per-request transport options require an *http.Transport got %T", base)}
+	}
+
+	t = t.Clone()
+	t.DisableKeepAlives = true
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+
+	for _, opt := range b.transportOpts {
+		opt(t)
+	}
+
+	return t
+}
+
+type errorTransport struct {
+	err error
+}
+
+func (t errorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, t.err
+}
+
+func (b *ReqBuilder) requestContext() (context.Context, context.CancelFunc) {
+	ctx := b.context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if b.timeout > 0 {
+		return context.WithTimeout(ctx, b.timeout)
+	}
+
+	return context.WithCancel(ctx)
+}
+
+// sentRequest is the final attempt of a sent request with the response body
+// not yet read.
+type sentRequest struct {
+	req     *http.Request
+	res     *http.Response
+	body    []byte
+	start   time.Time
+	timings *timingsTrace
+	// throttleDelays are the Retry-After delays waited, see
+	// ReqBuilder.RetryAfter.
+	throttleDelays []time.Duration
+}
+
+// send performs the request (including retries) and returns the response
+// with its body unread along with the request body that was sent.
+func (b *ReqBuilder) send(ctx context.Context) (*sentRequest, error) {
+	u, err := b.buildURL()
+	if err != nil {
+		return nil, err
+	}
+
+	bodyBytes, err := b.requestBody()
+	if err != nil {
+		return nil, err
+	}
+
+	// wireBytes is the body as sent, bodyBytes is kept for curl commands,
+	// dumps and contracts.
+	wireBytes := bodyBytes
+	if b.bodyEncoding != "" && bodyBytes != nil {
+		wireBytes, err = encodeContent(bodyBytes, b.bodyEncoding)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	client := b.httpClient()
+
+	var req *http.Request
+	var res *http.Response
+	var start time.Time
+	var timings *timingsTrace
+
+	if b.tracer != nil {
+		var span Span
+		ctx, span = b.startSpan(ctx, u)
+		defer func() {
+			endSpan(span, res, err)
+		}()
+	}
+
+	var requestID string
+	if b.requestIDHeader != "" {
+		requestID = newRequestID()
 	}
 
-	res, err := b.client.Do(req)
+	var throttleDelays []time.Duration
 
-	if b.afterRequest != nil {
-		b.afterRequest(req, res, err)
+	for attempt := 0; ; {
+		var body io.Reader
+		if wireBytes != nil {
+			body = bytes.NewReader(wireBytes)
+		}
+
+		var traceCtx context.Context
+		traceCtx, timings = withTimingsTrace(ctx)
+
+		req, err = b.newRequest(traceCtx, u, body)
+		if err != nil {
+			break
+		}
+
+		if requestID != "" {
+			if id := req.Header.Get(b.requestIDHeader); id != "" {
+				requestID = id
+			} else {
+				req.Header.Set(b.requestIDHeader, requestID)
+			}
+		}
+
+		if b.traceContext != nil {
+			tc := b.traceContext.child()
+			req.Header.Set("traceparent", tc.TraceParent())
+			if tc.State != "" {
+				req.Header.Set("tracestate", tc.State)
+			}
+		}
+
+		if b.tracer != nil {
+			b.tracer.Inject(ctx, req.Header)
+		}
+
+		start = time.Now()
+		res, err = b.roundTrip(req, func(r *http.Request) (*http.Response, error) {
+			req = r
+
+			if b.sign != nil {
+				b.sign(req, wireBytes)
+			}
+
+			start = time.Now()
+			return client.Do(req)
+		})
+		if res != nil && res.Request == nil {
+			// Responses returned by middleware without sending.
+			res.Request = req
+		}
+
+		if b.metrics != nil {
+			b.metrics.observe(req.Method, b.endpoint(), res, err, time.Since(start))
+		}
+
+		if b.coverage != nil && err == nil {
+			b.coverage.add(req.Method, req.URL.Path, res.StatusCode)
+		}
+
+		var delay time.Duration
+		if d, ok := b.throttle.delay(res, len(throttleDelays), time.Now()); ok {
+			delay = d
+			throttleDelays = append(throttleDelays, d)
+		} else if b.retryPolicy != nil && attempt < b.retryPolicy.MaxRetries && b.retryPolicy.shouldRetry(res, err) {
+			delay = b.retryPolicy.backoff(attempt)
+			attempt++
+		} else {
+			break
+		}
+
+		if res != nil {
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+			res = nil
+		}
+
+		if err = sleepContext(ctx, delay); err != nil {
+			break
+		}
 	}
 
-	b.client.CheckRedirect = oldCheckRedirect
+	if err != nil {
+		if requestID != "" {
+			err = fmt.Errorf("%w (%s: %s)", err, b.requestIDHeader, requestID)
+		}
+		return nil, err
+	}
+
+	return &sentRequest{
+		req:            req,
+		res:            res,
+		body:           bodyBytes,
+		start:          start,
+		timings:        timings,
+		throttleDelays: throttleDelays,
+	}, nil
+}
 
+func (b *ReqBuilder) Do() *Response {
+	b.helper()
+
+	ctx, cancel := b.requestContext()
+	defer cancel()
+
+	sent, err := b.send(ctx)
 	if err != nil {
 		b.onError(err)
 		return nil
 	}
 
-	return NewResponse(res, req, b.onError)
+	req := sent.req
+
+	response := NewResponse(sent.res, req, b.onError)
+	if response != nil {
+		response.duration = time.Since(sent.start)
+		response.timings = sent.timings.result()
+		response.throttleDelays = sent.throttleDelays
+		response.curl = b.curl(req.Method, req.URL.String(), req.Header, sent.body)
+		response.reqBody = sent.body
+		response.debug = b.debug
+		response.helper = b.helper
+		response.vars = b.vars
+		response.reporter = b.reporter
+		response.failures = b.failures
+		response.redactor = b.redactor
+		if b.requestIDHeader != "" {
+			response.requestIDHeader = b.requestIDHeader
+			response.requestID = req.Header.Get(b.requestIDHeader)
+		}
+		response.builder = b
+		response.reportName = req.Method + " " + b.endpoint()
+
+		if b.pact != nil {
+			b.recordPact(req, sent.body, response)
+		}
+	}
+
+	return response
 }
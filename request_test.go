@@ -1,35 +1,520 @@
 package httptester_test
 
 import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/andybalholm/brotli"
 	"github.com/bancek/httptester"
+	"github.com/klauspost/compress/zstd"
 )
 
-var base string
-var fail func(error)
+func TestReqBuilder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(409)
+		w.Write([]byte("Already exists\n"))
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
 
-func newRequest() *httptester.ReqBuilder {
-	return httptester.NewReqBuilder(base, http.DefaultClient, fail).Header("X-Http-Tester", "true")
+	c.GET("/").Header("X-Http-Tester", "true").Do().Status(409).Contains("Already exists")
 }
 
-func GET(url string) *httptester.ReqBuilder {
-	return newRequest().GET(url)
+func TestReqBuilderCookieJar(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret", Path: "/"})
+			return
+		}
+		cookie, err := r.Cookie("session")
+		if err != nil {
+			w.WriteHeader(401)
+			return
+		}
+		w.Write([]byte(cookie.Value))
+	}))
+	defer server.Close()
+
+	fatal := func(err error) {
+		t.Fatal(err)
+	}
+
+	jar, _ := cookiejar.New(nil)
+	newRequest := func() *httptester.ReqBuilder {
+		return httptester.NewReqBuilder(server.URL, http.DefaultClient, fatal).WithCookieJar(jar)
+	}
+
+	newRequest().GET("/me").Do().Status(401)
+	newRequest().POST("/login").Do().Status(200)
+	newRequest().GET("/me").Do().Status(200).Eq("secret")
+
+	httptester.NewReqBuilder(server.URL, http.DefaultClient, fatal).GET("/me").Do().Status(401)
+
+	if http.DefaultClient.Jar != nil {
+		t.Fatal("shared client was mutated")
+	}
 }
 
-func TestReqBuilder(t *testing.T) {
-	fail = func(err error) {
+func TestReqBuilderRetry(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		body, _ := io.ReadAll(r.Body)
+		if attempts < 3 {
+			w.WriteHeader(503)
+			return
+		}
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	fatal := func(err error) {
 		t.Fatal(err)
 	}
 
+	policy := httptester.DefaultRetryPolicy(3)
+	policy.InitialBackoff = time.Millisecond
+
+	httptester.NewReqBuilder(server.URL, http.DefaultClient, fatal).
+		POST("/").Body(strings.NewReader("payload")).RetryPolicy(policy).
+		Do().Status(200).Eq("payload")
+
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts got %d", attempts)
+	}
+
+	attempts = 0
+	policy.MaxRetries = 1
+
+	httptester.NewReqBuilder(server.URL, http.DefaultClient, fatal).
+		GET("/").RetryPolicy(policy).Do().Status(503)
+
+	if attempts != 2 {
+		t.Fatalf("expected 2 attempts got %d", attempts)
+	}
+}
+
+func TestReqBuilderSign(t *testing.T) {
+	secret := []byte("webhook-secret")
+
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(409)
-		w.Write([]byte("Already exists\n"))
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(body)
+		if r.Header.Get("X-Hub-Signature-256") != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+			w.WriteHeader(401)
+			return
+		}
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	sign := func(req *http.Request, body []byte) {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(body)
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	c := httptester.New(t, server.URL, httptester.WithSign(sign))
+
+	c.POST("/webhook").JSON(map[string]string{"event": "push"}).Do().Status(200).Eq(`{"event":"push"}`)
+	c.POST("/webhook").Body(strings.NewReader("raw")).Do().Status(200).Eq("raw")
+	c.POST("/webhook").Body(strings.NewReader("raw")).Sign(nil).Do().Status(401)
+}
+
+func TestReqBuilderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	var errs []error
+	res := collectErrors(server.URL, &errs).GET("/").Timeout(10 * time.Millisecond).Do()
+	if res != nil || len(errs) != 1 || !errors.Is(errs[0], context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded got %v", errs)
+	}
+}
+
+func TestReqBuilderCurl(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+	}))
+	defer server.Close()
+
+	var errs []error
+	b := collectErrors(server.URL, &errs).POST("/articles").Q("draft", "1").
+		Bearer("token").JSON(map[string]string{"title": "It's"})
+
+	expected := "curl -L -X POST '" + server.URL + "/articles?draft=1' -H 'Authorization: Bearer token' " +
+		`-H 'Content-Type: application/json' --data-binary '{"title":"It'\''s"}'`
+	if curl := b.Curl(); curl != expected {
+		t.Fatalf("expected %s got %s", expected, curl)
+	}
+
+	b.Do().Status(200)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), expected) {
+		t.Fatalf("expected curl command in error got %v", errs)
+	}
+}
+
+func TestReqBuilderGraphQL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := struct {
+			Query         string
+			Variables     map[string]interface{}
+			OperationName string
+		}{}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		w.Header().Set("Content-Type", "application/json")
+		if req.Variables["id"] != "1" {
+			w.Write([]byte(`{"data":null,"errors":[{"message":"article not found"}]}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"article":{"title":"` + req.OperationName + `"}}}`))
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	data := struct {
+		Article struct {
+			Title string
+		}
+	}{}
+	query := `query GetArticle($id: ID!) { article(id: $id) { title } }`
+
+	c.POST("/graphql").GraphQL(query, map[string]interface{}{"id": "1"}, "GetArticle").
+		Do().Status(200).GraphQLData(&data)
+	if data.Article.Title != "GetArticle" {
+		t.Fatalf("unexpected data: %+v", data)
+	}
+
+	c.POST("/graphql").GraphQL(query, map[string]interface{}{"id": "2"}, "").
+		Do().Status(200).GraphQLError("not found")
+}
+
+func TestReqBuilderMultipart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			w.WriteHeader(400)
+			return
+		}
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			data, _ := io.ReadAll(part)
+			fmt.Fprintf(w, "%s|%s|%s|%s\n", part.FormName(), part.FileName(), part.Header.Get("Content-Type"), data)
+		}
+	}))
+	defer server.Close()
+
+	rawHeader := textproto.MIMEHeader{}
+	rawHeader.Set("Content-Disposition", `form-data; name="raw"`)
+	rawHeader.Set("Content-Type", "application/json")
+
+	httptester.New(t, server.URL).POST("/upload").Multipart().
+		Field("title", "Photos").
+		FileWithType("files", "a.png", "image/png", strings.NewReader("png")).
+		File("files", "b.bin", strings.NewReader("bin")).
+		Part(rawHeader, strings.NewReader(`{"a":1}`)).
+		Done().
+		Do().Status(200).Eq("title|||Photos\n" +
+		"files|a.png|image/png|png\n" +
+		"files|b.bin|application/octet-stream|bin\n" +
+		"raw||application/json|{\"a\":1}\n")
+}
+
+func TestReqBuilderMethods(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Method", r.Method)
+		w.Write([]byte(`{"ok":true}`))
 	}))
 	defer server.Close()
-	base = server.URL
 
-	GET("/").Do().Status(409).Contains("Already exists")
+	c := httptester.New(t, server.URL)
+
+	c.PATCH("/").Do().Status(200).HeaderEq("X-Method", "PATCH")
+	c.OPTIONS("/").Do().Status(200).HeaderEq("X-Method", "OPTIONS")
+	c.TRACE("/").Do().Status(200).HeaderEq("X-Method", "TRACE")
+	c.HEAD("/").Do().Status(200).HeaderEq("X-Method", "HEAD").HeaderEq("Content-Length", "11")
+
+	var errs []error
+	collectErrors(server.URL, &errs).HEAD("/").Do().Status(200).JSONPath("$.ok", true)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "HEAD responses have no body") {
+		t.Fatalf("expected HEAD body error got %v", errs)
+	}
+}
+
+func TestReqBuilderPathParams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.EscapedPath()))
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	c.Request().Path("/users/{id}/posts/{postID}").Param("id", "a/b c", "postID", "42").
+		Do().Status(200).Eq("/users/a%2Fb%20c/posts/42")
+
+	c.GET("/users/{id}/search?filter={\"id\":1}").Param("id", "7").
+		Do().Status(200).Eq("/users/7/search")
+	c.GET("/files/{name}").Do().Status(200).Eq("/files/%7Bname%7D")
+
+	var errs []error
+	res := collectErrors(server.URL, &errs).Path("/users/{id}").Do()
+	if res != nil || len(errs) != 1 || !strings.Contains(errs[0].Error(), "missing path parameters id") {
+		t.Fatalf("expected missing parameter error got %v", errs)
+	}
+}
+
+func TestReqBuilderQStruct(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.RawQuery))
+	}))
+	defer server.Close()
+
+	type Paging struct {
+		Page int `url:"page"`
+	}
+
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	filter := &struct {
+		Paging
+		Query   string     `url:"q"`
+		Tags    []string   `url:"tag"`
+		Draft   *bool      `url:"draft"`
+		Since   *time.Time `url:"since,omitempty"`
+		Author  string     `url:"author,omitempty"`
+		Limit   float64    `url:"limit,omitempty"`
+		Ignored string     `url:"-"`
+	}{
+		Paging: Paging{Page: 2},
+		Query:  "a b",
+		Tags:   []string{"go", "http"},
+		Since:  &since,
+	}
+
+	httptester.New(t, server.URL).GET("/").QStruct(filter).Do().Status(200).
+		Eq("page=2&q=a+b&since=2024-01-02T03%3A04%3A05Z&tag=go&tag=http")
+}
+
+func TestReqBuilderFormStruct(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		fmt.Fprintf(w, "%s %v %s", r.Header.Get("Content-Type"), r.PostForm["role"], r.PostForm.Get("username"))
+	}))
+	defer server.Close()
+
+	httptester.New(t, server.URL).POST("/users").FormStruct(struct {
+		Username string   `form:"username"`
+		Roles    []string `form:"role"`
+		Note     string   `form:"note,omitempty"`
+	}{
+		Username: "alice",
+		Roles:    []string{"admin", "dev"},
+	}).Do().Status(200).Eq("application/x-www-form-urlencoded [admin dev] alice")
+}
+
+func TestReqBuilderClone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "%s %s %s %s %s", r.Method, r.URL.RequestURI(), r.Header.Get("Authorization"), r.Header.Get("X-Extra"), body)
+	}))
+	defer server.Close()
+
+	template := httptester.New(t, server.URL).Request().Bearer("token").Q("tenant", "1").
+		Body(strings.NewReader("payload"))
+
+	template.Clone().POST("/a").Q("page", "2").Header("X-Extra", "a").Do().
+		Eq("POST /a?page=2&tenant=1 Bearer token a payload")
+	template.Clone().PUT("/b").Do().
+		Eq("PUT /b?tenant=1 Bearer token  payload")
+	template.GET("/c").Do().
+		Eq("GET /c?tenant=1 Bearer token  payload")
+}
+
+func TestReqBuilderNoFollowParallel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/target", http.StatusFound)
+			return
+		}
+		w.Write([]byte("target"))
+	}))
+	defer server.Close()
+
+	client := &http.Client{}
+
+	t.Run("group", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			noFollow := i%2 == 0
+			t.Run(fmt.Sprint(i), func(t *testing.T) {
+				t.Parallel()
+				b := httptester.NewReqBuilder(server.URL, client, func(err error) {
+					t.Error(err)
+				}).GET("/redirect")
+				if noFollow {
+					b.NoFollow().Do().Status(302)
+				} else {
+					b.Do().Status(200).Eq("target")
+				}
+			})
+		}
+	})
+
+	if client.CheckRedirect != nil {
+		t.Fatal("shared client was mutated")
+	}
+}
+
+func TestReqBuilderVars(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "POST" {
+			w.Write([]byte(`{"article":{"id":42,"token":"abc"}}`))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, `{"path":%q,"auth":%q,"body":%q}`, r.URL.RequestURI(), r.Header.Get("Authorization"), body)
+	}))
+	defer server.Close()
+
+	vars := httptester.NewVars()
+	c := httptester.New(t, server.URL, httptester.WithVars(vars))
+
+	c.POST("/articles").Do().Status(201, 200).
+		Extract("articleID", "$.article.id").
+		Extract("token", "$.article.token")
+
+	c.PUT("/articles/{{articleID}}").Q("v", "{{ token }}").Bearer("{{token}}").
+		Body(strings.NewReader(`{"id":{{articleID}}}`)).Do().
+		JSONPath("$.path", "/articles/42?v=abc").
+		JSONPath("$.auth", "Bearer abc").
+		JSONPath("$.body", `{"id":42}`)
+
+	if vars.String("articleID") != "42" {
+		t.Fatalf("unexpected articleID %s", vars.String("articleID"))
+	}
+
+	var errs []error
+	collectErrors(server.URL, &errs).Vars(vars).GET("/articles/{{missing}}").Do()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "undefined variables missing") {
+		t.Fatalf("expected undefined variable error got %v", errs)
+	}
+}
+
+func TestEventually(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.Write([]byte(`{"status":"pending"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"done"}`))
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	httptester.Eventually(func() *httptester.ReqBuilder {
+		return c.GET("/jobs/1")
+	}, func(r *httptester.Response) {
+		r.Status(200).JSONPath("$.status", "done")
+	}, time.Second, 10*time.Millisecond).Status(200)
+
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Fatalf("expected 3 calls got %d", n)
+	}
+
+	var errs []error
+	res := httptester.Eventually(func() *httptester.ReqBuilder {
+		return collectErrors(server.URL, &errs).GET("/jobs/1")
+	}, func(r *httptester.Response) {
+		r.JSONPath("$.status", "failed")
+	}, 50*time.Millisecond, 10*time.Millisecond)
+
+	if res != nil || len(errs) != 1 || !strings.Contains(errs[0].Error(), "condition not met within 50ms") {
+		t.Fatalf("expected timeout error got %v", errs)
+	}
+}
+
+func TestReqBuilderEncodeBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reader io.Reader = r.Body
+		switch r.Header.Get("Content-Encoding") {
+		case "gzip":
+			gr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(400)
+				return
+			}
+			reader = gr
+		case "deflate":
+			zr, err := zlib.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(400)
+				return
+			}
+			reader = zr
+		case "br":
+			reader = brotli.NewReader(r.Body)
+		case "zstd":
+			zr, err := zstd.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(400)
+				return
+			}
+			defer zr.Close()
+			reader = zr
+		}
+		body, _ := io.ReadAll(reader)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	b := c.POST("/").JSON(map[string]string{"name": "Ann"}).GzipBody()
+	if curl := b.Curl(); curl != `printf '%s' '{"name":"Ann"}' | gzip -c | curl -L -X POST `+server.URL+`/ -H 'Content-Encoding: gzip' -H 'Content-Type: application/json' --data-binary '@-'` {
+		t.Fatalf("unexpected curl %s", curl)
+	}
+	b.Do().Status(200).Eq(`{"name":"Ann"}`)
+
+	c.POST("/").Body(strings.NewReader("hello")).EncodeBody("deflate").Do().Status(200).Eq("hello")
+	c.POST("/").Body(strings.NewReader("hello")).EncodeBody("br").Do().Status(200).Eq("hello")
+	c.POST("/").Body(strings.NewReader("hello")).EncodeBody("zstd").Do().Status(200).Eq("hello")
+
+	var errs []error
+	httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	})).POST("/").Body(strings.NewReader("hello")).EncodeBody("x-unknown").Do()
+	if len(errs) != 1 || errs[0].Error() != "no content encoder registered for x-unknown" {
+		t.Fatalf("unexpected errors %v", errs)
+	}
 }
@@ -0,0 +1,36 @@
+package httptester
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// RequestIDHeader is the default header of request ids, see
+// ReqBuilder.RequestID.
+const RequestIDHeader = "X-Request-Id"
+
+// RequestID sends a unique id (a random UUID) in the header, X-Request-Id
+// if header is empty, unless the request already has one. Retries of the
+// request share the id. The id is available with Response.RequestID and
+// included in failure messages so failures can be found in server logs.
+func (b *ReqBuilder) RequestID(header string) *ReqBuilder {
+	if header == "" {
+		header = RequestIDHeader
+	}
+	b.requestIDHeader = header
+	return b
+}
+
+func newRequestID() string {
+	var id [16]byte
+	rand.Read(id[:])
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16])
+}
+
+// RequestID returns the request id sent with the request, see
+// ReqBuilder.RequestID.
+func (r *Response) RequestID() string {
+	return r.requestID
+}
@@ -0,0 +1,80 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bancek/httptester"
+)
+
+func TestRequestID(t *testing.T) {
+	var mu sync.Mutex
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ids = append(ids, r.Header.Get("X-Request-Id")+r.Header.Get("X-Correlation-Id"))
+		attempt := len(ids)
+		mu.Unlock()
+
+		if r.URL.Path == "/flaky" && attempt == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	var errs []error
+	b := httptester.NewClient(
+		httptester.WithBaseURL(server.URL),
+		httptester.WithOnError(func(err error) {
+			errs = append(errs, err)
+		}),
+		httptester.WithRequestID(""),
+	)
+
+	res := b.GET("/flaky").RetryPolicy(httptester.RetryPolicy{MaxRetries: 1, InitialBackoff: time.Millisecond}).Do().Status(200)
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !uuid.MatchString(res.RequestID()) {
+		t.Fatalf("expected UUID request id got %q", res.RequestID())
+	}
+	if len(ids) != 2 || ids[0] != res.RequestID() || ids[1] != res.RequestID() {
+		t.Errorf("expected retries to share request id %s got %q", res.RequestID(), ids)
+	}
+
+	other := b.GET("/").Do()
+	if other.RequestID() == res.RequestID() {
+		t.Errorf("expected unique request ids got %s twice", res.RequestID())
+	}
+
+	own := b.GET("/").Header("X-Request-Id", "my-id").Do()
+	if own.RequestID() != "my-id" || ids[len(ids)-1] != "my-id" {
+		t.Errorf("expected explicit request id to be kept got %s", own.RequestID())
+	}
+
+	custom := b.GET("/").RequestID("X-Correlation-Id").Do().Status(201)
+	if ids[len(ids)-1] != custom.RequestID() || custom.RequestID() == "" {
+		t.Errorf("expected request id in X-Correlation-Id got %q", ids[len(ids)-1])
+	}
+
+	b.GET("/").Q("a", "b").Timeout(time.Nanosecond).Do()
+
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors got %v", errs)
+	}
+	if msg := errs[0].Error(); !strings.HasPrefix(msg, "GET "+server.URL+"/ (X-Correlation-Id: "+custom.RequestID()+"): expected status [201] got 200") {
+		t.Errorf("expected request id in error got %s", msg)
+	}
+	if msg := errs[1].Error(); !strings.Contains(msg, "(X-Request-Id: ") {
+		t.Errorf("expected request id in transport error got %s", msg)
+	}
+
+	if id := httptester.NewClient(httptester.WithBaseURL(server.URL)).GET("/").Do().RequestID(); id != "" {
+		t.Errorf("expected no request id got %s", id)
+	}
+}
@@ -0,0 +1,34 @@
+package httptester
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+// Resolve connects to address instead of resolving hostPort, like curl
+// --resolve. The URL, Host header and TLS server name are unchanged so
+// virtual host routing and SNI can be tested against a local or staging
+// server. hostPort is host:port and address is an IP with an optional port
+// (the port of hostPort is used if omitted).
+func (b *ReqBuilder) Resolve(hostPort string, address string) *ReqBuilder {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		if _, port, err := net.SplitHostPort(hostPort); err == nil {
+			address = net.JoinHostPort(address, port)
+		}
+	}
+
+	return b.transportOption(func(t *http.Transport) {
+		dial := t.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{}).DialContext
+		}
+
+		t.DialContext = func(ctx context.Context, network string, addr string) (net.Conn, error) {
+			if addr == hostPort {
+				addr = address
+			}
+			return dial(ctx, network, addr)
+		}
+	})
+}
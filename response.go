@@ -1,21 +1,65 @@
 package httptester
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Response struct {
 	*http.Response
-	req     *http.Request
-	onError func(error)
-	Body    []byte
-	URL     *url.URL
+	req      *http.Request
+	onError  func(error)
+	curl     string
+	reqBody  []byte
+	debug    bool
+	helper   func()
+	vars     *Vars
+	duration time.Duration
+	timings  Timings
+	// throttleDelays are the Retry-After delays waited, see
+	// ReqBuilder.RetryAfter.
+	throttleDelays []time.Duration
+	// reporter, reportName, errs and tracking are used to record
+	// assertions to a JUnitReporter, failures and assertion to record
+	// failures to a FailureReport.
+	reporter   *JUnitReporter
+	reportName string
+	errs       []error
+	tracking   int
+	failures   *FailureReport
+	assertion  string
+	// redactor masks secrets in dumps and failure messages.
+	redactor *Redactor
+	// requestID is the id sent in the requestIDHeader, see
+	// ReqBuilder.RequestID.
+	requestIDHeader string
+	requestID       string
+	// builder is the builder that sent the request, used to derive follow
+	// up requests.
+	builder *ReqBuilder
+	// masks are JSONPaths of values replaced before comparisons.
+	masks []string
+	// encodings are the content codings of the response and encodedSize
+	// the size of the body before decoding.
+	encodings   []string
+	encodedSize int
+	Body        []byte
+	URL         *url.URL
 }
 
 func NewResponse(res *http.Response, req *http.Request, onError func(error)) *Response {
@@ -27,28 +71,157 @@ func NewResponse(res *http.Response, req *http.Request, onError func(error)) *Re
 		return nil
 	}
 
+	encodedSize := len(body)
+	encodings := contentEncodings(res.Header.Get("Content-Encoding"))
+
+	if res.Uncompressed {
+		// http.Transport removed Content-Encoding after decompressing.
+		encodings = []string{"gzip"}
+		encodedSize = -1
+	} else if len(encodings) > 0 {
+		body, _, err = decodeContent(body, encodings)
+		if err != nil {
+			onError(err)
+			return nil
+		}
+	}
+
 	return &Response{
-		Response: res,
-		req:      req,
-		onError:  onError,
-		Body:     body,
-		URL:      res.Request.URL,
+		Response:    res,
+		req:         req,
+		onError:     onError,
+		helper:      func() {},
+		encodings:   encodings,
+		encodedSize: encodedSize,
+		Body:        body,
+		URL:         res.Request.URL,
 	}
 }
 
 func (r *Response) err(err error) {
-	r.onError(fmt.Errorf("%s %s: %s", r.req.Method, r.req.URL.String(), err))
+	r.helper()
+
+	if r.redactor != nil {
+		err = r.redactor.error(err, r)
+	}
+
+	if r.reporter != nil {
+		r.errs = append(r.errs, err)
+	}
+
+	msg := fmt.Sprintf("%s %s: %s", r.req.Method, r.redactor.text(r.req.URL.String()), err)
+	if r.requestID != "" {
+		msg = fmt.Sprintf("%s %s (%s: %s): %s", r.req.Method, r.redactor.text(r.req.URL.String()), r.requestIDHeader, r.requestID, err)
+	}
+	if r.curl != "" {
+		msg += "\n" + r.curl
+	}
+	if r.debug {
+		msg += "\n" + r.Dump()
+	}
+	msg = r.redactor.message(msg, r)
+	if r.failures != nil {
+		r.failures.add(r.reportName, r.assertion, err)
+	}
+	r.onError(&AssertionError{Request: r.req, Response: r, Err: err, msg: msg})
+}
+
+func (r *Response) bodyMismatch(assertion string, expected string, format string, args ...interface{}) error {
+	return &BodyMismatchError{
+		Assertion: assertion,
+		Expected:  expected,
+		Actual:    r.Body,
+		msg:       fmt.Sprintf(format, args...),
+	}
+}
+
+func (r *Response) headerError(assertion string, key string, expected string, format string, args ...interface{}) error {
+	return &HeaderError{
+		Assertion: assertion,
+		Key:       key,
+		Expected:  expected,
+		Actual:    r.Header.Values(key),
+		msg:       fmt.Sprintf(format, args...),
+	}
+}
+
+func (r *Response) Dump() string {
+	sb := &strings.Builder{}
+
+	sb.WriteString("--- request ---\n")
+	fmt.Fprintf(sb, "%s %s\n", r.req.Method, r.redactor.text(r.req.URL.String()))
+	dumpHeader(sb, r.redactor.header(r.req.Header))
+	dumpBody(sb, r.req.Header.Get("Content-Type"), r.redactor.body(r.reqBody))
+
+	sb.WriteString("--- response ---\n")
+	fmt.Fprintf(sb, "%s %s\n", r.Proto, r.Response.Status)
+	dumpHeader(sb, r.redactor.header(r.Header))
+	dumpBody(sb, r.Header.Get("Content-Type"), r.redactor.body(r.Body))
+
+	return r.redactor.message(sb.String(), r)
+}
+
+func (r *Response) Curl() string {
+	return r.curl
+}
+
+// hasBody reports an error when a body assertion is used on a response to a
+// HEAD request, which never has a body.
+func (r *Response) hasBody() bool {
+	r.helper()
+
+	if r.req.Method == "HEAD" {
+		r.err(errors.New("HEAD responses have no body"))
+		return false
+	}
+
+	return true
 }
 
 func (r *Response) bodyExcerpt() string {
-	if len(r.Body) > 100 {
-		return string(r.Body[:100]) + "..."
+	return bodyExcerpt(r.Body)
+}
+
+func bodyExcerpt(body []byte) string {
+	if len(body) > 100 {
+		return string(body[:100]) + "..."
 	}
 
-	return string(r.Body)
+	return string(body)
+}
+
+// Duration returns the time from sending the final attempt of the request
+// until the response body was read.
+func (r *Response) Duration() time.Duration {
+	return r.duration
+}
+
+func (r *Response) FasterThan(d time.Duration) *Response {
+	r.helper()
+	defer r.track("FasterThan", d)()
+
+	if r.duration >= d {
+		r.err(fmt.Errorf("expected response faster than %s, took %s", d, r.duration))
+	}
+
+	return r
+}
+
+func (r *Response) SlowerThan(d time.Duration) *Response {
+	r.helper()
+	defer r.track("SlowerThan", d)()
+
+	if r.duration <= d {
+		r.err(fmt.Errorf("expected response slower than %s, took %s", d, r.duration))
+	}
+
+	return r
 }
 
 func (r *Response) Status(statuses ...int) *Response {
+	r.helper()
+	defer r.track("Status", statuses)()
+
 	if len(statuses) > 0 {
 		ok := false
 
@@ -59,7 +232,22 @@ func (r *Response) Status(statuses ...int) *Response {
 		}
 
 		if !ok {
-			r.err(fmt.Errorf("expected status %v got %d: %s", statuses, r.StatusCode, r.bodyExcerpt()))
+			r.err(&StatusError{Expected: statuses, Actual: r.StatusCode, Body: r.Body})
+		}
+	}
+
+	return r
+}
+
+// StatusNot checks that the status is none of statuses.
+func (r *Response) StatusNot(statuses ...int) *Response {
+	r.helper()
+	defer r.track("StatusNot", statuses)()
+
+	for _, status := range statuses {
+		if r.StatusCode == status {
+			r.err(&StatusError{Expected: statuses, Actual: r.StatusCode, Not: true, Body: r.Body})
+			break
 		}
 	}
 
@@ -67,6 +255,12 @@ func (r *Response) Status(statuses ...int) *Response {
 }
 
 func (r *Response) JSON(j interface{}) interface{} {
+	r.helper()
+
+	if !r.hasBody() {
+		return nil
+	}
+
 	contentType := r.Header.Get("Content-Type")
 	if !strings.HasPrefix(contentType, "application/json") {
 		r.err(fmt.Errorf("Content-Type is not application/json, got %s: %s", contentType, r.bodyExcerpt()))
@@ -79,7 +273,30 @@ func (r *Response) JSON(j interface{}) interface{} {
 	return j
 }
 
+// JSONAs decodes the JSON body of r into a new T, e.g.
+//
+//	user, res := httptester.JSONAs[User](c.GET("/users/1").Do().Status(200))
+//
+// The zero value is returned if r is nil or the body cannot be decoded.
+func JSONAs[T any](r *Response) (T, *Response) {
+	var v T
+	if r == nil {
+		return v, nil
+	}
+
+	r.helper()
+
+	r.JSON(&v)
+	return v, r
+}
+
 func (r *Response) XML(j interface{}) interface{} {
+	r.helper()
+
+	if !r.hasBody() {
+		return nil
+	}
+
 	contentType := r.Header.Get("Content-Type")
 	if !strings.HasPrefix(contentType, "application/xml") && !strings.HasPrefix(contentType, "text/xml") {
 		r.err(fmt.Errorf("Content-Type is not application/xml or text/xml, got %s: %s", contentType, r.bodyExcerpt()))
@@ -92,29 +309,581 @@ func (r *Response) XML(j interface{}) interface{} {
 	return j
 }
 
+func (r *Response) jsonPath(path string) (interface{}, bool) {
+	r.helper()
+
+	if !r.hasBody() {
+		return nil, false
+	}
+
+	var v interface{}
+	err := json.Unmarshal(r.Body, &v)
+	if err != nil {
+		r.err(err)
+		return nil, false
+	}
+	value, err := evalJSONPath(v, path)
+	if err != nil {
+		r.err(err)
+		return nil, false
+	}
+	return value, true
+}
+
+func (r *Response) JSONPathValue(path string) interface{} {
+	r.helper()
+
+	value, _ := r.jsonPath(path)
+	return value
+}
+
+func (r *Response) JSONPath(path string, expected interface{}) *Response {
+	r.helper()
+	defer r.track("JSONPath", path, expected)()
+
+	value, ok := r.jsonPath(path)
+	if !ok {
+		return r
+	}
+
+	normalized, err := normalizeJSON(expected)
+	if err != nil {
+		r.err(err)
+		return r
+	}
+
+	if !reflect.DeepEqual(value, normalized) {
+		if isJSONContainer(normalized) && isJSONContainer(value) {
+			r.err(fmt.Errorf("JSONPath %s: does not equal expected value:%s", path, formatJSONDiff(jsonDiff(path, normalized, value))))
+		} else {
+			r.err(fmt.Errorf("JSONPath %s: expected %s got %s", path, jsonString(normalized), jsonString(value)))
+		}
+	}
+
+	return r
+}
+
+// Extract stores the value at a JSONPath in the builder's Vars under name.
+func (r *Response) Extract(name string, path string) *Response {
+	r.helper()
+
+	if r.vars == nil {
+		r.err(fmt.Errorf("cannot extract %s: no Vars set on the request", name))
+		return r
+	}
+
+	if value, ok := r.jsonPath(path); ok {
+		r.vars.Set(name, value)
+	}
+
+	return r
+}
+
+func (r *Response) JSONPathExists(path string) *Response {
+	r.helper()
+	defer r.track("JSONPathExists", path)()
+
+	r.jsonPath(path)
+	return r
+}
+
+func (r *Response) JSONPathLen(path string, length int) *Response {
+	r.helper()
+	defer r.track("JSONPathLen", path, length)()
+
+	value, ok := r.jsonPath(path)
+	if !ok {
+		return r
+	}
+
+	var actual int
+	switch v := value.(type) {
+	case []interface{}:
+		actual = len(v)
+	case map[string]interface{}:
+		actual = len(v)
+	case string:
+		actual = len(v)
+	default:
+		r.err(fmt.Errorf("JSONPath %s: expected array, object or string got %s", path, jsonString(value)))
+		return r
+	}
+
+	if actual != length {
+		r.err(fmt.Errorf("JSONPath %s: expected length %d got %d", path, length, actual))
+	}
+
+	return r
+}
+
+// JSONNumberNear checks that the number at a JSONPath is within epsilon of
+// value.
+func (r *Response) JSONNumberNear(path string, value float64, epsilon float64) *Response {
+	r.helper()
+	defer r.track("JSONNumberNear", path, value, epsilon)()
+
+	v, ok := r.jsonPath(path)
+	if !ok {
+		return r
+	}
+
+	actual, ok := v.(float64)
+	if !ok {
+		r.err(fmt.Errorf("JSONPath %s: expected number got %s", path, jsonString(v)))
+		return r
+	}
+
+	if math.Abs(actual-value) > epsilon {
+		r.err(fmt.Errorf("JSONPath %s: expected %v ± %v got %v", path, value, epsilon, actual))
+	}
+
+	return r
+}
+
+// JSONTimeWithin checks that the timestamp at a JSONPath, an RFC 3339
+// string or a number of Unix seconds, is within tolerance of expected.
+func (r *Response) JSONTimeWithin(path string, expected time.Time, tolerance time.Duration) *Response {
+	r.helper()
+	defer r.track("JSONTimeWithin", path, expected.Format(time.RFC3339Nano), tolerance)()
+
+	v, ok := r.jsonPath(path)
+	if !ok {
+		return r
+	}
+
+	var actual time.Time
+	switch t := v.(type) {
+	case string:
+		var err error
+		if actual, err = time.Parse(time.RFC3339Nano, t); err != nil {
+			r.err(fmt.Errorf("JSONPath %s: expected RFC 3339 timestamp got %s", path, jsonString(v)))
+			return r
+		}
+	case float64:
+		sec, frac := math.Modf(t)
+		actual = time.Unix(int64(sec), int64(frac*1e9))
+	default:
+		r.err(fmt.Errorf("JSONPath %s: expected timestamp got %s", path, jsonString(v)))
+		return r
+	}
+
+	if d := actual.Sub(expected); d > tolerance || d < -tolerance {
+		r.err(fmt.Errorf("JSONPath %s: expected %s ± %s got %s", path, expected.Format(time.RFC3339Nano), tolerance, actual.Format(time.RFC3339Nano)))
+	}
+
+	return r
+}
+
+func (r *Response) MatchesOpenAPI(spec *OpenAPI, operationID string) *Response {
+	r.helper()
+	defer r.track("MatchesOpenAPI", operationID)()
+
+	if errs := spec.validateResponse(operationID, r); len(errs) > 0 {
+		r.err(fmt.Errorf("response does not match OpenAPI operation %s:\n  %s", operationID, strings.Join(errs, "\n  ")))
+	}
+
+	return r
+}
+
+func (r *Response) JSONSchema(schema []byte) *Response {
+	r.helper()
+	defer r.track("JSONSchema")()
+
+	if !r.hasBody() {
+		return r
+	}
+
+	var s interface{}
+	if err := json.Unmarshal(schema, &s); err != nil {
+		r.err(fmt.Errorf("invalid JSON Schema: %w", err))
+		return r
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(r.Body, &body); err != nil {
+		r.err(err)
+		return r
+	}
+
+	if errs := validateSchema(s, s, body); len(errs) > 0 {
+		r.err(fmt.Errorf("body does not match JSON Schema:\n  %s", strings.Join(errs, "\n  ")))
+	}
+
+	return r
+}
+
+func (r *Response) JSONSchemaFile(path string) *Response {
+	r.helper()
+	defer r.track("JSONSchemaFile", path)()
+
+	schema, err := os.ReadFile(path)
+	if err != nil {
+		r.err(err)
+		return r
+	}
+
+	return r.JSONSchema(schema)
+}
+
+func (r *Response) SaveTo(path string) *Response {
+	r.helper()
+
+	f, err := createFile(path)
+	if err != nil {
+		r.err(err)
+		return r
+	}
+
+	_, err = f.Write(r.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		r.err(err)
+	}
+
+	return r
+}
+
+func (r *Response) SHA256(expected string) *Response {
+	r.helper()
+	defer r.track("SHA256", expected)()
+
+	sum := sha256.Sum256(r.Body)
+	if actual := hex.EncodeToString(sum[:]); actual != strings.ToLower(expected) {
+		r.err(fmt.Errorf("expected body SHA-256 %s got %s", expected, actual))
+	}
+
+	return r
+}
+
 func (r *Response) BodyStr() string {
 	return string(r.Body)
 }
 
 func (r *Response) Contains(substr string) *Response {
+	r.helper()
+	defer r.track("Contains", substr)()
+
+	if !r.hasBody() {
+		return r
+	}
+
 	if !strings.Contains(r.BodyStr(), substr) {
-		r.err(fmt.Errorf("body does not contain %s: %s", substr, r.bodyExcerpt()))
+		r.err(r.bodyMismatch("Contains", substr, "body does not contain %s: %s", substr, r.bodyExcerpt()))
+	}
+
+	return r
+}
+
+// NotContains checks that the body does not contain substr, e.g. secrets
+// or stack traces.
+func (r *Response) NotContains(substr string) *Response {
+	r.helper()
+	defer r.track("NotContains", substr)()
+
+	if !r.hasBody() {
+		return r
+	}
+
+	if i := strings.Index(r.BodyStr(), substr); i >= 0 {
+		r.err(r.bodyMismatch("NotContains", substr, "body contains %s at offset %d: %s", substr, i, mismatchExcerpt(r.BodyStr(), i)))
 	}
 
 	return r
 }
 
 func (r *Response) Eq(substr string) *Response {
+	r.helper()
+	defer r.track("Eq", substr)()
+
+	if !r.hasBody() {
+		return r
+	}
+
 	if r.BodyStr() != substr {
-		r.err(fmt.Errorf("body does not equal %s: %s", substr, r.bodyExcerpt()))
+		var expected, actual interface{}
+		if json.Unmarshal([]byte(substr), &expected) == nil && json.Unmarshal(r.Body, &actual) == nil && isJSONContainer(expected) && isJSONContainer(actual) {
+			if diff := jsonDiff("$", expected, actual); len(diff) > 0 {
+				r.err(r.bodyMismatch("Eq", substr, "body does not equal expected JSON:%s", formatJSONDiff(diff)))
+				return r
+			}
+		}
+		r.err(r.bodyMismatch("Eq", substr, "body does not equal %s: %s", substr, r.bodyExcerpt()))
+	}
+
+	return r
+}
+
+func (r *Response) NotEq(substr string) *Response {
+	r.helper()
+	defer r.track("NotEq", substr)()
+
+	if !r.hasBody() {
+		return r
+	}
+
+	if r.BodyStr() == substr {
+		r.err(r.bodyMismatch("NotEq", substr, "body equals %s", r.bodyExcerpt()))
+	}
+
+	return r
+}
+
+// Matches checks that the body matches the regular expression pattern,
+// given as a string or *regexp.Regexp. The error points at the part of the
+// body where the pattern stopped matching.
+func (r *Response) Matches(pattern interface{}) *Response {
+	r.helper()
+	defer r.track("Matches", pattern)()
+
+	var re *regexp.Regexp
+	switch p := pattern.(type) {
+	case *regexp.Regexp:
+		re = p
+	case string:
+		var err error
+		if re, err = regexp.Compile(p); err != nil {
+			r.err(fmt.Errorf("invalid regular expression %s: %w", p, err))
+			return r
+		}
+	default:
+		r.err(fmt.Errorf("Matches requires a string or *regexp.Regexp got %T", pattern))
+		return r
+	}
+
+	if !r.hasBody() {
+		return r
+	}
+
+	if re.Match(r.Body) {
+		return r
+	}
+
+	if offset, expected, ok := regexpMismatch(re, r.BodyStr()); ok {
+		r.err(r.bodyMismatch("Matches", re.String(), "body does not match %s: expected %s at offset %d: %s", re, expected, offset, mismatchExcerpt(r.BodyStr(), offset)))
+		return r
+	}
+
+	r.err(r.bodyMismatch("Matches", re.String(), "body does not match %s: %s", re, r.bodyExcerpt()))
+
+	return r
+}
+
+// regexpMismatch finds where a pattern that is a literal or a concatenation
+// stops matching s: the end of the match of its longest matching prefix and
+// the rest of the pattern.
+func regexpMismatch(re *regexp.Regexp, s string) (int, string, bool) {
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return 0, "", false
+	}
+	switch parsed.Op {
+	case syntax.OpConcat:
+	case syntax.OpLiteral:
+		parsed = &syntax.Regexp{Op: syntax.OpConcat, Flags: parsed.Flags, Sub: []*syntax.Regexp{parsed}}
+	default:
+		return 0, "", false
+	}
+
+	// Split literals so the mismatch is found character by character.
+	subs := []*syntax.Regexp{}
+	for _, sub := range parsed.Sub {
+		if sub.Op != syntax.OpLiteral {
+			subs = append(subs, sub)
+			continue
+		}
+		for _, c := range sub.Rune {
+			subs = append(subs, &syntax.Regexp{Op: syntax.OpLiteral, Flags: sub.Flags, Rune: []rune{c}})
+		}
+	}
+
+	offset := 0
+	for k := 1; k <= len(subs); k++ {
+		prefix := &syntax.Regexp{Op: syntax.OpConcat, Flags: parsed.Flags, Sub: subs[:k]}
+		prefixRe, err := regexp.Compile(prefix.String())
+		if err != nil {
+			return 0, "", false
+		}
+		loc := prefixRe.FindStringIndex(s)
+		if loc == nil {
+			rest := &syntax.Regexp{Op: syntax.OpConcat, Flags: parsed.Flags, Sub: subs[k-1:]}
+			return offset, rest.String(), true
+		}
+		offset = loc[1]
+	}
+
+	return 0, "", false
+}
+
+// mismatchExcerpt returns the part of s around offset with the offset
+// marked by >>>.
+func mismatchExcerpt(s string, offset int) string {
+	const context = 40
+
+	start, end := max(offset-context, 0), min(offset+context, len(s))
+	excerpt := s[start:offset] + ">>>" + s[offset:end]
+	if start > 0 {
+		excerpt = "..." + excerpt
+	}
+	if end < len(s) {
+		excerpt += "..."
+	}
+	return excerpt
+}
+
+// BodyLen checks the length of the (decoded) body in bytes.
+func (r *Response) BodyLen(n int) *Response {
+	r.helper()
+	defer r.track("BodyLen", n)()
+
+	if !r.hasBody() {
+		return r
+	}
+
+	if len(r.Body) != n {
+		r.err(fmt.Errorf("expected body length %d got %d", n, len(r.Body)))
+	}
+
+	return r
+}
+
+func (r *Response) BodyLenBetween(min int, max int) *Response {
+	r.helper()
+	defer r.track("BodyLenBetween", min, max)()
+
+	if !r.hasBody() {
+		return r
+	}
+
+	if len(r.Body) < min || len(r.Body) > max {
+		r.err(fmt.Errorf("expected body length between %d and %d got %d", min, max, len(r.Body)))
+	}
+
+	return r
+}
+
+// ContentLengthMatchesBody checks that the Content-Length header equals the
+// size of the body as received (before decoding).
+func (r *Response) ContentLengthMatchesBody() *Response {
+	r.helper()
+	defer r.track("ContentLengthMatchesBody")()
+
+	if !r.hasBody() {
+		return r
+	}
+
+	header := r.Header.Get("Content-Length")
+	if header == "" {
+		r.err(errors.New("response has no Content-Length header"))
+		return r
+	}
+
+	length, err := strconv.Atoi(header)
+	if err != nil {
+		r.err(fmt.Errorf("invalid Content-Length %q", header))
+		return r
+	}
+
+	if length != r.encodedSize {
+		r.err(fmt.Errorf("Content-Length %d does not match body length %d", length, r.encodedSize))
 	}
 
 	return r
 }
 
 func (r *Response) HeaderEq(key string, value string) *Response {
+	r.helper()
+	defer r.track("HeaderEq", key, value)()
+
 	if resVal := r.Header.Get(key); resVal != value {
-		r.err(fmt.Errorf("header %s: expected %s to equal %s", key, resVal, value))
+		r.err(r.headerError("HeaderEq", key, value, "header %s: expected %s to equal %s", key, resVal, value))
+	}
+
+	return r
+}
+
+// HeaderNotEq checks that no value of the header equals value.
+func (r *Response) HeaderNotEq(key string, value string) *Response {
+	r.helper()
+	defer r.track("HeaderNotEq", key, value)()
+
+	for _, resVal := range r.Header.Values(key) {
+		if resVal == value {
+			r.err(r.headerError("HeaderNotEq", key, value, "header %s: expected not to equal %s", key, value))
+			break
+		}
+	}
+
+	return r
+}
+
+// HeaderMatches checks that a value of the header matches the regular
+// expression.
+func (r *Response) HeaderMatches(key string, expr string) *Response {
+	r.helper()
+	defer r.track("HeaderMatches", key, expr)()
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		r.err(fmt.Errorf("header %s: invalid regular expression %s: %w", key, expr, err))
+		return r
+	}
+
+	values := r.Header.Values(key)
+	for _, value := range values {
+		if re.MatchString(value) {
+			return r
+		}
+	}
+
+	r.err(r.headerError("HeaderMatches", key, expr, "header %s: expected %q to match %s", key, values, expr))
+
+	return r
+}
+
+func (r *Response) HeaderContains(key string, substr string) *Response {
+	r.helper()
+	defer r.track("HeaderContains", key, substr)()
+
+	values := r.Header.Values(key)
+	for _, value := range values {
+		if strings.Contains(value, substr) {
+			return r
+		}
+	}
+
+	r.err(r.headerError("HeaderContains", key, substr, "header %s: expected %q to contain %s", key, values, substr))
+
+	return r
+}
+
+func (r *Response) HeaderAbsent(key string) *Response {
+	r.helper()
+	defer r.track("HeaderAbsent", key)()
+
+	if values := r.Header.Values(key); len(values) > 0 {
+		r.err(r.headerError("HeaderAbsent", key, "", "header %s: expected to be absent got %q", key, values))
+	}
+
+	return r
+}
+
+// HeaderValues checks all values of a repeated header in order.
+func (r *Response) HeaderValues(key string, values ...string) *Response {
+	r.helper()
+	defer r.track("HeaderValues", key, values)()
+
+	actual := r.Header.Values(key)
+	if len(actual) != len(values) {
+		r.err(r.headerError("HeaderValues", key, strings.Join(values, ", "), "header %s: expected %q got %q", key, values, actual))
+		return r
+	}
+	for i := range values {
+		if actual[i] != values[i] {
+			r.err(r.headerError("HeaderValues", key, strings.Join(values, ", "), "header %s: expected %q got %q", key, values, actual))
+			return r
+		}
 	}
 
 	return r
@@ -0,0 +1,680 @@
+package httptester_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/bancek/httptester"
+	"github.com/klauspost/compress/zstd"
+)
+
+func collectErrors(baseURL string, errs *[]error) *httptester.ReqBuilder {
+	return httptester.NewReqBuilder(baseURL, http.DefaultClient, func(err error) {
+		*errs = append(*errs, err)
+	})
+}
+
+func TestResponseJSONPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items":[{"id":1,"name":"a"},{"id":2,"tags":["x"]}],"total":2}`))
+	}))
+	defer server.Close()
+
+	var errs []error
+	res := collectErrors(server.URL, &errs).GET("/").Do()
+
+	res.JSONPath("$.items[0].id", 1).
+		JSONPath("$.items[-1].tags", []string{"x"}).
+		JSONPath("$['total']", 2).
+		JSONPathLen("$.items", 2).
+		JSONPathExists("$.items[1].tags[0]")
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+
+	res.JSONPath("$.items[0].name", "b").
+		JSONPathExists("$.missing").
+		JSONPathLen("$.items", 3).
+		JSONPathExists("$.items[5]")
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 errors got %d: %v", len(errs), errs)
+	}
+}
+
+func TestResponseDebug(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"error":"invalid title"}`))
+	}))
+	defer server.Close()
+
+	var errs []error
+	collectErrors(server.URL, &errs).Debug().POST("/").JSON(map[string]string{"title": ""}).
+		Do().Status(200).Status(400)
+
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error got %v", errs)
+	}
+
+	msg := errs[0].Error()
+	for _, expected := range []string{"--- request ---", `"title": ""`, "--- response ---", "200 OK", `"error": "invalid title"`} {
+		if !strings.Contains(msg, expected) {
+			t.Fatalf("expected %q in %s", expected, msg)
+		}
+	}
+}
+
+func TestSoftAssertions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Hello"))
+	}))
+	defer server.Close()
+
+	soft := httptester.NewSoftAssertions()
+
+	httptester.New(t, server.URL).GET("/").Soft(soft).Do().
+		Status(201).Contains("World").Eq("Hello").HeaderEq("X-Missing", "1")
+
+	if errs := soft.Errors(); len(errs) != 3 {
+		t.Fatalf("expected 3 errors got %d: %v", len(errs), errs)
+	}
+
+	if err := soft.Err(); err == nil || !strings.Contains(err.Error(), "World") {
+		t.Fatalf("expected joined error got %v", err)
+	}
+}
+
+func TestResponseJSONSchema(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items":[{"id":1,"email":"a@example.com"},{"id":-1,"email":"b"}]}`))
+	}))
+	defer server.Close()
+
+	schema := []byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"required": ["items", "total"],
+		"properties": {
+			"items": {"type": "array", "items": {"$ref": "#/$defs/item"}}
+		},
+		"$defs": {
+			"item": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "integer", "minimum": 0},
+					"email": {"type": "string", "format": "email"}
+				}
+			}
+		}
+	}`)
+
+	var errs []error
+	collectErrors(server.URL, &errs).GET("/").Do().JSONSchema(schema)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error got %v", errs)
+	}
+
+	for _, expected := range []string{
+		"$: missing required property total",
+		"$.items[1].id: expected >= 0 got -1",
+		`$.items[1].email: "b" is not a valid email`,
+	} {
+		if !strings.Contains(errs[0].Error(), expected) {
+			t.Fatalf("expected %q in %s", expected, errs[0])
+		}
+	}
+}
+
+func TestResponseJSONSchemaUnsupportedKeywords(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":1,"extra":true}`))
+	}))
+	defer server.Close()
+
+	httptester.New(t, server.URL).GET("/").Do().JSONSchema([]byte(`{"$id": "https://example.com/item", "type": "object"}`))
+
+	schema := []byte(`{
+		"type": "object",
+		"unevaluatedProperties": false,
+		"properties": {
+			"id": {"$id": "https://example.com/id", "type": "integer"}
+		}
+	}`)
+
+	var errs []error
+	collectErrors(server.URL, &errs).GET("/").Do().JSONSchema(schema)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error got %v", errs)
+	}
+
+	for _, expected := range []string{
+		"$: unsupported schema keyword unevaluatedProperties",
+		"$.id: unsupported schema keyword $id in a subschema",
+	} {
+		if !strings.Contains(errs[0].Error(), expected) {
+			t.Fatalf("expected %q in %s", expected, errs[0])
+		}
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(": comment\n\nevent: created\nid: 1\ndata: {\"id\":1}\n\n"))
+		w.(http.Flusher).Flush()
+		if r.URL.Query().Get("finite") != "" {
+			w.Write([]byte("data: line 1\ndata: line 2\n\nid\ndata: reset\n\n"))
+			return
+		}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	created := map[string]int{}
+	stream := c.GET("/events").SSE().Timeout(time.Second).ExpectEventJSON("created", &created)
+	if created["id"] != 1 {
+		t.Fatalf("unexpected event data: %v", created)
+	}
+	stream.Close()
+
+	events := c.GET("/events").Q("finite", "1").Do().Status(200).Events()
+	if len(events) != 3 || events[0].ID != "1" || events[1].ID != "1" || events[1].Event != "message" || events[1].Data != "line 1\nline 2" || events[2].ID != "" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	var errs []error
+	stream = collectErrors(server.URL, &errs).GET("/events").SSE().Timeout(20 * time.Millisecond)
+	stream.ExpectEvent("created", nil).ExpectEvent("deleted", nil)
+	stream.Close()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "expected event within") {
+		t.Fatalf("expected timeout error got %v", errs)
+	}
+}
+
+func TestStreamResponse(t *testing.T) {
+	chunk := bytes.Repeat([]byte("0123456789"), 1024)
+	hash := sha256.New()
+	for i := 0; i < 1024; i++ {
+		hash.Write(chunk)
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		for i := 0; i < 1024; i++ {
+			w.Write(chunk)
+		}
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	c.GET("/download").DoStream().Status(200).Prefix("0123").Size(10 * 1024 * 1024).SHA256(sum).Prefix("01234567890")
+
+	path := filepath.Join(t.TempDir(), "artifacts", "download.bin")
+	c.GET("/download").DoStream().SaveTo(path).SHA256(sum)
+	if info, err := os.Stat(path); err != nil || info.Size() != 10*1024*1024 {
+		t.Fatalf("unexpected saved file: %v %v", info, err)
+	}
+
+	c.GET("/download").Do().SHA256(sum).SaveTo(path)
+
+	var errs []error
+	res := collectErrors(server.URL, &errs).GET("/download").DoStream()
+	res.Prefix("1234").SHA256("00").Size(1)
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors got %v", errs)
+	}
+}
+
+func TestResponseRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/admin":
+			http.Redirect(w, r, "/login", http.StatusFound)
+		case "/login":
+			http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
+		default:
+			w.Write([]byte("dashboard"))
+		}
+	}))
+	defer server.Close()
+
+	res := httptester.New(t, server.URL).GET("/admin").Do().Status(200).
+		RedirectedVia(302, "/admin").
+		RedirectedVia(303, server.URL+"/login").
+		FinalURL("/dashboard")
+
+	redirects := res.Redirects()
+	if len(redirects) != 2 || redirects[0].Location != "/login" || redirects[1].URL.Path != "/login" {
+		t.Fatalf("unexpected redirects: %+v", redirects)
+	}
+
+	var errs []error
+	collectErrors(server.URL, &errs).GET("/admin").Do().RedirectedVia(301, "/admin").FinalURL("/login")
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors got %v", errs)
+	}
+}
+
+func TestResponseDuration(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	var errs []error
+	res := collectErrors(server.URL, &errs).GET("/").Do().
+		SlowerThan(20 * time.Millisecond).
+		FasterThan(5 * time.Second)
+
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	if res.Duration() < 30*time.Millisecond {
+		t.Fatalf("unexpected duration %s", res.Duration())
+	}
+
+	res.FasterThan(10 * time.Millisecond)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "expected response faster than 10ms, took") {
+		t.Fatalf("expected duration error got %v", errs)
+	}
+}
+
+func TestResponseTimings(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	var errs []error
+	b := func() *httptester.ReqBuilder {
+		return httptester.NewReqBuilder(server.URL, server.Client(), func(err error) {
+			errs = append(errs, err)
+		})
+	}
+
+	res := b().GET("/").Do().
+		ConnectFasterThan(5 * time.Second).
+		TLSHandshakeFasterThan(5 * time.Second).
+		TTFBFasterThan(5 * time.Second)
+
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+
+	timings := res.Timings()
+	if timings.ConnReused || timings.Connect <= 0 || timings.TLSHandshake <= 0 || timings.TimeToFirstByte < 20*time.Millisecond {
+		t.Fatalf("unexpected timings %+v", timings)
+	}
+
+	timings = b().GET("/").Do().TTFBFasterThan(time.Millisecond).Timings()
+	if !timings.ConnReused || timings.Connect != 0 || timings.TLSHandshake != 0 {
+		t.Fatalf("expected reused connection got %+v", timings)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "expected time to first byte faster than 1ms") {
+		t.Fatalf("expected TTFB error got %v", errs)
+	}
+}
+
+func TestResponseEncoding(t *testing.T) {
+	payload := strings.Repeat(`{"name": "Ann"}`, 100)
+
+	compressed := &bytes.Buffer{}
+	gw := gzip.NewWriter(compressed)
+	gw.Write([]byte(payload))
+	gw.Close()
+
+	brotliCompressed := &bytes.Buffer{}
+	bw := brotli.NewWriter(brotliCompressed)
+	bw.Write([]byte(payload))
+	bw.Close()
+
+	zstdEncoder, _ := zstd.NewWriter(nil)
+	zstdCompressed := zstdEncoder.EncodeAll([]byte(payload), nil)
+	zstdEncoder.Close()
+
+	httptester.RegisterContentDecoder("x-reverse", func(r io.Reader) (io.Reader, error) {
+		data, err := io.ReadAll(r)
+		for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+			data[i], data[j] = data[j], data[i]
+		}
+		return bytes.NewReader(data), err
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gzip":
+			if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+				w.Header().Set("Content-Encoding", "gzip")
+				w.Write(compressed.Bytes())
+				return
+			}
+			w.Write([]byte(payload))
+		case "/reverse":
+			w.Header().Set("Content-Encoding", "x-reverse")
+			w.Write([]byte("olleh"))
+		case "/br":
+			w.Header().Set("Content-Encoding", "br")
+			w.Write(brotliCompressed.Bytes())
+		case "/zstd":
+			w.Header().Set("Content-Encoding", "zstd")
+			w.Write(zstdCompressed)
+		case "/unknown":
+			w.Header().Set("Content-Encoding", "x-unknown")
+			w.Write([]byte{0x0b, 0x01, 0x80})
+		default:
+			w.Write([]byte("plain"))
+		}
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	res := c.GET("/gzip").Do().Status(200).Encoded("gzip").Eq(payload)
+	if res.EncodedSize() != -1 || res.DecodedSize() != len(payload) {
+		t.Fatalf("unexpected sizes %d %d", res.EncodedSize(), res.DecodedSize())
+	}
+
+	res = c.GET("/gzip").AcceptEncoding("gzip", "br").Do().Status(200).Encoded("gzip").Eq(payload)
+	if res.EncodedSize() != compressed.Len() || res.DecodedSize() != len(payload) {
+		t.Fatalf("unexpected sizes %d %d", res.EncodedSize(), res.DecodedSize())
+	}
+
+	c.GET("/gzip").AcceptEncoding("identity").Do().Status(200).Eq(payload)
+	c.GET("/reverse").Do().Status(200).Encoded("x-reverse").Eq("hello")
+
+	res = c.GET("/br").AcceptEncoding("br").Do().Status(200).Encoded("br").Eq(payload)
+	if res.EncodedSize() != brotliCompressed.Len() || res.DecodedSize() != len(payload) {
+		t.Fatalf("unexpected sizes %d %d", res.EncodedSize(), res.DecodedSize())
+	}
+
+	c.GET("/zstd").AcceptEncoding("zstd").Do().Status(200).Encoded("zstd").Eq(payload)
+
+	res = c.GET("/unknown").Do().Status(200).Encoded("x-unknown")
+	if !bytes.Equal(res.Body, []byte{0x0b, 0x01, 0x80}) {
+		t.Fatalf("expected undecoded body got %v", res.Body)
+	}
+
+	var errs []error
+	httptester.NewReqBuilder(server.URL, http.DefaultClient, func(err error) {
+		errs = append(errs, err)
+	}).GET("/").Do().Encoded("gzip")
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "expected gzip content encoding got identity") {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+}
+
+func TestResponseBodyLen(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/chunked":
+			w.Write([]byte("hello"))
+			w.(http.Flusher).Flush()
+		case "/mismatch":
+			w.Header().Set("Content-Length", "3")
+			w.Write([]byte("hel"))
+			w.Header().Set("Content-Length", "5")
+		default:
+			w.Write([]byte("hello"))
+		}
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	c.GET("/").Do().Status(200).
+		BodyLen(5).
+		BodyLenBetween(1, 5).
+		ContentLengthMatchesBody()
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.GET("/").Do().BodyLen(4).BodyLenBetween(6, 10)
+	b.GET("/chunked").Do().ContentLengthMatchesBody()
+
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		"expected body length 4 got 5",
+		"expected body length between 6 and 10 got 5",
+		"response has no Content-Length header",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
+
+func TestResponseHeaderMatchers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Add("Vary", "Accept")
+		w.Header().Add("Vary", "Origin")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	httptester.New(t, server.URL).GET("/").Do().
+		HeaderMatches("Content-Type", `^application/json(;|$)`).
+		HeaderContains("Content-Type", "charset=utf-8").
+		HeaderContains("Vary", "Origin").
+		HeaderAbsent("X-Powered-By").
+		HeaderValues("Vary", "Accept", "Origin")
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.GET("/").Do().
+		HeaderMatches("Content-Type", `^text/`).
+		HeaderMatches("Content-Type", `(`).
+		HeaderContains("Vary", "Cookie").
+		HeaderAbsent("Vary").
+		HeaderValues("Vary", "Origin", "Accept")
+
+	if len(errs) != 5 {
+		t.Fatalf("expected 5 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		`header Content-Type: expected ["application/json; charset=utf-8"] to match ^text/`,
+		"header Content-Type: invalid regular expression (",
+		`header Vary: expected ["Accept" "Origin"] to contain Cookie`,
+		`header Vary: expected to be absent got ["Accept" "Origin"]`,
+		`header Vary: expected ["Origin" "Accept"] got ["Accept" "Origin"]`,
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
+
+func TestResponseJSONTolerant(t *testing.T) {
+	now := time.Now()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"price":10.000000001,"ratio":0.3,"created_at":%q,"unix":%d,"name":"x"}`, now.Add(-2*time.Second).Format(time.RFC3339Nano), now.Unix())
+	}))
+	defer server.Close()
+
+	httptester.New(t, server.URL).GET("/").Do().
+		JSONNumberNear("$.price", 10, 1e-6).
+		JSONNumberNear("$.ratio", 0.1+0.2, 1e-9).
+		JSONTimeWithin("$.created_at", now, 5*time.Second).
+		JSONTimeWithin("$.unix", now, time.Second)
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.GET("/").Do().
+		JSONNumberNear("$.price", 11, 0.5).
+		JSONNumberNear("$.name", 1, 1).
+		JSONTimeWithin("$.created_at", now, time.Second).
+		JSONTimeWithin("$.name", now, time.Second)
+
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		"JSONPath $.price: expected 11 ± 0.5 got 10.000000001",
+		`JSONPath $.name: expected number got "x"`,
+		"JSONPath $.created_at: expected " + now.Format(time.RFC3339Nano) + " ± 1s got",
+		`JSONPath $.name: expected RFC 3339 timestamp got "x"`,
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
+
+func TestJSONAs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":1,"name":"alice","tags":["a"]}`))
+	}))
+	defer server.Close()
+
+	type user struct {
+		ID   int      `json:"id"`
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+
+	c := httptester.New(t, server.URL)
+
+	u, res := httptester.JSONAs[user](c.GET("/").Do().Status(200))
+	if u.ID != 1 || u.Name != "alice" || len(u.Tags) != 1 {
+		t.Fatalf("unexpected user %+v", u)
+	}
+	res.JSONPath("$.id", 1)
+
+	m, _ := httptester.JSONAs[map[string]interface{}](c.GET("/").Do())
+	if m["name"] != "alice" {
+		t.Fatalf("unexpected map %v", m)
+	}
+
+	if u, res := httptester.JSONAs[user](nil); res != nil || u.ID != 0 {
+		t.Fatalf("expected zero value got %+v %v", u, res)
+	}
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	if n, _ := httptester.JSONAs[int](b.GET("/").Do()); n != 0 || len(errs) != 1 {
+		t.Fatalf("expected decode error got %d %v", n, errs)
+	}
+}
+
+func TestResponseMatches(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"a1b2","status":"pending","created":"2024-01-02"}`))
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	c.GET("/").Do().
+		Matches(`"id":"[0-9a-f]+"`).
+		Matches(regexp.MustCompile(`(?i)"STATUS":"(pending|done)"`))
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.GET("/").Do().
+		Matches(`"status":"done"`).
+		Matches(`"id":"[0-9a-f]+","status":"done"`).
+		Matches(`^\[`).
+		Matches(`(`).
+		Matches(42)
+
+	expected := []string{
+		`body does not match "status":"done": expected done" at offset 23: {"id":"a1b2","status":">>>pending","created":"2024-01-02"}`,
+		`body does not match "id":"[0-9a-f]+","status":"done": expected done" at offset 23: {"id":"a1b2","status":">>>pending`,
+		`body does not match ^\[: expected \[ at offset 0: >>>{"id":"a1b2"`,
+		"invalid regular expression (: error parsing regexp",
+		"Matches requires a string or *regexp.Regexp got int",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors got %v", len(expected), errs)
+	}
+	for i, msg := range expected {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
+
+func TestResponseNegativeMatchers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("X-Debug", "off")
+		w.Header().Add("X-Debug", "trace")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("internal error\ngoroutine 1 [running]:\nmain.handler()"))
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	c.GET("/").Do().
+		StatusNot(200, 404).
+		NotContains("password").
+		NotEq("ok").
+		HeaderNotEq("X-Debug", "on").
+		HeaderNotEq("X-Missing", "")
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.GET("/").Do().
+		StatusNot(500, 503).
+		NotContains("goroutine").
+		NotEq("internal error\ngoroutine 1 [running]:\nmain.handler()").
+		HeaderNotEq("X-Debug", "trace")
+
+	expected := []string{
+		"expected status not in [500 503] got 500: internal error",
+		"body contains goroutine at offset 15: internal error\n>>>goroutine 1 [running]",
+		"body equals internal error\ngoroutine 1",
+		"header X-Debug: expected not to equal trace",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors got %v", len(expected), errs)
+	}
+	for i, msg := range expected {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package httptester
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+type RetryPolicy struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// RetryStatuses defaults to 502, 503 and 504.
+	RetryStatuses []int
+	// ShouldRetry overrides RetryStatuses and the default transport error
+	// handling when set.
+	ShouldRetry func(res *http.Response, err error) bool
+}
+
+func DefaultRetryPolicy(maxRetries int) RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     maxRetries,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		RetryStatuses:  []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+	}
+}
+
+func (p RetryPolicy) shouldRetry(res *http.Response, err error) bool {
+	if p.ShouldRetry != nil {
+		return p.ShouldRetry(res, err)
+	}
+
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, ErrCircuitOpen)
+	}
+
+	statuses := p.RetryStatuses
+	if statuses == nil {
+		statuses = DefaultRetryPolicy(0).RetryStatuses
+	}
+
+	for _, status := range statuses {
+		if res.StatusCode == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	for i := 0; i < attempt; i++ {
+		backoff = time.Duration(float64(backoff) * multiplier)
+		if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+
+	return backoff
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,232 @@
+package httptester
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Scenario runs named steps in order against Clients sharing the same
+// options and Vars. A step stops at its first error and the remaining steps
+// are skipped. Step teardowns always run once the step was started.
+type Scenario struct {
+	name  string
+	opts  []ClientOption
+	vars  *Vars
+	steps []*ScenarioStep
+}
+
+type ScenarioStep struct {
+	name     string
+	run      func(c *Client)
+	setup    func(c *Client)
+	teardown func(c *Client)
+}
+
+type StepResult struct {
+	Name     string
+	Duration time.Duration
+	Errors   []error
+	Skipped  bool
+}
+
+func (r StepResult) Failed() bool {
+	return len(r.Errors) > 0
+}
+
+type ScenarioReport struct {
+	Name     string
+	Duration time.Duration
+	Steps    []StepResult
+}
+
+// NewScenario returns a Scenario whose steps use Clients configured with
+// opts. Errors are always collected by the scenario so WithOnError has no
+// effect.
+func NewScenario(name string, opts ...ClientOption) *Scenario {
+	return &Scenario{
+		name: name,
+		opts: opts,
+		vars: NewVars(),
+	}
+}
+
+// Vars returns the variables shared by all steps.
+func (s *Scenario) Vars() *Vars {
+	return s.vars
+}
+
+func (s *Scenario) Step(name string, run func(c *Client)) *ScenarioStep {
+	step := &ScenarioStep{
+		name: name,
+		run:  run,
+	}
+	s.steps = append(s.steps, step)
+	return step
+}
+
+// Setup runs f before the step. The step is not run if setup fails.
+func (st *ScenarioStep) Setup(f func(c *Client)) *ScenarioStep {
+	st.setup = f
+	return st
+}
+
+// Teardown runs f after the step even if the step or its setup failed.
+func (st *ScenarioStep) Teardown(f func(c *Client)) *ScenarioStep {
+	st.teardown = f
+	return st
+}
+
+// Execute runs all steps and returns the report.
+func (s *Scenario) Execute() *ScenarioReport {
+	start := time.Now()
+
+	report := &ScenarioReport{
+		Name: s.name,
+	}
+
+	failed := false
+
+	for _, step := range s.steps {
+		if failed {
+			report.Steps = append(report.Steps, StepResult{
+				Name:    step.name,
+				Skipped: true,
+			})
+			continue
+		}
+
+		result := s.runStep(step)
+		failed = result.Failed()
+		report.Steps = append(report.Steps, result)
+	}
+
+	report.Duration = time.Since(start)
+
+	return report
+}
+
+// Run executes the scenario, logs the report and fails the test if any step
+// failed.
+func (s *Scenario) Run(t testing.TB) *ScenarioReport {
+	t.Helper()
+
+	report := s.Execute()
+
+	if report.Failed() {
+		t.Fatal(report.String())
+	} else {
+		t.Log(report.String())
+	}
+
+	return report
+}
+
+func (s *Scenario) runStep(step *ScenarioStep) StepResult {
+	start := time.Now()
+
+	var mu sync.Mutex
+	var errs []error
+
+	record := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		errs = append(errs, err)
+	}
+
+	opts := append(append([]ClientOption{}, s.opts...), WithVars(s.vars), WithOnError(func(err error) {
+		record(err)
+		runtime.Goexit()
+	}))
+	client := NewClient(opts...)
+
+	// Each phase runs in its own goroutine so that the first error can stop
+	// it with runtime.Goexit, like t.FailNow does for tests.
+	phase := func(f func(c *Client)) bool {
+		done := make(chan bool)
+
+		go func() {
+			ok := false
+			defer func() {
+				if r := recover(); r != nil {
+					record(fmt.Errorf("panic: %v", r))
+				}
+				done <- ok
+			}()
+
+			f(client)
+			ok = true
+		}()
+
+		return <-done
+	}
+
+	if step.setup == nil || phase(step.setup) {
+		phase(step.run)
+	}
+
+	if step.teardown != nil {
+		phase(step.teardown)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	return StepResult{
+		Name:     step.name,
+		Duration: time.Since(start),
+		Errors:   errs,
+	}
+}
+
+func (r *ScenarioReport) Failed() bool {
+	for _, step := range r.Steps {
+		if step.Failed() {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns the errors of all steps prefixed with the step name.
+func (r *ScenarioReport) Err() error {
+	var errs []error
+	for _, step := range r.Steps {
+		for _, err := range step.Errors {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", r.Name, step.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// String returns a summary with one line per step.
+func (r *ScenarioReport) String() string {
+	sb := &strings.Builder{}
+
+	status := "ok"
+	if r.Failed() {
+		status = "FAIL"
+	}
+	fmt.Fprintf(sb, "scenario %s: %s (%s)\n", r.Name, status, r.Duration.Round(time.Millisecond))
+
+	for _, step := range r.Steps {
+		switch {
+		case step.Skipped:
+			fmt.Fprintf(sb, "  SKIP %s\n", step.Name)
+		case step.Failed():
+			fmt.Fprintf(sb, "  FAIL %s (%s)\n", step.Name, step.Duration.Round(time.Millisecond))
+			for _, err := range step.Errors {
+				fmt.Fprintf(sb, "       %s\n", strings.ReplaceAll(err.Error(), "\n", "\n       "))
+			}
+		default:
+			fmt.Fprintf(sb, "  ok   %s (%s)\n", step.Name, step.Duration.Round(time.Millisecond))
+		}
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -0,0 +1,93 @@
+package httptester_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestScenario(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/carts":
+			w.WriteHeader(201)
+			w.Write([]byte(`{"id":"c1"}`))
+		case r.Method == "POST" && r.URL.Path == "/carts/c1/pay":
+			w.WriteHeader(402)
+			w.Write([]byte(`{"error":"declined"}`))
+		default:
+			fmt.Fprintf(w, `{"path":%q}`, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	var calls []string
+
+	s := httptester.NewScenario("checkout", httptester.WithBaseURL(server.URL))
+
+	s.Step("create cart", func(c *httptester.Client) {
+		c.POST("/carts").Do().Status(201).Extract("cart", "$.id")
+	}).Setup(func(c *httptester.Client) {
+		calls = append(calls, "setup")
+	})
+
+	s.Step("view cart", func(c *httptester.Client) {
+		c.GET("/carts/{{cart}}").Do().JSONPath("$.path", "/carts/c1")
+	})
+
+	s.Step("pay", func(c *httptester.Client) {
+		c.POST("/carts/{{cart}}/pay").Do().Status(200)
+		calls = append(calls, "after pay")
+	}).Teardown(func(c *httptester.Client) {
+		calls = append(calls, "teardown")
+	})
+
+	s.Step("confirm", func(c *httptester.Client) {
+		calls = append(calls, "confirm")
+	})
+
+	report := s.Execute()
+
+	if !report.Failed() {
+		t.Fatal("expected scenario to fail")
+	}
+	if strings.Join(calls, ",") != "setup,teardown" {
+		t.Fatalf("unexpected calls %v", calls)
+	}
+	if s.Vars().String("cart") != "c1" {
+		t.Fatalf("unexpected cart %s", s.Vars().String("cart"))
+	}
+
+	steps := report.Steps
+	if len(steps) != 4 || steps[0].Failed() || steps[1].Failed() || !steps[2].Failed() || !steps[3].Skipped {
+		t.Fatalf("unexpected steps %+v", steps)
+	}
+
+	if err := report.Err(); err == nil || !strings.Contains(err.Error(), "checkout: pay: POST") {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	summary := report.String()
+	for _, line := range []string{"scenario checkout: FAIL", "  ok   create cart", "  FAIL pay", "expected status [200] got 402", "  SKIP confirm"} {
+		if !strings.Contains(summary, line) {
+			t.Fatalf("summary does not contain %q: %s", line, summary)
+		}
+	}
+}
+
+func TestScenarioPanic(t *testing.T) {
+	s := httptester.NewScenario("panic")
+	s.Step("boom", func(c *httptester.Client) {
+		panic("boom")
+	})
+
+	report := s.Execute()
+	if err := report.Err(); err == nil || !strings.Contains(err.Error(), "panic: boom") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,502 @@
+package httptester
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// schemaValidator validates decoded JSON values against JSON Schema (and
+// OpenAPI schema objects, which are a superset/subset depending on version).
+// Schemas are plain decoded JSON so $ref pointers can be resolved against the
+// document they came from.
+type schemaValidator struct {
+	root   interface{}
+	errors []string
+}
+
+func validateSchema(root interface{}, schema interface{}, value interface{}) []string {
+	v := &schemaValidator{
+		root: root,
+	}
+	v.validate(schema, value, "$")
+	return v.errors
+}
+
+func (v *schemaValidator) errorf(path string, format string, args ...interface{}) {
+	v.errors = append(v.errors, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (v *schemaValidator) resolveRef(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported $ref %s: only local references are supported", ref)
+	}
+
+	pointer, err := url.PathUnescape(ref[1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid $ref %s: %w", ref, err)
+	}
+
+	current := v.root
+	if pointer == "" {
+		return current, nil
+	}
+
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		switch c := current.(type) {
+		case map[string]interface{}:
+			next, ok := c[token]
+			if !ok {
+				return nil, fmt.Errorf("invalid $ref %s: %s not found", ref, token)
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(c) {
+				return nil, fmt.Errorf("invalid $ref %s: %s not found", ref, token)
+			}
+			current = c[index]
+		default:
+			return nil, fmt.Errorf("invalid $ref %s: %s not found", ref, token)
+		}
+	}
+
+	return current, nil
+}
+
+func (v *schemaValidator) validate(schema interface{}, value interface{}, path string) {
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			v.errorf(path, "not allowed by schema")
+		}
+		return
+	case map[string]interface{}:
+		v.validateObjectSchema(s, value, path)
+	}
+}
+
+func (v *schemaValidator) valid(schema interface{}, value interface{}, path string) bool {
+	sub := &schemaValidator{
+		root: v.root,
+	}
+	sub.validate(schema, value, path)
+	return len(sub.errors) == 0
+}
+
+// unsupportedSchemaKeywords are JSON Schema keywords the validator does not
+// implement. They are reported instead of ignored so a schema that relies on
+// them does not pass silently.
+var unsupportedSchemaKeywords = []string{
+	"$anchor",
+	"$dynamicAnchor",
+	"$dynamicRef",
+	"$recursiveAnchor",
+	"$recursiveRef",
+	"dependencies",
+	"dependentSchemas",
+	"unevaluatedItems",
+	"unevaluatedProperties",
+}
+
+func (v *schemaValidator) checkKeywords(s map[string]interface{}, path string) {
+	for _, keyword := range unsupportedSchemaKeywords {
+		if _, ok := s[keyword]; ok {
+			v.errorf(path, "unsupported schema keyword %s", keyword)
+		}
+	}
+
+	// $id on the root schema only names the document. On a subschema it
+	// changes the base URI of the $refs inside it, which is not supported.
+	if _, ok := s["$id"]; ok {
+		if root, isMap := v.root.(map[string]interface{}); !isMap || reflect.ValueOf(root).Pointer() != reflect.ValueOf(s).Pointer() {
+			v.errorf(path, "unsupported schema keyword $id in a subschema")
+		}
+	}
+}
+
+func (v *schemaValidator) validateObjectSchema(s map[string]interface{}, value interface{}, path string) {
+	v.checkKeywords(s, path)
+
+	if ref, ok := s["$ref"].(string); ok {
+		resolved, err := v.resolveRef(ref)
+		if err != nil {
+			v.errorf(path, "%s", err)
+			return
+		}
+		v.validate(resolved, value, path)
+	}
+
+	// OpenAPI 3.0
+	if nullable, _ := s["nullable"].(bool); nullable && value == nil {
+		return
+	}
+
+	if t, ok := s["type"]; ok {
+		v.validateType(t, value, path)
+	}
+
+	if enum, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if reflect.DeepEqual(e, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.errorf(path, "expected one of %s got %s", jsonString(enum), jsonString(value))
+		}
+	}
+
+	if c, ok := s["const"]; ok && !reflect.DeepEqual(c, value) {
+		v.errorf(path, "expected %s got %s", jsonString(c), jsonString(value))
+	}
+
+	for _, sub := range schemaList(s["allOf"]) {
+		v.validate(sub, value, path)
+	}
+
+	if anyOf := schemaList(s["anyOf"]); len(anyOf) > 0 {
+		matched := false
+		for _, sub := range anyOf {
+			if v.valid(sub, value, path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.errorf(path, "does not match any schema in anyOf")
+		}
+	}
+
+	if oneOf := schemaList(s["oneOf"]); len(oneOf) > 0 {
+		matched := 0
+		for _, sub := range oneOf {
+			if v.valid(sub, value, path) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			v.errorf(path, "expected to match exactly one schema in oneOf, matched %d", matched)
+		}
+	}
+
+	if not, ok := s["not"]; ok && v.valid(not, value, path) {
+		v.errorf(path, "must not match schema in not")
+	}
+
+	if cond, ok := s["if"]; ok {
+		if v.valid(cond, value, path) {
+			if then, ok := s["then"]; ok {
+				v.validate(then, value, path)
+			}
+		} else if els, ok := s["else"]; ok {
+			v.validate(els, value, path)
+		}
+	}
+
+	switch val := value.(type) {
+	case string:
+		v.validateString(s, val, path)
+	case float64:
+		v.validateNumber(s, val, path)
+	case []interface{}:
+		v.validateArray(s, val, path)
+	case map[string]interface{}:
+		v.validateObject(s, val, path)
+	}
+}
+
+func (v *schemaValidator) validateType(t interface{}, value interface{}, path string) {
+	var types []string
+	switch tt := t.(type) {
+	case string:
+		types = []string{tt}
+	case []interface{}:
+		for _, x := range tt {
+			if s, ok := x.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+
+	actual := jsonType(value)
+	for _, expected := range types {
+		if expected == actual || (expected == "number" && actual == "integer") {
+			return
+		}
+	}
+
+	v.errorf(path, "expected %s got %s", strings.Join(types, " or "), actual)
+}
+
+func (v *schemaValidator) validateString(s map[string]interface{}, value string, path string) {
+	length := utf8.RuneCountInString(value)
+
+	if min, ok := schemaNumber(s["minLength"]); ok && float64(length) < min {
+		v.errorf(path, "expected length >= %v got %d", min, length)
+	}
+
+	if max, ok := schemaNumber(s["maxLength"]); ok && float64(length) > max {
+		v.errorf(path, "expected length <= %v got %d", max, length)
+	}
+
+	if pattern, ok := s["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			v.errorf(path, "invalid pattern %s: %s", pattern, err)
+		} else if !re.MatchString(value) {
+			v.errorf(path, "%s does not match pattern %s", jsonString(value), pattern)
+		}
+	}
+
+	if format, ok := s["format"].(string); ok && !validFormat(format, value) {
+		v.errorf(path, "%s is not a valid %s", jsonString(value), format)
+	}
+}
+
+func (v *schemaValidator) validateNumber(s map[string]interface{}, value float64, path string) {
+	if min, ok := schemaNumber(s["minimum"]); ok {
+		if exclusive, _ := s["exclusiveMinimum"].(bool); exclusive && value <= min {
+			v.errorf(path, "expected > %v got %v", min, value)
+		} else if value < min {
+			v.errorf(path, "expected >= %v got %v", min, value)
+		}
+	}
+
+	if max, ok := schemaNumber(s["maximum"]); ok {
+		if exclusive, _ := s["exclusiveMaximum"].(bool); exclusive && value >= max {
+			v.errorf(path, "expected < %v got %v", max, value)
+		} else if value > max {
+			v.errorf(path, "expected <= %v got %v", max, value)
+		}
+	}
+
+	if min, ok := schemaNumber(s["exclusiveMinimum"]); ok && value <= min {
+		v.errorf(path, "expected > %v got %v", min, value)
+	}
+
+	if max, ok := schemaNumber(s["exclusiveMaximum"]); ok && value >= max {
+		v.errorf(path, "expected < %v got %v", max, value)
+	}
+
+	if multipleOf, ok := schemaNumber(s["multipleOf"]); ok && multipleOf > 0 {
+		quotient := value / multipleOf
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			v.errorf(path, "expected multiple of %v got %v", multipleOf, value)
+		}
+	}
+}
+
+func (v *schemaValidator) validateArray(s map[string]interface{}, value []interface{}, path string) {
+	if min, ok := schemaNumber(s["minItems"]); ok && float64(len(value)) < min {
+		v.errorf(path, "expected at least %v items got %d", min, len(value))
+	}
+
+	if max, ok := schemaNumber(s["maxItems"]); ok && float64(len(value)) > max {
+		v.errorf(path, "expected at most %v items got %d", max, len(value))
+	}
+
+	if unique, _ := s["uniqueItems"].(bool); unique {
+		for i := range value {
+			for j := i + 1; j < len(value); j++ {
+				if reflect.DeepEqual(value[i], value[j]) {
+					v.errorf(path, "items [%d] and [%d] are equal", i, j)
+				}
+			}
+		}
+	}
+
+	prefixItems := schemaList(s["prefixItems"])
+	items, hasItems := s["items"]
+	additionalItems, hasAdditionalItems := s["additionalItems"]
+
+	// draft-07 and older tuple validation
+	if tuple, ok := items.([]interface{}); ok {
+		prefixItems = tuple
+		items, hasItems = additionalItems, hasAdditionalItems
+	}
+
+	for i, item := range value {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		if i < len(prefixItems) {
+			v.validate(prefixItems[i], item, itemPath)
+		} else if hasItems {
+			v.validate(items, item, itemPath)
+		}
+	}
+
+	if contains, ok := s["contains"]; ok {
+		count := 0
+		for i, item := range value {
+			if v.valid(contains, item, fmt.Sprintf("%s[%d]", path, i)) {
+				count++
+			}
+		}
+
+		min := 1.0
+		if m, ok := schemaNumber(s["minContains"]); ok {
+			min = m
+		}
+		if float64(count) < min {
+			v.errorf(path, "expected at least %v items matching contains got %d", min, count)
+		}
+		if max, ok := schemaNumber(s["maxContains"]); ok && float64(count) > max {
+			v.errorf(path, "expected at most %v items matching contains got %d", max, count)
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(s map[string]interface{}, value map[string]interface{}, path string) {
+	if min, ok := schemaNumber(s["minProperties"]); ok && float64(len(value)) < min {
+		v.errorf(path, "expected at least %v properties got %d", min, len(value))
+	}
+
+	if max, ok := schemaNumber(s["maxProperties"]); ok && float64(len(value)) > max {
+		v.errorf(path, "expected at most %v properties got %d", max, len(value))
+	}
+
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, r := range required {
+			if name, ok := r.(string); ok {
+				if _, ok := value[name]; !ok {
+					v.errorf(path, "missing required property %s", name)
+				}
+			}
+		}
+	}
+
+	if dependentRequired, ok := s["dependentRequired"].(map[string]interface{}); ok {
+		for name, deps := range dependentRequired {
+			if _, ok := value[name]; !ok {
+				continue
+			}
+			for _, dep := range schemaList(deps) {
+				if depName, ok := dep.(string); ok {
+					if _, ok := value[depName]; !ok {
+						v.errorf(path, "property %s requires property %s", name, depName)
+					}
+				}
+			}
+		}
+	}
+
+	properties, _ := s["properties"].(map[string]interface{})
+	patternProperties, _ := s["patternProperties"].(map[string]interface{})
+	additionalProperties, hasAdditionalProperties := s["additionalProperties"]
+	propertyNames, hasPropertyNames := s["propertyNames"]
+
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propValue := value[name]
+		propPath := path + jsonPathSegment{key: name}.String()
+		evaluated := false
+
+		if hasPropertyNames {
+			v.validate(propertyNames, name, propPath)
+		}
+
+		if propSchema, ok := properties[name]; ok {
+			v.validate(propSchema, propValue, propPath)
+			evaluated = true
+		}
+
+		for pattern, patternSchema := range patternProperties {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				v.errorf(path, "invalid pattern %s: %s", pattern, err)
+				continue
+			}
+			if re.MatchString(name) {
+				v.validate(patternSchema, propValue, propPath)
+				evaluated = true
+			}
+		}
+
+		if !evaluated && hasAdditionalProperties {
+			if allowed, ok := additionalProperties.(bool); ok && !allowed {
+				v.errorf(path, "additional property %s is not allowed", name)
+			} else {
+				v.validate(additionalProperties, propValue, propPath)
+			}
+		}
+	}
+}
+
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func schemaList(v interface{}) []interface{} {
+	list, _ := v.([]interface{})
+	return list
+}
+
+func schemaNumber(v interface{}) (float64, bool) {
+	n, ok := v.(float64)
+	return n, ok
+}
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// validFormat checks the commonly used formats. Unknown formats are accepted.
+func validFormat(format string, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "email":
+		at := strings.LastIndexByte(value, '@')
+		return at > 0 && at < len(value)-1
+	case "uuid":
+		return uuidRegexp.MatchString(value)
+	case "uri":
+		u, err := url.Parse(value)
+		return err == nil && u.Scheme != ""
+	case "ipv4":
+		ip := net.ParseIP(value)
+		return ip != nil && ip.To4() != nil && !strings.Contains(value, ":")
+	case "ipv6":
+		ip := net.ParseIP(value)
+		return ip != nil && strings.Contains(value, ":")
+	default:
+		return true
+	}
+}
@@ -0,0 +1,237 @@
+package httptester
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SecurityHeaders checks the baseline hardening headers:
+//   - Strict-Transport-Security with a positive max-age
+//   - X-Content-Type-Options: nosniff
+//   - X-Frame-Options DENY or SAMEORIGIN, or a CSP frame-ancestors directive
+//   - Referrer-Policy other than unsafe-url
+//   - Content-Security-Policy
+//
+// All missing headers are reported in a single error.
+func (r *Response) SecurityHeaders() *Response {
+	r.helper()
+	defer r.track("SecurityHeaders")()
+
+	problems := []interface{}{}
+
+	if _, err := r.hsts("SecurityHeaders"); err != nil {
+		problems = append(problems, err)
+	}
+
+	if v := r.Header.Get("X-Content-Type-Options"); !strings.EqualFold(strings.TrimSpace(v), "nosniff") {
+		problems = append(problems, r.headerError("SecurityHeaders", "X-Content-Type-Options", "nosniff", "X-Content-Type-Options: expected nosniff got %q", v))
+	}
+
+	csp := r.CSP()
+
+	if _, ok := csp["frame-ancestors"]; !ok {
+		switch v := strings.ToUpper(strings.TrimSpace(r.Header.Get("X-Frame-Options"))); v {
+		case "DENY", "SAMEORIGIN":
+		default:
+			problems = append(problems, r.headerError("SecurityHeaders", "X-Frame-Options", "DENY", "X-Frame-Options: expected DENY or SAMEORIGIN got %q", v))
+		}
+	}
+
+	if v := r.Header.Get("Referrer-Policy"); v == "" || strings.EqualFold(strings.TrimSpace(v), "unsafe-url") {
+		problems = append(problems, r.headerError("SecurityHeaders", "Referrer-Policy", "", "Referrer-Policy: expected a policy other than unsafe-url got %q", v))
+	}
+
+	if csp == nil {
+		problems = append(problems, r.headerError("SecurityHeaders", "Content-Security-Policy", "", "Content-Security-Policy: missing"))
+	}
+
+	// Every problem is wrapped so errors.As finds each HeaderError.
+	if len(problems) > 0 {
+		r.err(fmt.Errorf("missing security headers:"+strings.Repeat("\n  %w", len(problems)), problems...))
+	}
+
+	return r
+}
+
+type hstsPolicy struct {
+	maxAge            time.Duration
+	includeSubDomains bool
+	preload           bool
+}
+
+func (r *Response) hsts(assertion string) (hstsPolicy, error) {
+	header := r.Header.Get("Strict-Transport-Security")
+	if header == "" {
+		return hstsPolicy{}, r.headerError(assertion, "Strict-Transport-Security", "", "Strict-Transport-Security: missing")
+	}
+
+	policy := hstsPolicy{}
+	hasMaxAge := false
+	for _, directive := range strings.Split(header, ";") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "max-age":
+			seconds, err := strconv.ParseInt(strings.Trim(strings.TrimSpace(value), `"`), 10, 64)
+			if err != nil {
+				return policy, r.headerError(assertion, "Strict-Transport-Security", "", "Strict-Transport-Security: invalid max-age %q", value)
+			}
+			policy.maxAge = time.Duration(seconds) * time.Second
+			hasMaxAge = true
+		case "includesubdomains":
+			policy.includeSubDomains = true
+		case "preload":
+			policy.preload = true
+		}
+	}
+
+	if !hasMaxAge || policy.maxAge <= 0 {
+		return policy, r.headerError(assertion, "Strict-Transport-Security", "", "Strict-Transport-Security: expected a positive max-age got %q", header)
+	}
+
+	return policy, nil
+}
+
+// HSTS checks that Strict-Transport-Security has a max-age of at least
+// minMaxAge and includeSubDomains if includeSubDomains is true.
+func (r *Response) HSTS(minMaxAge time.Duration, includeSubDomains bool) *Response {
+	r.helper()
+	defer r.track("HSTS", minMaxAge, includeSubDomains)()
+
+	policy, err := r.hsts("HSTS")
+	if err != nil {
+		r.err(err)
+		return r
+	}
+
+	if policy.maxAge < minMaxAge {
+		r.err(r.headerError("HSTS", "Strict-Transport-Security", fmt.Sprintf("max-age=%d", int64(minMaxAge/time.Second)), "Strict-Transport-Security: expected max-age at least %s got %s", minMaxAge, policy.maxAge))
+	}
+	if includeSubDomains && !policy.includeSubDomains {
+		r.err(r.headerError("HSTS", "Strict-Transport-Security", "includeSubDomains", "Strict-Transport-Security: expected includeSubDomains"))
+	}
+
+	return r
+}
+
+// CSP returns the Content-Security-Policy directives with their sources, or
+// nil if the response has no policy. Directive names are lowercase and the
+// first occurrence of a directive wins.
+func (r *Response) CSP() map[string][]string {
+	values := r.Header.Values("Content-Security-Policy")
+	if len(values) == 0 {
+		return nil
+	}
+
+	return parseCSP(strings.Join(values, ";"))
+}
+
+func parseCSP(policy string) map[string][]string {
+	directives := map[string][]string{}
+
+	for _, directive := range strings.Split(policy, ";") {
+		fields := strings.Fields(directive)
+		if len(fields) == 0 {
+			continue
+		}
+		name := strings.ToLower(fields[0])
+		if _, ok := directives[name]; ok {
+			continue
+		}
+		directives[name] = fields[1:]
+	}
+
+	return directives
+}
+
+// cspFetchDirectives fall back to default-src when they are not set.
+var cspFetchDirectives = map[string]bool{
+	"child-src":       true,
+	"connect-src":     true,
+	"font-src":        true,
+	"frame-src":       true,
+	"img-src":         true,
+	"manifest-src":    true,
+	"media-src":       true,
+	"object-src":      true,
+	"script-src":      true,
+	"script-src-elem": true,
+	"script-src-attr": true,
+	"style-src":       true,
+	"style-src-elem":  true,
+	"style-src-attr":  true,
+	"worker-src":      true,
+}
+
+// cspDirective returns the sources effective for directive, following the
+// default-src fallback of fetch directives.
+func (r *Response) cspDirective(assertion string, directive string) ([]string, bool) {
+	r.helper()
+
+	csp := r.CSP()
+	if csp == nil {
+		r.err(r.headerError(assertion, "Content-Security-Policy", directive, "response has no Content-Security-Policy"))
+		return nil, false
+	}
+
+	directive = strings.ToLower(directive)
+	if sources, ok := csp[directive]; ok {
+		return sources, true
+	}
+	if cspFetchDirectives[directive] {
+		if sources, ok := csp["default-src"]; ok {
+			return sources, true
+		}
+	}
+
+	r.err(r.headerError(assertion, "Content-Security-Policy", directive, "Content-Security-Policy has no %s directive", directive))
+	return nil, false
+}
+
+// CSPDirective checks that the Content-Security-Policy directive allows all
+// sources, e.g. CSPDirective("script-src", "'self'").
+func (r *Response) CSPDirective(directive string, sources ...string) *Response {
+	r.helper()
+	defer r.track("CSPDirective", directive, sources)()
+
+	actual, ok := r.cspDirective("CSPDirective", directive)
+	if !ok {
+		return r
+	}
+
+	for _, source := range sources {
+		if !containsFold(actual, source) {
+			r.err(r.headerError("CSPDirective", "Content-Security-Policy", directive+" "+source, "Content-Security-Policy %s: expected %s in %q", directive, source, actual))
+		}
+	}
+
+	return r
+}
+
+// CSPDisallows checks that the Content-Security-Policy directive does not
+// allow source, e.g. CSPDisallows("script-src", "'unsafe-inline'").
+func (r *Response) CSPDisallows(directive string, source string) *Response {
+	r.helper()
+	defer r.track("CSPDisallows", directive, source)()
+
+	actual, ok := r.cspDirective("CSPDisallows", directive)
+	if !ok {
+		return r
+	}
+
+	if containsFold(actual, source) {
+		r.err(r.headerError("CSPDisallows", "Content-Security-Policy", directive+" "+source, "Content-Security-Policy %s: expected %s not to be allowed in %q", directive, source, actual))
+	}
+
+	return r
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bancek/httptester"
+)
+
+func TestResponseSecurityHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/hardened" {
+			w.Header().Set("Strict-Transport-Security", "max-age=63072000; includeSubDomains; preload")
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+			w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
+			w.Header().Set("Content-Security-Policy", "default-src 'self'; img-src 'self' data:; frame-ancestors 'none'")
+		}
+		w.Header().Set("X-Frame-Options", "ALLOWALL")
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	res := c.GET("/hardened").Do().
+		SecurityHeaders().
+		HSTS(365*24*time.Hour, true).
+		CSPDirective("img-src", "data:", "'SELF'").
+		CSPDirective("script-src", "'self'").
+		CSPDisallows("script-src", "'unsafe-inline'")
+
+	if sources := res.CSP()["frame-ancestors"]; len(sources) != 1 || sources[0] != "'none'" {
+		t.Fatalf("expected frame-ancestors 'none' got %q", sources)
+	}
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.GET("/").Do().SecurityHeaders().HSTS(time.Hour, false)
+	b.GET("/hardened").Do().
+		HSTS(3*365*24*time.Hour, true).
+		CSPDirective("img-src", "https:").
+		CSPDisallows("img-src", "data:").
+		CSPDirective("form-action", "'self'")
+
+	if len(errs) != 6 {
+		t.Fatalf("expected 6 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		"missing security headers:\n  Strict-Transport-Security: missing\n  X-Content-Type-Options: expected nosniff got \"\"\n  X-Frame-Options: expected DENY or SAMEORIGIN got \"ALLOWALL\"\n  Referrer-Policy: expected a policy other than unsafe-url got \"\"\n  Content-Security-Policy: missing",
+		"Strict-Transport-Security: missing",
+		"Strict-Transport-Security: expected max-age at least 26280h0m0s got 17520h0m0s",
+		`Content-Security-Policy img-src: expected https: in ["'self'" "data:"]`,
+		`Content-Security-Policy img-src: expected data: not to be allowed`,
+		"Content-Security-Policy has no form-action directive",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package httptester_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// verifySigV4 checks the signature of requests to /{bucket}/a%20b.txt?list-type=2&prefix=x.
+func verifySigV4(r *http.Request, service string, body string) string {
+	authRegexp := regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=AKID/(\d{8})/eu-west-1/` + service + `/aws4_request, SignedHeaders=([^,]+), Signature=([0-9a-f]{64})$`)
+
+	m := authRegexp.FindStringSubmatch(r.Header.Get("Authorization"))
+	if m == nil {
+		return "invalid authorization " + r.Header.Get("Authorization")
+	}
+	date, signedHeaders, signature := m[1], m[2], m[3]
+
+	headers := ""
+	for _, name := range strings.Split(signedHeaders, ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		headers += name + ":" + strings.Join(strings.Fields(value), " ") + "\n"
+	}
+
+	path := "/bucket/a%20b.txt"
+	if service != "s3" {
+		path = "/bucket/a%2520b.txt"
+	}
+
+	canonicalRequest := r.Method + "\n" + path + "\nlist-type=2&prefix=x%2Fy\n" + headers + "\n" + signedHeaders + "\n" + sha256Hex(body)
+	stringToSign := "AWS4-HMAC-SHA256\n" + r.Header.Get("X-Amz-Date") + "\n" + date + "/eu-west-1/" + service + "/aws4_request\n" + sha256Hex(canonicalRequest)
+
+	key := hmacSHA256([]byte("AWS4secret"), date)
+	key = hmacSHA256(key, "eu-west-1")
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+
+	if expected := hex.EncodeToString(hmacSHA256(key, stringToSign)); signature != expected {
+		return "signature mismatch for canonical request\n" + canonicalRequest
+	}
+
+	return ""
+}
+
+func TestReqBuilderSigV4(t *testing.T) {
+	for _, service := range []string{"s3", "execute-api"} {
+		t.Run(service, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if msg := verifySigV4(r, service, string(body)); msg != "" {
+					w.WriteHeader(403)
+					w.Write([]byte(msg))
+					return
+				}
+				w.Write([]byte(r.Header.Get("X-Amz-Security-Token") + " " + r.Header.Get("X-Amz-Content-Sha256")))
+			}))
+			defer server.Close()
+
+			c := httptester.New(t, server.URL)
+			credentials := httptester.AWSCredentials{
+				AccessKeyID:     "AKID",
+				SecretAccessKey: "secret",
+				SessionToken:    "session",
+			}
+
+			res := c.PUT("/bucket/{key}").Param("key", "a b.txt").Q("prefix", "x/y", "list-type", "2").
+				Header("X-Amz-Meta-Owner", "  ann   smith ").
+				JSON(map[string]string{"a": "b"}).
+				SigV4("eu-west-1", service, credentials).
+				Do().Status(200)
+
+			expected := "session "
+			if service == "s3" {
+				expected += sha256Hex(`{"a":"b"}`)
+			}
+			res.Eq(expected)
+
+			c.GET("/bucket/{key}").Param("key", "a b.txt").Q("prefix", "x/y", "list-type", "2").
+				SigV4("eu-west-1", service, httptester.AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "wrong"}).
+				Do().Status(403)
+		})
+	}
+}
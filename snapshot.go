@@ -0,0 +1,154 @@
+package httptester
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// SnapshotDir is the directory snapshots are stored in, relative to the
+// package under test.
+var SnapshotDir = filepath.Join("testdata", "snapshots")
+
+// updateSnapshots reports whether HTTPTESTER_UPDATE is set or the test
+// package defines an -update flag that was passed. The flag is not defined
+// here so it does not clash with the test package's own.
+func updateSnapshots() bool {
+	if os.Getenv("HTTPTESTER_UPDATE") != "" {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		update, _ := strconv.ParseBool(f.Value.String())
+		return update
+	}
+	return false
+}
+
+// UpdateSnapshotsFlag defines the -update flag so snapshots are written by
+// go test -update. Test packages without their own -update flag call it
+// from a package level variable:
+//
+//	var _ = httptester.UpdateSnapshotsFlag()
+func UpdateSnapshotsFlag() *bool {
+	return flag.Bool("update", false, "write httptester snapshots")
+}
+
+var (
+	snapshotMu     sync.Mutex
+	snapshotCounts = map[string]int{}
+)
+
+var snapshotNameRegexp = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// snapshotPath returns the path of the next snapshot of the test. The
+// first snapshot of a test is named after the test, following ones get a
+// _2, _3, ... suffix.
+func snapshotPath(t testing.TB, ext string) string {
+	snapshotMu.Lock()
+	defer snapshotMu.Unlock()
+
+	name := t.Name()
+	if snapshotCounts[name] == 0 {
+		t.Cleanup(func() {
+			snapshotMu.Lock()
+			defer snapshotMu.Unlock()
+
+			delete(snapshotCounts, name)
+		})
+	}
+	snapshotCounts[name]++
+
+	file := snapshotNameRegexp.ReplaceAllString(name, "_")
+	if n := snapshotCounts[name]; n > 1 {
+		file += "_" + strconv.Itoa(n)
+	}
+
+	return filepath.Join(SnapshotDir, file+ext)
+}
+
+// normalizeSnapshot formats JSON bodies with sorted keys and indentation so
+// formatting changes of the server do not change the snapshot and applies
+// the masks. Other bodies are returned as is.
+func (r *Response) normalizeSnapshot() ([]byte, bool) {
+	var v interface{}
+	if err := json.Unmarshal(r.Body, &v); err != nil {
+		return r.Body, false
+	}
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r.maskJSON(v)); err != nil {
+		return r.Body, false
+	}
+
+	return buf.Bytes(), true
+}
+
+// MatchSnapshot compares the body with a golden file under SnapshotDir
+// named after the test. JSON bodies are stored normalized and masked (see
+// Mask) and compared field by field. A missing snapshot fails the test.
+// Snapshots are created or rewritten when HTTPTESTER_UPDATE is set, or when
+// the test runs with -update if the test package defines that flag (see
+// UpdateSnapshotsFlag).
+func (r *Response) MatchSnapshot(t testing.TB) *Response {
+	t.Helper()
+	r.helper()
+	defer r.track("MatchSnapshot")()
+
+	body, isJSON := r.normalizeSnapshot()
+
+	ext := ".snap"
+	if isJSON {
+		ext = ".json"
+	}
+	path := snapshotPath(t, ext)
+
+	expected, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !updateSnapshots() {
+		r.err(fmt.Errorf("snapshot %s does not exist (set HTTPTESTER_UPDATE=1 to create it)", path))
+		return r
+	}
+	if errors.Is(err, os.ErrNotExist) || (err == nil && updateSnapshots()) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			r.err(err)
+			return r
+		}
+		if err := os.WriteFile(path, body, 0o644); err != nil {
+			r.err(err)
+			return r
+		}
+		t.Logf("wrote snapshot %s", path)
+		return r
+	}
+	if err != nil {
+		r.err(err)
+		return r
+	}
+
+	if bytes.Equal(expected, body) {
+		return r
+	}
+
+	if isJSON {
+		var e, a interface{}
+		if json.Unmarshal(expected, &e) == nil && json.Unmarshal(body, &a) == nil {
+			if diff := jsonDiff("$", e, a); len(diff) > 0 {
+				r.err(fmt.Errorf("body does not match snapshot %s (set HTTPTESTER_UPDATE=1 to accept):%s", path, formatJSONDiff(diff)))
+			}
+			return r
+		}
+	}
+
+	r.err(fmt.Errorf("body does not match snapshot %s (set HTTPTESTER_UPDATE=1 to accept): %s", path, r.bodyExcerpt()))
+	return r
+}
@@ -0,0 +1,117 @@
+package httptester_test
+
+import (
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+type namedTB struct {
+	testing.TB
+	name string
+}
+
+func (t namedTB) Name() string {
+	return t.name
+}
+
+func TestResponseMatchSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	defer func(old string) { httptester.SnapshotDir = old }(httptester.SnapshotDir)
+	httptester.SnapshotDir = dir
+
+	name := "alice"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/text" {
+			w.Write([]byte("hello " + name))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"` + name + `","id":1}`))
+	}))
+	defer server.Close()
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	// Repeated subtests get a #01 suffix, so use a fixed name.
+	run := func() {
+		t.Run("users", func(t *testing.T) {
+			named := namedTB{t, "TestResponseMatchSnapshot/users"}
+			b.GET("/").Do().MatchSnapshot(named)
+			b.GET("/text").Do().MatchSnapshot(named)
+		})
+	}
+
+	run()
+
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		"snapshot " + filepath.Join(dir, "TestResponseMatchSnapshot_users.json") + " does not exist (set HTTPTESTER_UPDATE=1 to create it)",
+		"snapshot " + filepath.Join(dir, "TestResponseMatchSnapshot_users_2.snap") + " does not exist",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+
+	errs = nil
+	t.Setenv("HTTPTESTER_UPDATE", "1")
+	run()
+	os.Unsetenv("HTTPTESTER_UPDATE")
+
+	data, err := os.ReadFile(filepath.Join(dir, "TestResponseMatchSnapshot_users.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "{\n  \"id\": 1,\n  \"name\": \"alice\"\n}\n" {
+		t.Fatalf("unexpected snapshot %q", data)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "TestResponseMatchSnapshot_users_2.snap")); err != nil || string(data) != "hello alice" {
+		t.Fatalf("unexpected snapshot %q %v", data, err)
+	}
+
+	run()
+
+	name = "bob"
+	run()
+
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		"TestResponseMatchSnapshot_users.json (set HTTPTESTER_UPDATE=1 to accept):\n  ~ $.name: \"alice\" -> \"bob\"",
+		"TestResponseMatchSnapshot_users_2.snap (set HTTPTESTER_UPDATE=1 to accept): hello bob",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+
+	errs = nil
+	os.Setenv("HTTPTESTER_UPDATE", "1")
+	run()
+	os.Unsetenv("HTTPTESTER_UPDATE")
+	run()
+
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors after update got %v", errs)
+	}
+}
+
+func TestSnapshotUpdateFlagNotDefined(t *testing.T) {
+	// Test packages must be able to define their own -update flag.
+	if flag.Lookup("update") != nil {
+		t.Fatal("expected httptester not to define the -update flag")
+	}
+}
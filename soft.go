@@ -0,0 +1,45 @@
+package httptester
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+// SoftAssertions collects errors instead of failing on the first one. Use
+// ReqBuilder.Soft to route a request's errors into it. Note that Do still
+// returns nil when the request itself fails.
+type SoftAssertions struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func NewSoftAssertions() *SoftAssertions {
+	return &SoftAssertions{}
+}
+
+func (s *SoftAssertions) OnError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.errs = append(s.errs, err)
+}
+
+func (s *SoftAssertions) Errors() []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]error(nil), s.errs...)
+}
+
+func (s *SoftAssertions) Err() error {
+	return errors.Join(s.Errors()...)
+}
+
+func (s *SoftAssertions) Report(t testing.TB) {
+	t.Helper()
+
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,285 @@
+package httptester
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  string
+	Retry int
+}
+
+type sseParser struct {
+	scanner *bufio.Scanner
+	// lastID is the last event ID buffer which is kept across events until
+	// an id field resets it.
+	lastID string
+}
+
+func newSSEParser(r io.Reader) *sseParser {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	return &sseParser{
+		scanner: scanner,
+	}
+}
+
+// next returns the next dispatched event or io.EOF when the stream ends.
+func (p *sseParser) next() (SSEEvent, error) {
+	event := SSEEvent{ID: p.lastID}
+	data := []string{}
+	hasData := false
+
+	for p.scanner.Scan() {
+		line := strings.TrimSuffix(p.scanner.Text(), "\r")
+
+		if line == "" {
+			if !hasData {
+				event = SSEEvent{ID: p.lastID}
+				continue
+			}
+			event.Data = strings.Join(data, "\n")
+			if event.Event == "" {
+				event.Event = "message"
+			}
+			return event, nil
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data = append(data, value)
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				p.lastID = value
+				event.ID = value
+			}
+		case "retry":
+			if retry, err := strconv.Atoi(value); err == nil {
+				event.Retry = retry
+			}
+		}
+	}
+
+	if err := p.scanner.Err(); err != nil {
+		return SSEEvent{}, err
+	}
+
+	return SSEEvent{}, io.EOF
+}
+
+func isEventStream(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "text/event-stream"
+}
+
+// Events parses a fully received text/event-stream body.
+func (r *Response) Events() []SSEEvent {
+	r.helper()
+
+	if contentType := r.Header.Get("Content-Type"); !isEventStream(contentType) {
+		r.err(fmt.Errorf("Content-Type is not text/event-stream, got %s: %s", contentType, r.bodyExcerpt()))
+		return nil
+	}
+
+	parser := newSSEParser(bytes.NewReader(r.Body))
+	events := []SSEEvent{}
+
+	for {
+		event, err := parser.next()
+		if err == io.EOF {
+			return events
+		}
+		if err != nil {
+			r.err(err)
+			return events
+		}
+		events = append(events, event)
+	}
+}
+
+type EventStream struct {
+	req     *http.Request
+	res     *http.Response
+	cancel  context.CancelFunc
+	timeout time.Duration
+	onError func(error)
+	helper  func()
+	events  chan SSEEvent
+	readErr error
+	done    chan struct{}
+	once    sync.Once
+}
+
+// SSE sends the request and returns a stream of server-sent events without
+// waiting for the response body to complete.
+func (b *ReqBuilder) SSE() *EventStream {
+	b.helper()
+
+	if b.headers.Get("Accept") == "" {
+		b.Header("Accept", "text/event-stream")
+	}
+
+	ctx, cancel := b.requestContext()
+
+	sent, err := b.send(ctx)
+	if err != nil {
+		cancel()
+		b.onError(err)
+		return nil
+	}
+
+	req, res := sent.req, sent.res
+
+	s := &EventStream{
+		req:     req,
+		res:     res,
+		cancel:  cancel,
+		timeout: 5 * time.Second,
+		onError: b.onError,
+		helper:  b.helper,
+		events:  make(chan SSEEvent, 64),
+		done:    make(chan struct{}),
+	}
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 100))
+		s.Close()
+		s.err(fmt.Errorf("expected status 200 got %d: %s", res.StatusCode, body))
+		return nil
+	}
+
+	if contentType := res.Header.Get("Content-Type"); !isEventStream(contentType) {
+		s.Close()
+		s.err(fmt.Errorf("Content-Type is not text/event-stream, got %s", contentType))
+		return nil
+	}
+
+	go s.readLoop()
+
+	return s
+}
+
+func (s *EventStream) err(err error) {
+	s.helper()
+
+	s.onError(fmt.Errorf("%s %s: %s", s.req.Method, s.req.URL.String(), err))
+}
+
+func (s *EventStream) readLoop() {
+	defer close(s.events)
+
+	parser := newSSEParser(s.res.Body)
+
+	for {
+		event, err := parser.next()
+		if err != nil {
+			s.readErr = err
+			return
+		}
+		select {
+		case s.events <- event:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Timeout sets how long to wait for each event.
+func (s *EventStream) Timeout(d time.Duration) *EventStream {
+	s.timeout = d
+	return s
+}
+
+func (s *EventStream) Next() (SSEEvent, bool) {
+	s.helper()
+
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
+
+	select {
+	case event, ok := <-s.events:
+		if !ok {
+			err := s.readErr
+			if err == io.EOF {
+				err = errors.New("stream ended")
+			}
+			s.err(fmt.Errorf("expected event: %w", err))
+			return SSEEvent{}, false
+		}
+		return event, true
+	case <-timer.C:
+		s.err(fmt.Errorf("expected event within %s", s.timeout))
+		return SSEEvent{}, false
+	}
+}
+
+// ExpectEvent waits for the next event and checks its name and, if
+// dataMatcher is not nil, its data.
+func (s *EventStream) ExpectEvent(name string, dataMatcher func(data string) bool) *EventStream {
+	s.helper()
+
+	event, ok := s.Next()
+	if !ok {
+		return s
+	}
+
+	if event.Event != name {
+		s.err(fmt.Errorf("expected event %s got %s: %s", name, event.Event, event.Data))
+		return s
+	}
+
+	if dataMatcher != nil && !dataMatcher(event.Data) {
+		s.err(fmt.Errorf("event %s data does not match: %s", name, event.Data))
+	}
+
+	return s
+}
+
+func (s *EventStream) ExpectEventData(name string, data string) *EventStream {
+	s.helper()
+
+	return s.ExpectEvent(name, func(d string) bool {
+		return d == data
+	})
+}
+
+func (s *EventStream) ExpectEventJSON(name string, v interface{}) *EventStream {
+	s.helper()
+
+	return s.ExpectEvent(name, func(data string) bool {
+		return json.Unmarshal([]byte(data), v) == nil
+	})
+}
+
+func (s *EventStream) Close() {
+	s.once.Do(func() {
+		close(s.done)
+	})
+	s.cancel()
+	s.res.Body.Close()
+}
@@ -0,0 +1,216 @@
+package httptester
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const streamHeadSize = 4096
+
+// StreamResponse is a response whose body is read on demand instead of being
+// buffered in memory. Size and SHA256 drain the body once and cache the
+// result, so they can be combined in any order.
+type StreamResponse struct {
+	*http.Response
+	req     *http.Request
+	cancel  context.CancelFunc
+	onError func(error)
+	helper  func()
+	reader  *bufio.Reader
+	drained bool
+	head    []byte
+	size    int64
+	sum     string
+}
+
+func (b *ReqBuilder) DoStream() *StreamResponse {
+	b.helper()
+
+	ctx, cancel := b.requestContext()
+
+	sent, err := b.send(ctx)
+	if err != nil {
+		cancel()
+		b.onError(err)
+		return nil
+	}
+
+	req, res := sent.req, sent.res
+
+	return &StreamResponse{
+		Response: res,
+		req:      req,
+		cancel:   cancel,
+		onError:  b.onError,
+		helper:   b.helper,
+		reader:   bufio.NewReaderSize(res.Body, streamHeadSize),
+	}
+}
+
+func (r *StreamResponse) err(err error) {
+	r.helper()
+
+	r.onError(fmt.Errorf("%s %s: %s", r.req.Method, r.req.URL.String(), err))
+}
+
+// Reader returns the unread part of the body.
+func (r *StreamResponse) Reader() io.Reader {
+	return r.reader
+}
+
+func (r *StreamResponse) Close() {
+	r.Response.Body.Close()
+	r.cancel()
+}
+
+func (r *StreamResponse) drain(writers ...io.Writer) bool {
+	r.helper()
+
+	if r.drained {
+		return true
+	}
+
+	hash := sha256.New()
+	head := &headWriter{limit: streamHeadSize}
+
+	size, err := io.Copy(io.MultiWriter(append(writers, hash, head)...), r.reader)
+	r.Close()
+	if err != nil {
+		r.err(err)
+		return false
+	}
+
+	r.drained = true
+	r.head = head.buf.Bytes()
+	r.size = size
+	r.sum = hex.EncodeToString(hash.Sum(nil))
+
+	return true
+}
+
+func (r *StreamResponse) Status(statuses ...int) *StreamResponse {
+	r.helper()
+
+	for _, status := range statuses {
+		if r.StatusCode == status {
+			return r
+		}
+	}
+
+	if len(statuses) > 0 {
+		r.err(fmt.Errorf("expected status %v got %d", statuses, r.StatusCode))
+	}
+
+	return r
+}
+
+func (r *StreamResponse) HeaderEq(key string, value string) *StreamResponse {
+	r.helper()
+
+	if resVal := r.Header.Get(key); resVal != value {
+		r.err(fmt.Errorf("header %s: expected %s to equal %s", key, resVal, value))
+	}
+
+	return r
+}
+
+func (r *StreamResponse) Prefix(prefix string) *StreamResponse {
+	r.helper()
+
+	if len(prefix) > streamHeadSize {
+		r.err(fmt.Errorf("prefix longer than %d bytes", streamHeadSize))
+		return r
+	}
+
+	var head []byte
+	if r.drained {
+		head = r.head
+	} else {
+		head, _ = r.reader.Peek(len(prefix))
+	}
+
+	if !bytes.HasPrefix(head, []byte(prefix)) {
+		if len(head) > len(prefix) {
+			head = head[:len(prefix)]
+		}
+		r.err(fmt.Errorf("body does not start with %q: %q", prefix, head))
+	}
+
+	return r
+}
+
+func (r *StreamResponse) Size(size int64) *StreamResponse {
+	r.helper()
+
+	if r.drain() && r.size != size {
+		r.err(fmt.Errorf("expected body size %d got %d", size, r.size))
+	}
+
+	return r
+}
+
+func (r *StreamResponse) SHA256(expected string) *StreamResponse {
+	r.helper()
+
+	if r.drain() && r.sum != strings.ToLower(expected) {
+		r.err(fmt.Errorf("expected body SHA-256 %s got %s", expected, r.sum))
+	}
+
+	return r
+}
+
+// SaveTo streams the body to path. It must be called before Size or SHA256
+// as the body can only be read once.
+func (r *StreamResponse) SaveTo(path string) *StreamResponse {
+	r.helper()
+
+	if r.drained {
+		r.err(fmt.Errorf("cannot save body to %s: body was already read", path))
+		return r
+	}
+
+	f, err := createFile(path)
+	if err != nil {
+		r.err(err)
+		return r
+	}
+
+	r.drain(f)
+
+	if err := f.Close(); err != nil {
+		r.err(err)
+	}
+
+	return r
+}
+
+func createFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return os.Create(path)
+}
+
+type headWriter struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (w *headWriter) Write(p []byte) (int, error) {
+	if remaining := w.limit - w.buf.Len(); remaining > 0 {
+		if len(p) < remaining {
+			remaining = len(p)
+		}
+		w.buf.Write(p[:remaining])
+	}
+	return len(p), nil
+}
@@ -0,0 +1,52 @@
+package httptester
+
+// TestingT is the interface of testify's assert.TestingT, implemented by
+// *testing.T and *assert.CollectT.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// RequireT is the interface of testify's require.TestingT.
+type RequireT interface {
+	TestingT
+	FailNow()
+}
+
+type tHelper interface {
+	Helper()
+}
+
+// WithAssert reports failed assertions with t.Errorf and continues the test
+// like testify's assert package does.
+func WithAssert(t TestingT) ClientOption {
+	return func(c *Client) {
+		helper := func() {}
+		if h, ok := t.(tHelper); ok {
+			helper = h.Helper
+		}
+
+		c.template.Helper(helper)
+		c.template.OnError(func(err error) {
+			helper()
+			t.Errorf("%s", err)
+		})
+	}
+}
+
+// WithRequire reports failed assertions with t.Errorf and stops the test
+// with t.FailNow like testify's require package does.
+func WithRequire(t RequireT) ClientOption {
+	return func(c *Client) {
+		helper := func() {}
+		if h, ok := t.(tHelper); ok {
+			helper = h.Helper
+		}
+
+		c.template.Helper(helper)
+		c.template.OnError(func(err error) {
+			helper()
+			t.Errorf("%s", err)
+			t.FailNow()
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package httptester_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+// testifyT records the calls testify makes on a TestingT.
+type testifyT struct {
+	errors  []string
+	failed  bool
+	helpers int
+}
+
+func (t *testifyT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *testifyT) FailNow() {
+	t.failed = true
+	runtime.Goexit()
+}
+
+func (t *testifyT) Helper() {
+	t.helpers++
+}
+
+func TestTestifyAdapter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	httptester.New(t, server.URL, httptester.WithAssert(t)).GET("/").Do().Status(200).Eq("ok")
+
+	at := &testifyT{}
+	a := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithAssert(at))
+	a.GET("/").Do().Status(404).Eq("nope")
+
+	if len(at.errors) != 2 || at.failed {
+		t.Fatalf("expected 2 errors without FailNow got %q %v", at.errors, at.failed)
+	}
+	if !strings.Contains(at.errors[0], "expected status [404] got 200: ok") || !strings.Contains(at.errors[1], "body does not equal nope: ok") {
+		t.Errorf("unexpected errors %q", at.errors)
+	}
+	if at.helpers == 0 {
+		t.Error("expected Helper to be called")
+	}
+
+	rt := &testifyT{}
+	r := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithRequire(rt))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.GET("/").Do().Status(404).Eq("nope")
+	}()
+	<-done
+
+	if len(rt.errors) != 1 || !rt.failed {
+		t.Fatalf("expected 1 error with FailNow got %q %v", rt.errors, rt.failed)
+	}
+}
@@ -0,0 +1,78 @@
+package httptester
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// throttlePolicy retries throttled responses after their Retry-After delay,
+// see ReqBuilder.RetryAfter.
+type throttlePolicy struct {
+	maxRetries int
+	maxDelay   time.Duration
+}
+
+// delay returns how long to wait before retrying res, or false if res is not
+// throttled, asks for more than maxDelay or the retries are used up.
+func (p *throttlePolicy) delay(res *http.Response, retries int, now time.Time) (time.Duration, bool) {
+	if p == nil || retries >= p.maxRetries {
+		return 0, false
+	}
+
+	d, ok := retryAfter(res, now)
+	if !ok || (p.maxDelay > 0 && d > p.maxDelay) {
+		return 0, false
+	}
+	return d, true
+}
+
+// retryAfter returns the Retry-After delay of a 429 or 503 response. The
+// header is either a number of seconds or an HTTP date.
+func retryAfter(res *http.Response, now time.Time) (time.Duration, bool) {
+	if res == nil || (res.StatusCode != http.StatusTooManyRequests && res.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+
+	value := strings.TrimSpace(res.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// RetryAfter retries 429 Too Many Requests and 503 Service Unavailable
+// responses with a Retry-After header up to maxRetries times, waiting the
+// requested delay first. Responses asking for more than maxDelay are returned
+// as they are, a zero maxDelay waits for any delay. Throttle retries are
+// counted separately from RetryPolicy retries and the delays are available
+// from Response.ThrottleDelays.
+func (b *ReqBuilder) RetryAfter(maxRetries int, maxDelay time.Duration) *ReqBuilder {
+	b.throttle = &throttlePolicy{
+		maxRetries: maxRetries,
+		maxDelay:   maxDelay,
+	}
+	return b
+}
+
+// ThrottleDelays returns the Retry-After delays waited before the response
+// was received, see ReqBuilder.RetryAfter.
+func (r *Response) ThrottleDelays() []time.Duration {
+	return r.throttleDelays
+}
@@ -0,0 +1,67 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bancek/httptester"
+)
+
+func TestReqBuilderRetryAfter(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		switch {
+		case r.URL.Path == "/slow":
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(503)
+		case attempts == 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(429)
+		case attempts == 2:
+			w.Header().Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+			w.WriteHeader(503)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	c := httptester.NewClient(
+		httptester.WithBaseURL(server.URL),
+		httptester.WithOnError(func(err error) {
+			t.Fatal(err)
+		}),
+	)
+
+	res := c.GET("/").RetryAfter(2, time.Second).Do().Status(200).Eq("ok")
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts got %d", attempts)
+	}
+	if delays := res.ThrottleDelays(); len(delays) != 2 || delays[0] != 0 || delays[1] != 0 {
+		t.Fatalf("expected 2 zero delays got %v", delays)
+	}
+
+	attempts = 0
+	c.GET("/").RetryAfter(1, time.Second).Do().Status(503)
+	if attempts != 2 {
+		t.Fatalf("expected 2 attempts got %d", attempts)
+	}
+
+	attempts = 0
+	res = c.GET("/slow").RetryAfter(3, time.Second).Do().Status(503)
+	if attempts != 1 {
+		t.Fatalf("expected delays over the limit not to be retried got %d attempts", attempts)
+	}
+	if len(res.ThrottleDelays()) != 0 {
+		t.Fatalf("expected no delays got %v", res.ThrottleDelays())
+	}
+
+	attempts = 0
+	c.GET("/").Do().Status(429)
+	if attempts != 1 {
+		t.Fatalf("expected throttled responses not to be retried by default got %d attempts", attempts)
+	}
+}
@@ -0,0 +1,139 @@
+package httptester
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timings is the per-phase latency breakdown of the final attempt of a
+// request. Phases that did not happen (e.g. DNS for an IP address or connect
+// for a reused connection) are zero. When redirects are followed the phases
+// are those of the last hop while TimeToFirstByte is measured from the
+// first.
+type Timings struct {
+	DNS             time.Duration
+	Connect         time.Duration
+	TLSHandshake    time.Duration
+	TimeToFirstByte time.Duration
+	ConnReused      bool
+}
+
+type timingsTrace struct {
+	mu       sync.Mutex
+	start    time.Time
+	dnsStart time.Time
+	conStart time.Time
+	tlsStart time.Time
+	timings  Timings
+}
+
+func withTimingsTrace(ctx context.Context) (context.Context, *timingsTrace) {
+	t := &timingsTrace{}
+
+	trace := &httptrace.ClientTrace{
+		GetConn: func(string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.start.IsZero() {
+				t.start = time.Now()
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timings.ConnReused = info.Reused
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timings.DNS = time.Since(t.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.conStart = time.Now()
+		},
+		ConnectDone: func(network string, addr string, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if err == nil {
+				t.timings.Connect = time.Since(t.conStart)
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timings.TLSHandshake = time.Since(t.tlsStart)
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timings.TimeToFirstByte = time.Since(t.start)
+		},
+	}
+
+	return httptrace.WithClientTrace(ctx, trace), t
+}
+
+func (t *timingsTrace) result() Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.timings
+}
+
+func (r *Response) Timings() Timings {
+	return r.timings
+}
+
+func (r *Response) phaseFasterThan(phase string, took time.Duration, d time.Duration) *Response {
+	r.helper()
+
+	if took >= d {
+		r.err(fmt.Errorf("expected %s faster than %s, took %s", phase, d, took))
+	}
+
+	return r
+}
+
+func (r *Response) DNSFasterThan(d time.Duration) *Response {
+	r.helper()
+	defer r.track("DNSFasterThan", d)()
+
+	return r.phaseFasterThan("DNS lookup", r.timings.DNS, d)
+}
+
+func (r *Response) ConnectFasterThan(d time.Duration) *Response {
+	r.helper()
+	defer r.track("ConnectFasterThan", d)()
+
+	return r.phaseFasterThan("connect", r.timings.Connect, d)
+}
+
+func (r *Response) TLSHandshakeFasterThan(d time.Duration) *Response {
+	r.helper()
+	defer r.track("TLSHandshakeFasterThan", d)()
+
+	return r.phaseFasterThan("TLS handshake", r.timings.TLSHandshake, d)
+}
+
+func (r *Response) TTFBFasterThan(d time.Duration) *Response {
+	r.helper()
+	defer r.track("TTFBFasterThan", d)()
+
+	return r.phaseFasterThan("time to first byte", r.timings.TimeToFirstByte, d)
+}
@@ -0,0 +1,119 @@
+package httptester
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ClientCert presents the PEM encoded certificate and key to servers that
+// request a client certificate (mutual TLS) for this request only.
+func (b *ReqBuilder) ClientCert(certPEM []byte, keyPEM []byte) *ReqBuilder {
+	b.helper()
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		b.onError(err)
+		return b
+	}
+
+	return b.clientCert(cert)
+}
+
+func (b *ReqBuilder) ClientCertFromFiles(certFile string, keyFile string) *ReqBuilder {
+	b.helper()
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		b.onError(err)
+		return b
+	}
+
+	return b.clientCert(cert)
+}
+
+func (b *ReqBuilder) clientCert(cert tls.Certificate) *ReqBuilder {
+	return b.transportOption(func(t *http.Transport) {
+		certs := append([]tls.Certificate(nil), t.TLSClientConfig.Certificates...)
+		t.TLSClientConfig.Certificates = append(certs, cert)
+	})
+}
+
+// InsecureTLS skips verification of the server's certificate for this
+// request only, for self-signed staging endpoints.
+func (b *ReqBuilder) InsecureTLS() *ReqBuilder {
+	return b.transportOption(func(t *http.Transport) {
+		t.TLSClientConfig.InsecureSkipVerify = true
+	})
+}
+
+// RootCAs verifies the server's certificate against pool instead of the
+// system roots for this request only.
+func (b *ReqBuilder) RootCAs(pool *x509.CertPool) *ReqBuilder {
+	return b.transportOption(func(t *http.Transport) {
+		t.TLSClientConfig.RootCAs = pool
+	})
+}
+
+// tlsState returns the TLS connection state of the response, available as
+// r.TLS, and reports an error for plain HTTP responses.
+func (r *Response) tlsState() (*tls.ConnectionState, bool) {
+	r.helper()
+
+	if r.TLS == nil {
+		r.err(errors.New("response was not received over TLS"))
+		return nil, false
+	}
+
+	return r.TLS, true
+}
+
+func (r *Response) TLSVersionAtLeast(version uint16) *Response {
+	r.helper()
+	defer r.track("TLSVersionAtLeast", tls.VersionName(version))()
+
+	if state, ok := r.tlsState(); ok && state.Version < version {
+		r.err(fmt.Errorf("expected TLS version at least %s got %s", tls.VersionName(version), tls.VersionName(state.Version)))
+	}
+
+	return r
+}
+
+// CertSubjectContains checks that the subject of the server's leaf
+// certificate contains substr, e.g. CN=api.example.com.
+func (r *Response) CertSubjectContains(substr string) *Response {
+	r.helper()
+	defer r.track("CertSubjectContains", substr)()
+
+	state, ok := r.tlsState()
+	if !ok {
+		return r
+	}
+
+	if len(state.PeerCertificates) == 0 {
+		r.err(errors.New("server sent no certificates"))
+		return r
+	}
+
+	if subject := state.PeerCertificates[0].Subject.String(); !strings.Contains(subject, substr) {
+		r.err(fmt.Errorf("expected certificate subject to contain %q got %q", substr, subject))
+	}
+
+	return r
+}
+
+// ALPN checks the application protocol negotiated during the TLS handshake,
+// e.g. h2.
+func (r *Response) ALPN(protocol string) *Response {
+	r.helper()
+	defer r.track("ALPN", protocol)()
+
+	if state, ok := r.tlsState(); ok && state.NegotiatedProtocol != protocol {
+		r.err(fmt.Errorf("expected ALPN protocol %q got %q", protocol, state.NegotiatedProtocol))
+	}
+
+	return r
+}
@@ -0,0 +1,210 @@
+package httptester_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bancek/httptester"
+)
+
+// newTestCert returns a PEM encoded certificate and key for commonName
+// signed by parent (self-signed if parent is nil).
+func newTestCert(t *testing.T, commonName string, parent *tls.Certificate, isCA bool) ([]byte, []byte, tls.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName, Organization: []string{"httptester"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		DNSNames:              []string{commonName},
+	}
+
+	parentCert, parentKey := template, interface{}(key)
+	if parent != nil {
+		parentCert = parent.Leaf
+		parentKey = parent.PrivateKey
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert.Leaf, _ = x509.ParseCertificate(der)
+
+	return certPEM, keyPEM, cert
+}
+
+func TestReqBuilderClientCert(t *testing.T) {
+	_, _, ca := newTestCert(t, "Test CA", nil, true)
+	certPEM, keyPEM, _ := newTestCert(t, "client", &ca, false)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.Leaf)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	c := httptester.New(t, server.URL, httptester.WithHTTPClient(server.Client()))
+
+	c.GET("/").ClientCert(certPEM, keyPEM).Do().Status(200).Eq("client")
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "client.pem"), certPEM, 0600)
+	os.WriteFile(filepath.Join(dir, "client.key"), keyPEM, 0600)
+
+	c.GET("/").ClientCertFromFiles(filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")).Do().Status(200).Eq("client")
+
+	var errs []error
+	client := httptester.NewClient(
+		httptester.WithBaseURL(server.URL),
+		httptester.WithHTTPClient(server.Client()),
+		httptester.WithOnError(func(err error) {
+			errs = append(errs, err)
+		}),
+	)
+
+	client.GET("/").Do()
+	client.GET("/").ClientCert(certPEM, []byte("invalid"))
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors got %v", errs)
+	}
+}
+
+func TestReqBuilderInsecureTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	c.GET("/").InsecureTLS().Do().Status(200).Eq("ok")
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	c.GET("/").RootCAs(pool).Do().Status(200).Eq("ok")
+
+	var errs []error
+	httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	})).GET("/").RootCAs(x509.NewCertPool()).Do()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "certificate") {
+		t.Fatalf("expected certificate error got %v", errs)
+	}
+}
+
+func TestResponseTLS(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	c := httptester.New(t, server.URL, httptester.WithHTTPClient(server.Client()))
+
+	res := c.GET("/").Do().Status(200).Eq("HTTP/2.0").
+		TLSVersionAtLeast(tls.VersionTLS12).
+		CertSubjectContains("O=Acme Co").
+		ALPN("h2")
+
+	if !res.TLS.HandshakeComplete {
+		t.Fatalf("expected TLS connection state")
+	}
+
+	var errs []error
+	onError := func(err error) {
+		errs = append(errs, err)
+	}
+
+	httptester.NewReqBuilder(server.URL, server.Client(), onError).GET("/").Do().
+		CertSubjectContains("CN=api.example.com").
+		ALPN("http/1.1")
+
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer plain.Close()
+
+	httptester.NewReqBuilder(plain.URL, http.DefaultClient, onError).GET("/").Do().
+		TLSVersionAtLeast(tls.VersionTLS13)
+
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors got %v", errs)
+	}
+	if !strings.Contains(errs[0].Error(), `expected certificate subject to contain "CN=api.example.com" got "O=Acme Co"`) {
+		t.Fatalf("unexpected error %s", errs[0])
+	}
+	if !strings.Contains(errs[2].Error(), "response was not received over TLS") {
+		t.Fatalf("unexpected error %s", errs[2])
+	}
+}
+
+func TestReqBuilderResolve(t *testing.T) {
+	_, _, ca := newTestCert(t, "Test CA", nil, true)
+	_, _, cert := newTestCert(t, "api.example.com", &ca, false)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Host + " " + r.TLS.ServerName))
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	hostPort := net.JoinHostPort("api.example.com", port)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+
+	c := httptester.New(t, "https://"+hostPort)
+
+	c.GET("/").Resolve(hostPort, "127.0.0.1").RootCAs(pool).Do().
+		Status(200).
+		Eq(hostPort + " api.example.com").
+		CertSubjectContains("CN=api.example.com")
+}
@@ -0,0 +1,108 @@
+package httptester
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// TraceContext is a W3C trace context, see
+// https://www.w3.org/TR/trace-context/.
+type TraceContext struct {
+	// TraceID is the 32 hex digit id of the trace.
+	TraceID string
+	// SpanID is the 16 hex digit id of the parent span.
+	SpanID string
+	// Flags are the trace flags, 01 if sampled.
+	Flags byte
+	// State is the vendor specific tracestate header.
+	State string
+}
+
+// ParseTraceParent parses traceparent and tracestate headers, e.g. of a
+// request received by a test server.
+func ParseTraceParent(traceparent string, tracestate string) (TraceContext, error) {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return TraceContext{}, fmt.Errorf("invalid traceparent %q", traceparent)
+	}
+
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	if !isHexID(version, 2) || !isHexID(traceID, 32) || !isHexID(spanID, 16) || !isHexID(flags, 2) {
+		return TraceContext{}, fmt.Errorf("invalid traceparent %q", traceparent)
+	}
+
+	flagBytes, _ := hex.DecodeString(flags)
+
+	return TraceContext{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Flags:   flagBytes[0],
+		State:   strings.TrimSpace(tracestate),
+	}, nil
+}
+
+// isHexID reports whether s is n lowercase hex digits that are not all
+// zero.
+func isHexID(s string, n int) bool {
+	if len(s) != n || strings.Trim(s, "0123456789abcdef") != "" {
+		return false
+	}
+	return n == 2 || strings.Trim(s, "0") != ""
+}
+
+// TraceParent returns the traceparent header of the context.
+func (tc TraceContext) TraceParent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", tc.TraceID, tc.SpanID, tc.Flags)
+}
+
+// child returns the trace context of a new span in the trace.
+func (tc TraceContext) child() TraceContext {
+	tc.SpanID = randomHex(8)
+	return tc
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type traceContextKey struct{}
+
+// ContextWithTraceContext returns a context carrying tc, e.g. parsed from a
+// request received by a test server, so requests sent with TraceParent
+// continue its trace.
+func ContextWithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, tc)
+}
+
+// TraceContextFromContext returns the trace context of ctx.
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextKey{}).(TraceContext)
+	return tc, ok
+}
+
+// TraceParent sends traceparent and tracestate headers linking the request
+// to the trace of ctx (see ContextWithTraceContext) or to a new sampled
+// trace if ctx has none. Each request is a new span of the trace. Headers
+// injected by a Tracer take precedence.
+func (b *ReqBuilder) TraceParent(ctx context.Context) *ReqBuilder {
+	tc, ok := TraceContextFromContext(ctx)
+	if !ok {
+		tc = TraceContext{
+			TraceID: randomHex(16),
+			SpanID:  randomHex(8),
+			Flags:   1,
+		}
+	}
+	b.traceContext = &tc
+	return b
+}
+
+// TraceParent returns the traceparent header sent with the request.
+func (r *Response) TraceParent() string {
+	return r.req.Header.Get("traceparent")
+}
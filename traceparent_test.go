@@ -0,0 +1,71 @@
+package httptester_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestTraceParent(t *testing.T) {
+	var received []httptester.TraceContext
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tc, err := httptester.ParseTraceParent(r.Header.Get("traceparent"), r.Header.Get("tracestate"))
+		if err != nil {
+			t.Errorf("unexpected traceparent: %v", err)
+		}
+		received = append(received, tc)
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	res := c.GET("/").TraceParent(context.Background()).Do()
+	root := received[0]
+	if root.Flags != 1 || root.State != "" || res.TraceParent() != root.TraceParent() {
+		t.Errorf("unexpected root trace context %+v %s", root, res.TraceParent())
+	}
+
+	parent, err := httptester.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "vendor=abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := httptester.ContextWithTraceContext(context.Background(), parent)
+
+	req := c.GET("/").TraceParent(ctx)
+	req.Do()
+	req.Do()
+
+	for _, tc := range received[1:] {
+		if tc.TraceID != parent.TraceID || tc.State != "vendor=abc" || tc.Flags != 1 {
+			t.Errorf("expected trace to be continued got %+v", tc)
+		}
+		if tc.SpanID == parent.SpanID {
+			t.Errorf("expected a new span id got %s", tc.SpanID)
+		}
+	}
+	if received[1].SpanID == received[2].SpanID {
+		t.Errorf("expected a span per request got %s twice", received[1].SpanID)
+	}
+
+	for _, header := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		if _, err := httptester.ParseTraceParent(header, ""); err == nil || !strings.Contains(err.Error(), "invalid traceparent") {
+			t.Errorf("expected %q to be invalid got %v", header, err)
+		}
+	}
+
+	if _, err := httptester.ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", ""); err != nil {
+		t.Errorf("expected future versions to be accepted got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package httptester
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Tracer starts a client span around each request of builders it is
+// attached to (see ReqBuilder.Tracer) so that test traffic shows up in
+// distributed traces. It is implemented by a small adapter over a tracing
+// library such as OpenTelemetry, see the README.
+type Tracer interface {
+	// Start starts a span and returns the context carrying it.
+	Start(ctx context.Context, name string, attrs []Attribute) (context.Context, Span)
+	// Inject writes the trace context headers (e.g. traceparent) of ctx to
+	// header.
+	Inject(ctx context.Context, header http.Header)
+}
+
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Attribute is a span attribute. Keys follow the OpenTelemetry HTTP semantic
+// conventions.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+func (b *ReqBuilder) Tracer(tracer Tracer) *ReqBuilder {
+	b.tracer = tracer
+	return b
+}
+
+// startSpan starts the span covering all attempts of a request. The span is
+// ended by endSpan once the response headers are received.
+func (b *ReqBuilder) startSpan(ctx context.Context, u *url.URL) (context.Context, Span) {
+	method := b.method
+	if method == "" {
+		method = "GET"
+	}
+
+	return b.tracer.Start(ctx, method+" "+b.endpoint(), []Attribute{
+		{Key: "http.request.method", Value: method},
+		{Key: "url.full", Value: u.String()},
+		{Key: "server.address", Value: u.Hostname()},
+	})
+}
+
+func endSpan(span Span, res *http.Response, err error) {
+	if err != nil {
+		span.RecordError(err)
+	} else {
+		span.SetAttributes(Attribute{Key: "http.response.status_code", Value: res.StatusCode})
+		if res.StatusCode >= 400 {
+			span.RecordError(fmt.Errorf("status %d", res.StatusCode))
+		}
+	}
+
+	span.End()
+}
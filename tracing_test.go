@@ -0,0 +1,83 @@
+package httptester_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+type spanKey struct{}
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string, attrs []httptester.Attribute) (context.Context, httptester.Span) {
+	span := &testSpan{name: name, attrs: map[string]interface{}{}}
+	span.SetAttributes(attrs...)
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (t *testTracer) Inject(ctx context.Context, header http.Header) {
+	if span, ok := ctx.Value(spanKey{}).(*testSpan); ok {
+		header.Set("X-Span", span.name)
+	}
+}
+
+type testSpan struct {
+	name  string
+	attrs map[string]interface{}
+	errs  []error
+	ended bool
+}
+
+func (s *testSpan) SetAttributes(attrs ...httptester.Attribute) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *testSpan) RecordError(err error) {
+	s.errs = append(s.errs, err)
+}
+
+func (s *testSpan) End() {
+	s.ended = true
+}
+
+func TestTracer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(404)
+		}
+		w.Write([]byte(r.Header.Get("X-Span")))
+	}))
+	defer server.Close()
+
+	tracer := &testTracer{}
+	c := httptester.New(t, server.URL, httptester.WithTracer(tracer))
+
+	c.GET("/users/{id}").Param("id", "1").Do().Status(200).Eq("GET /users/{id}")
+	c.DELETE("/missing").Do().Status(404)
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("expected 2 spans got %d", len(tracer.spans))
+	}
+
+	span := tracer.spans[0]
+	if !span.ended || len(span.errs) != 0 {
+		t.Fatalf("unexpected span %+v", span)
+	}
+	if span.attrs["http.request.method"] != "GET" || span.attrs["url.full"] != server.URL+"/users/1" || span.attrs["http.response.status_code"] != 200 {
+		t.Fatalf("unexpected attributes %v", span.attrs)
+	}
+
+	span = tracer.spans[1]
+	if !span.ended || len(span.errs) != 1 || span.errs[0].Error() != "status 404" {
+		t.Fatalf("expected error span got %+v", span)
+	}
+}
@@ -0,0 +1,151 @@
+package httptester
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// structValues encodes the exported fields of a struct into url.Values using
+// the given tag (e.g. `url:"name,omitempty"`). Slices produce repeated
+// values, nil pointers are skipped and embedded structs are flattened.
+func structValues(v interface{}, tag string) (url.Values, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return url.Values{}, nil
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a struct got %s", rv.Type())
+	}
+
+	values := url.Values{}
+	if err := addStructValues(values, rv, tag); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func addStructValues(values url.Values, rv reflect.Value, tag string) error {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fv := rv.Field(i)
+
+		name, opts, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			for fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				if err := addStructValues(values, fv, tag); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		omitEmpty := strings.Contains(","+opts+",", ",omitempty,")
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+
+		for fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+			if fv.IsNil() {
+				break
+			}
+			fv = fv.Elem()
+		}
+		if (fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface) && fv.IsNil() {
+			continue
+		}
+
+		if (fv.Kind() == reflect.Slice || fv.Kind() == reflect.Array) && fv.Type().Elem().Kind() != reflect.Uint8 {
+			if omitEmpty && fv.Len() == 0 {
+				continue
+			}
+			for j := 0; j < fv.Len(); j++ {
+				s, err := formatValue(fv.Index(j))
+				if err != nil {
+					return fmt.Errorf("%s: %w", field.Name, err)
+				}
+				values.Add(name, s)
+			}
+			continue
+		}
+
+		s, err := formatValue(fv)
+		if err != nil {
+			return fmt.Errorf("%s: %w", field.Name, err)
+		}
+		values.Add(name, s)
+	}
+
+	return nil
+}
+
+func formatValue(v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339), nil
+	}
+
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		return string(text), err
+	}
+
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported type %s", v.Type())
+}
@@ -0,0 +1,86 @@
+package httptester
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var varRegexp = regexp.MustCompile(`\{\{\s*([\w.-]+)\s*\}\}`)
+
+// Vars is a variable store shared between requests. Values are set with
+// Response.Extract or Set and referenced as {{name}} in URLs, query
+// parameters, headers and bodies of builders using the store.
+type Vars struct {
+	mu     sync.Mutex
+	values map[string]interface{}
+}
+
+func NewVars() *Vars {
+	return &Vars{
+		values: map[string]interface{}{},
+	}
+}
+
+func (v *Vars) Set(name string, value interface{}) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.values[name] = value
+}
+
+func (v *Vars) Get(name string) (interface{}, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	value, ok := v.values[name]
+	return value, ok
+}
+
+func (v *Vars) all() map[string]interface{} {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	values := make(map[string]interface{}, len(v.values))
+	for k, value := range v.values {
+		values[k] = value
+	}
+	return values
+}
+
+func (v *Vars) String(name string) string {
+	value, _ := v.Get(name)
+	return varString(value)
+}
+
+func varString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return jsonString(value)
+}
+
+func (v *Vars) expand(s string) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	var missing []string
+
+	expanded := varRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		name := varRegexp.FindStringSubmatch(m)[1]
+		value, ok := v.Get(name)
+		if !ok {
+			missing = append(missing, name)
+			return m
+		}
+		return varString(value)
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("undefined variables %s", strings.Join(missing, ", "))
+	}
+
+	return expanded, nil
+}
@@ -0,0 +1,490 @@
+package httptester
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xa
+)
+
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// wsMaxMessageSize limits the size of a frame or a fragmented message so a
+// misbehaving server cannot make the client allocate unbounded memory.
+const wsMaxMessageSize = 32 << 20
+
+// WSMessageType is the type of a received WebSocket message.
+type WSMessageType byte
+
+const (
+	WSText   WSMessageType = wsOpText
+	WSBinary WSMessageType = wsOpBinary
+)
+
+func (t WSMessageType) String() string {
+	switch t {
+	case WSText:
+		return "text"
+	case WSBinary:
+		return "binary"
+	}
+	return fmt.Sprintf("opcode %d", byte(t))
+}
+
+type WSBuilder struct {
+	req       *ReqBuilder
+	timeout   time.Duration
+	protocols []string
+}
+
+// WS starts a WebSocket handshake to url using the builder's base URL,
+// headers, auth, cookies and client.
+func (b *ReqBuilder) WS(url string) *WSBuilder {
+	b.Method("GET", url)
+
+	return &WSBuilder{
+		req:     b,
+		timeout: 5 * time.Second,
+	}
+}
+
+func (w *WSBuilder) Timeout(d time.Duration) *WSBuilder {
+	w.timeout = d
+	return w
+}
+
+func (w *WSBuilder) Protocol(protocols ...string) *WSBuilder {
+	w.protocols = append(w.protocols, protocols...)
+	return w
+}
+
+func (w *WSBuilder) Dial() *WSConn {
+	b := w.req
+	b.helper()
+
+	u, err := b.buildURL()
+	if err != nil {
+		b.onError(err)
+		return nil
+	}
+
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	}
+
+	keyBytes := make([]byte, 16)
+	if _, err := rand.Read(keyBytes); err != nil {
+		b.onError(err)
+		return nil
+	}
+	key := base64.StdEncoding.EncodeToString(keyBytes)
+
+	ctx := b.context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// The handshake timeout must not apply to the established connection so
+	// the context is only canceled if the handshake takes too long or when
+	// the connection is closed.
+	ctx, cancel := context.WithCancel(ctx)
+	timer := time.AfterFunc(w.timeout, cancel)
+
+	req, err := b.newRequest(ctx, u, nil)
+	if err != nil {
+		cancel()
+		b.onError(err)
+		return nil
+	}
+
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	if len(w.protocols) > 0 {
+		req.Header.Set("Sec-WebSocket-Protocol", strings.Join(w.protocols, ", "))
+	}
+
+	res, err := b.roundTrip(req, func(r *http.Request) (*http.Response, error) {
+		req = r
+		return b.httpClient().Do(req)
+	})
+	timer.Stop()
+
+	if err != nil {
+		cancel()
+		b.onError(err)
+		return nil
+	}
+
+	fail := func(err error) *WSConn {
+		res.Body.Close()
+		cancel()
+		b.onError(fmt.Errorf("WS %s: %s", u.String(), err))
+		return nil
+	}
+
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 100))
+		return fail(fmt.Errorf("expected status 101 got %d: %s", res.StatusCode, body))
+	}
+
+	accept := sha1.Sum([]byte(key + wsGUID))
+	if res.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(accept[:]) {
+		return fail(errors.New("invalid Sec-WebSocket-Accept"))
+	}
+
+	rwc, ok := res.Body.(io.ReadWriteCloser)
+	if !ok {
+		return fail(errors.New("connection is not writable"))
+	}
+
+	conn := &WSConn{
+		url:      u.String(),
+		conn:     rwc,
+		cancel:   cancel,
+		timeout:  w.timeout,
+		onError:  b.onError,
+		helper:   b.helper,
+		Protocol: res.Header.Get("Sec-WebSocket-Protocol"),
+		messages: make(chan wsMessage, 64),
+		done:     make(chan struct{}),
+		closing:  make(chan struct{}),
+	}
+
+	go conn.readLoop()
+
+	return conn
+}
+
+type wsMessage struct {
+	opcode byte
+	data   []byte
+}
+
+type WSConn struct {
+	url      string
+	conn     io.ReadWriteCloser
+	cancel   context.CancelFunc
+	timeout  time.Duration
+	onError  func(error)
+	helper   func()
+	writeMu  sync.Mutex
+	messages chan wsMessage
+	done     chan struct{}
+	closing  chan struct{}
+	readErr  error
+	closed   bool
+
+	Protocol string
+}
+
+func (c *WSConn) err(err error) {
+	c.helper()
+
+	c.onError(fmt.Errorf("WS %s: %s", c.url, err))
+}
+
+func (c *WSConn) Timeout(d time.Duration) *WSConn {
+	c.timeout = d
+	return c
+}
+
+func (c *WSConn) Send(text string) *WSConn {
+	c.helper()
+
+	if err := c.send(wsOpText, []byte(text)); err != nil {
+		c.err(err)
+	}
+	return c
+}
+
+func (c *WSConn) SendBinary(data []byte) *WSConn {
+	c.helper()
+
+	if err := c.send(wsOpBinary, data); err != nil {
+		c.err(err)
+	}
+	return c
+}
+
+func (c *WSConn) SendJSON(v interface{}) *WSConn {
+	c.helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		c.err(err)
+		return c
+	}
+	return c.Send(string(data))
+}
+
+// Receive waits for the next text or binary message and returns its type
+// and payload.
+func (c *WSConn) Receive() (WSMessageType, []byte, bool) {
+	c.helper()
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	select {
+	case msg, ok := <-c.messages:
+		if !ok {
+			err := c.readErr
+			if err == nil {
+				err = errors.New("connection closed")
+			}
+			c.err(fmt.Errorf("expected message: %w", err))
+			return 0, nil, false
+		}
+		return WSMessageType(msg.opcode), msg.data, true
+	case <-timer.C:
+		c.err(fmt.Errorf("expected message within %s", c.timeout))
+		return 0, nil, false
+	}
+}
+
+func (c *WSConn) receiveText() ([]byte, bool) {
+	c.helper()
+
+	typ, data, ok := c.Receive()
+	if ok && typ != WSText {
+		c.err(fmt.Errorf("expected text message got %s", typ))
+		return nil, false
+	}
+	return data, ok
+}
+
+func (c *WSConn) ExpectText(expected string) *WSConn {
+	c.helper()
+
+	data, ok := c.receiveText()
+	if ok && string(data) != expected {
+		c.err(fmt.Errorf("expected message %s got %s", expected, data))
+	}
+	return c
+}
+
+func (c *WSConn) ExpectTextContains(substr string) *WSConn {
+	c.helper()
+
+	data, ok := c.receiveText()
+	if ok && !strings.Contains(string(data), substr) {
+		c.err(fmt.Errorf("message does not contain %s: %s", substr, data))
+	}
+	return c
+}
+
+func (c *WSConn) ExpectJSON(v interface{}) *WSConn {
+	c.helper()
+
+	_, data, ok := c.Receive()
+	if !ok {
+		return c
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		c.err(fmt.Errorf("invalid JSON message: %s: %s", err, data))
+	}
+	return c
+}
+
+func (c *WSConn) Close() {
+	c.helper()
+
+	c.writeMu.Lock()
+	alreadyClosed := c.closed
+	c.closed = true
+	c.writeMu.Unlock()
+
+	if alreadyClosed {
+		return
+	}
+
+	close(c.closing)
+
+	payload := make([]byte, 2)
+	binary.BigEndian.PutUint16(payload, 1000)
+	c.writeFrame(wsOpClose, payload)
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	select {
+	case <-c.done:
+	case <-timer.C:
+	}
+
+	c.conn.Close()
+	c.cancel()
+}
+
+func (c *WSConn) send(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	closed := c.closed
+	c.writeMu.Unlock()
+
+	if closed {
+		return errors.New("connection closed")
+	}
+
+	return c.writeFrame(opcode, payload)
+}
+
+func (c *WSConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	frame := &bytes.Buffer{}
+	frame.WriteByte(0x80 | opcode)
+
+	length := len(payload)
+	switch {
+	case length < 126:
+		frame.WriteByte(0x80 | byte(length))
+	case length <= 0xffff:
+		frame.WriteByte(0x80 | 126)
+		binary.Write(frame, binary.BigEndian, uint16(length))
+	default:
+		frame.WriteByte(0x80 | 127)
+		binary.Write(frame, binary.BigEndian, uint64(length))
+	}
+
+	mask := make([]byte, 4)
+	if _, err := rand.Read(mask); err != nil {
+		return err
+	}
+	frame.Write(mask)
+
+	for i, p := range payload {
+		frame.WriteByte(p ^ mask[i%4])
+	}
+
+	_, err := c.conn.Write(frame.Bytes())
+	return err
+}
+
+func (c *WSConn) readLoop() {
+	defer close(c.done)
+	defer close(c.messages)
+
+	var message []byte
+	var messageOpcode byte
+
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			c.readErr = err
+			return
+		}
+
+		switch opcode {
+		case wsOpPing:
+			c.writeFrame(wsOpPong, payload)
+		case wsOpPong:
+		case wsOpClose:
+			c.writeMu.Lock()
+			alreadyClosed := c.closed
+			c.closed = true
+			c.writeMu.Unlock()
+			if !alreadyClosed {
+				c.writeFrame(wsOpClose, payload)
+			}
+			c.readErr = errors.New("connection closed by server")
+			return
+		case wsOpText, wsOpBinary, wsOpContinuation:
+			if opcode != wsOpContinuation {
+				messageOpcode = opcode
+				message = nil
+			}
+			if len(message)+len(payload) > wsMaxMessageSize {
+				c.readErr = fmt.Errorf("message exceeds the limit of %d bytes", wsMaxMessageSize)
+				return
+			}
+			message = append(message, payload...)
+			if fin {
+				// Close stops reading messages so the loop must not block on
+				// a full channel once the connection is being closed.
+				select {
+				case c.messages <- wsMessage{opcode: messageOpcode, data: message}:
+				case <-c.closing:
+					return
+				}
+				message = nil
+			}
+		}
+	}
+}
+
+func (c *WSConn) readFrame() (bool, byte, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.conn, header); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin := header[0]&0x80 != 0
+	opcode := header[0] & 0x0f
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7f)
+
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(c.conn, ext); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err := io.ReadFull(c.conn, ext); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext)
+	}
+
+	if length > wsMaxMessageSize {
+		return false, 0, nil, fmt.Errorf("frame of %d bytes exceeds the limit of %d bytes", length, wsMaxMessageSize)
+	}
+
+	var mask []byte
+	if masked {
+		mask = make([]byte, 4)
+		if _, err := io.ReadFull(c.conn, mask); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.conn, payload); err != nil {
+		return false, 0, nil, err
+	}
+
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+
+	return fin, opcode, payload, nil
+}
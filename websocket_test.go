@@ -0,0 +1,150 @@
+package httptester_test
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bancek/httptester"
+)
+
+func wsEchoHandler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(401)
+			return
+		}
+
+		accept := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+		rw.WriteString("Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(accept[:]) + "\r\n\r\n")
+		rw.Flush()
+
+		for {
+			opcode, payload, ok := readClientFrame(rw.Reader)
+			if !ok {
+				return
+			}
+			switch string(payload) {
+			case "flood":
+				for i := 0; i < 100; i++ {
+					rw.Write([]byte{0x81, 1, 'x'})
+				}
+				rw.Flush()
+				continue
+			case "huge":
+				rw.Write([]byte{0x82, 127, 0, 0, 1, 0, 0, 0, 0, 0})
+				rw.Flush()
+				continue
+			}
+			rw.Write(append([]byte{0x80 | opcode, byte(len(payload))}, payload...))
+			rw.Flush()
+			if opcode == 0x8 {
+				return
+			}
+		}
+	}
+}
+
+func readClientFrame(r *bufio.Reader) (byte, []byte, bool) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, false
+	}
+	mask := make([]byte, 4)
+	if _, err := io.ReadFull(r, mask); err != nil {
+		return 0, nil, false
+	}
+	payload := make([]byte, header[1]&0x7f)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, false
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return header[0] & 0x0f, payload, true
+}
+
+func TestWebSocket(t *testing.T) {
+	server := httptest.NewServer(wsEchoHandler(t))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	conn := c.Request().Bearer("token").WS("/ws").Timeout(time.Second).Dial()
+
+	msg := map[string]string{}
+	conn.Send("hello").ExpectText("hello").
+		SendJSON(map[string]string{"type": "ping"}).ExpectJSON(&msg)
+	if msg["type"] != "ping" {
+		t.Fatalf("unexpected message: %v", msg)
+	}
+
+	conn.SendBinary([]byte{1, 2})
+	if typ, data, ok := conn.Receive(); !ok || typ != httptester.WSBinary || string(data) != "\x01\x02" {
+		t.Fatalf("unexpected message %s %q", typ, data)
+	}
+
+	conn.Close()
+
+	var errs []error
+	collectErrors(server.URL, &errs).WS("/ws").Dial()
+	if len(errs) != 1 {
+		t.Fatalf("expected handshake error got %v", errs)
+	}
+
+	conn = collectErrors(server.URL, &errs).Bearer("token").WS("/ws").Timeout(50 * time.Millisecond).Dial()
+	conn.ExpectText("never")
+	conn.Close()
+	if len(errs) != 2 {
+		t.Fatalf("expected timeout error got %v", errs)
+	}
+
+	errs = nil
+	conn = collectErrors(server.URL, &errs).Bearer("token").WS("/ws").Timeout(time.Second).Dial()
+	conn.SendBinary([]byte("binary")).ExpectText("binary")
+	conn.Send("huge").ExpectText("huge")
+	conn.Close()
+
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		"expected text message got binary",
+		"expected message: frame of 1099511627776 bytes exceeds the limit of 33554432 bytes",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
+
+func TestWebSocketCloseWithUnreadMessages(t *testing.T) {
+	server := httptest.NewServer(wsEchoHandler(t))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	conn := c.Request().Bearer("token").WS("/ws").Timeout(5 * time.Second).Dial()
+	conn.Send("flood").ExpectText("x")
+
+	start := time.Now()
+	conn.Close()
+	if d := time.Since(start); d > 2*time.Second {
+		t.Fatalf("expected Close to return without waiting for the timeout, took %s", d)
+	}
+}
@@ -0,0 +1,555 @@
+package httptester
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// domNode is an element, attribute or text node of a parsed XML or HTML
+// document. The document itself is an element without a name.
+type domNode struct {
+	kind     domNodeKind
+	name     string
+	attrs    []*domNode
+	children []*domNode
+	parent   *domNode
+	// text is the value of attribute and text nodes.
+	text string
+}
+
+type domNodeKind int
+
+const (
+	elementNode domNodeKind = iota
+	attributeNode
+	textNode
+)
+
+func (n *domNode) appendChild(child *domNode) {
+	child.parent = n
+	n.children = append(n.children, child)
+}
+
+func (n *domNode) attr(name string) (string, bool) {
+	for _, a := range n.attrs {
+		if a.name == name {
+			return a.text, true
+		}
+	}
+	return "", false
+}
+
+// textContent returns the concatenated text of n and its descendants.
+func (n *domNode) textContent() string {
+	if n.kind != elementNode {
+		return n.text
+	}
+
+	sb := &strings.Builder{}
+	var walk func(n *domNode)
+	walk = func(n *domNode) {
+		for _, c := range n.children {
+			if c.kind == textNode {
+				sb.WriteString(c.text)
+			} else {
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+
+	return sb.String()
+}
+
+func (n *domNode) root() *domNode {
+	for n.parent != nil {
+		n = n.parent
+	}
+	return n
+}
+
+func (n *domNode) descendantsOrSelf() []*domNode {
+	nodes := []*domNode{n}
+	for _, c := range n.children {
+		if c.kind == elementNode {
+			nodes = append(nodes, c.descendantsOrSelf()...)
+		}
+	}
+	return nodes
+}
+
+// parseXMLDocument parses data into a tree of element and non-whitespace
+// text nodes. Names are local names without namespace prefixes.
+func parseXMLDocument(data []byte) (*domNode, error) {
+	doc := &domNode{}
+	current := doc
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			element := &domNode{name: t.Name.Local}
+			for _, a := range t.Attr {
+				element.attrs = append(element.attrs, &domNode{kind: attributeNode, name: a.Name.Local, text: a.Value, parent: element})
+			}
+			current.appendChild(element)
+			current = element
+		case xml.EndElement:
+			current = current.parent
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				current.appendChild(&domNode{kind: textNode, text: string(t)})
+			}
+		}
+	}
+
+	return doc, nil
+}
+
+// xpathExpr is a compiled location path of the supported XPath subset:
+// child (/) and descendant (//) steps with name tests, *, ., .., text(),
+// node(), @name and @*, and predicates with a position, last(), a relative
+// path (existence), path = 'literal', path != 'literal',
+// contains(path, 'literal') and starts-with(path, 'literal').
+// Other expressions are rejected.
+type xpathExpr struct {
+	absolute bool
+	steps    []xpathStep
+}
+
+type xpathStep struct {
+	descendant bool
+	test       string
+	predicates []xpathPredicate
+}
+
+type xpathPredicate struct {
+	position int
+	last     bool
+	function string
+	op       string
+	path     *xpathExpr
+	literal  string
+}
+
+// splitXPath splits s at sep outside of brackets, parentheses and quotes.
+func splitXPath(s string, sep byte) []string {
+	parts := []string{}
+	depth := 0
+	var quote byte
+	start := 0
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[' || c == '(':
+			depth++
+		case c == ']' || c == ')':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+func compileXPath(path string) (*xpathExpr, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, errors.New("empty path")
+	}
+
+	segments := splitXPath(path, '/')
+	expr := &xpathExpr{}
+	if segments[0] == "" {
+		expr.absolute = true
+		segments = segments[1:]
+	}
+
+	descendant := false
+	for i, segment := range segments {
+		segment = strings.TrimSpace(segment)
+		if segment == "" {
+			if descendant || i == len(segments)-1 {
+				return nil, errors.New("unexpected /")
+			}
+			descendant = true
+			continue
+		}
+
+		step, err := compileXPathStep(segment)
+		if err != nil {
+			return nil, err
+		}
+		step.descendant = descendant
+		descendant = false
+		expr.steps = append(expr.steps, step)
+	}
+
+	return expr, nil
+}
+
+// xpathNameRegexp matches names with an optional namespace prefix.
+var xpathNameRegexp = regexp.MustCompile(`^(?:[\pL_][\pL\pN_.-]*:)?[\pL_][\pL\pN_.-]*$`)
+
+// validateXPathTest returns an error for node tests outside the supported
+// subset, which would otherwise silently match nothing.
+func validateXPathTest(test string) error {
+	switch test {
+	case ".", "..", "*", "text()", "node()", "@*":
+		return nil
+	}
+	if xpathNameRegexp.MatchString(strings.TrimPrefix(test, "@")) {
+		return nil
+	}
+	if test == "" {
+		return errors.New("missing node test")
+	}
+	return fmt.Errorf("unsupported expression %q", test)
+}
+
+func compileXPathStep(segment string) (xpathStep, error) {
+	step := xpathStep{}
+
+	open := strings.IndexByte(segment, '[')
+	if open < 0 {
+		step.test = segment
+		return step, validateXPathTest(step.test)
+	}
+	step.test = strings.TrimSpace(segment[:open])
+	if err := validateXPathTest(step.test); err != nil {
+		return step, err
+	}
+
+	rest := segment[open:]
+	for rest != "" {
+		if rest[0] != '[' {
+			return step, fmt.Errorf("unexpected %q", rest)
+		}
+		end := matchingClose(rest, '[', ']')
+		if end < 0 {
+			return step, errors.New("unterminated [")
+		}
+		predicate, err := compileXPathPredicate(strings.TrimSpace(rest[1:end]))
+		if err != nil {
+			return step, err
+		}
+		step.predicates = append(step.predicates, predicate)
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+
+	return step, nil
+}
+
+// matchingClose returns the index of the close delimiter matching the open
+// delimiter s starts with, ignoring delimiters in quotes.
+func matchingClose(s string, open byte, close byte) int {
+	depth := 0
+	var quote byte
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == open:
+			depth++
+		case c == close:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+func compileXPathPredicate(s string) (xpathPredicate, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 1 {
+			return xpathPredicate{}, fmt.Errorf("invalid position %d", n)
+		}
+		return xpathPredicate{position: n}, nil
+	}
+	if s == "last()" {
+		return xpathPredicate{last: true}, nil
+	}
+
+	for _, function := range []string{"contains", "starts-with"} {
+		if strings.HasPrefix(s, function+"(") && strings.HasSuffix(s, ")") {
+			args := splitXPath(s[len(function)+1:len(s)-1], ',')
+			if len(args) != 2 {
+				return xpathPredicate{}, fmt.Errorf("%s expects 2 arguments", function)
+			}
+			path, err := compileXPath(args[0])
+			if err != nil {
+				return xpathPredicate{}, err
+			}
+			literal, err := xpathStringLiteral(args[1])
+			if err != nil {
+				return xpathPredicate{}, err
+			}
+			return xpathPredicate{function: function, path: path, literal: literal}, nil
+		}
+	}
+
+	predicate := xpathPredicate{}
+	left := s
+	if parts := splitXPath(s, '='); len(parts) == 2 {
+		left = parts[0]
+		predicate.op = "="
+		if strings.HasSuffix(left, "!") {
+			left = left[:len(left)-1]
+			predicate.op = "!="
+		}
+		literal, err := xpathStringLiteral(parts[1])
+		if err != nil {
+			return xpathPredicate{}, err
+		}
+		predicate.literal = literal
+	}
+
+	path, err := compileXPath(left)
+	if err != nil {
+		return xpathPredicate{}, err
+	}
+	predicate.path = path
+
+	return predicate, nil
+}
+
+// xpathStringLiteral returns the value of a quoted string or number literal
+// of a predicate.
+func xpathStringLiteral(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] && !strings.ContainsRune(s[1:len(s)-1], rune(s[0])) {
+		return s[1 : len(s)-1], nil
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return s, nil
+	}
+	return "", fmt.Errorf("unsupported expression %q, expected a quoted string or number", s)
+}
+
+func xpathLiteral(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func (e *xpathExpr) eval(context *domNode) []*domNode {
+	nodes := []*domNode{context}
+	if e.absolute {
+		nodes = []*domNode{context.root()}
+	}
+
+	for _, step := range e.steps {
+		seen := map[*domNode]bool{}
+		next := []*domNode{}
+		for _, n := range nodes {
+			for _, m := range step.eval(n) {
+				if !seen[m] {
+					seen[m] = true
+					next = append(next, m)
+				}
+			}
+		}
+		nodes = next
+	}
+
+	return nodes
+}
+
+func (s xpathStep) eval(n *domNode) []*domNode {
+	contexts := []*domNode{n}
+	if s.descendant {
+		contexts = n.descendantsOrSelf()
+	}
+
+	result := []*domNode{}
+	for _, c := range contexts {
+		selected := s.selectNodes(c)
+		for _, p := range s.predicates {
+			filtered := []*domNode{}
+			for i, m := range selected {
+				if p.matches(m, i+1, len(selected)) {
+					filtered = append(filtered, m)
+				}
+			}
+			selected = filtered
+		}
+		result = append(result, selected...)
+	}
+
+	return result
+}
+
+func (s xpathStep) selectNodes(n *domNode) []*domNode {
+	switch {
+	case s.test == ".":
+		return []*domNode{n}
+	case s.test == "..":
+		if n.parent == nil {
+			return nil
+		}
+		return []*domNode{n.parent}
+	case strings.HasPrefix(s.test, "@"):
+		name := xpathLocalName(s.test[1:])
+		nodes := []*domNode{}
+		for _, a := range n.attrs {
+			if name == "*" || a.name == name {
+				nodes = append(nodes, a)
+			}
+		}
+		return nodes
+	}
+
+	name := xpathLocalName(s.test)
+	nodes := []*domNode{}
+	for _, c := range n.children {
+		switch {
+		case name == "node()",
+			name == "text()" && c.kind == textNode,
+			c.kind == elementNode && (name == "*" || c.name == name):
+			nodes = append(nodes, c)
+		}
+	}
+	return nodes
+}
+
+func xpathLocalName(name string) string {
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+func (p xpathPredicate) matches(n *domNode, position int, size int) bool {
+	switch {
+	case p.position > 0:
+		return position == p.position
+	case p.last:
+		return position == size
+	}
+
+	for _, m := range p.path.eval(n) {
+		value := m.textContent()
+		switch {
+		case p.function == "contains" && strings.Contains(value, p.literal),
+			p.function == "starts-with" && strings.HasPrefix(value, p.literal),
+			p.op == "=" && value == p.literal,
+			p.op == "!=" && value != p.literal,
+			p.function == "" && p.op == "":
+			return true
+		}
+	}
+
+	return false
+}
+
+// xmlPath evaluates an XPath expression against the XML body.
+func (r *Response) xmlPath(path string) ([]*domNode, bool) {
+	r.helper()
+
+	if !r.hasBody() {
+		return nil, false
+	}
+
+	expr, err := compileXPath(path)
+	if err != nil {
+		r.err(fmt.Errorf("invalid XPath %s: %w", path, err))
+		return nil, false
+	}
+
+	doc, err := parseXMLDocument(r.Body)
+	if err != nil {
+		r.err(err)
+		return nil, false
+	}
+
+	return expr.eval(doc), true
+}
+
+// XMLPathValue returns the string value of the first node matched by an
+// XPath expression, e.g. //user[@id='1']/name or //user/@id.
+func (r *Response) XMLPathValue(path string) string {
+	r.helper()
+
+	nodes, ok := r.xmlPath(path)
+	if !ok {
+		return ""
+	}
+	if len(nodes) == 0 {
+		r.err(fmt.Errorf("XPath %s matched no nodes", path))
+		return ""
+	}
+
+	return nodes[0].textContent()
+}
+
+// XMLPath checks the string value of the first node matched by an XPath
+// expression. Surrounding whitespace is ignored.
+func (r *Response) XMLPath(path string, expected string) *Response {
+	r.helper()
+	defer r.track("XMLPath", path, expected)()
+
+	nodes, ok := r.xmlPath(path)
+	if !ok {
+		return r
+	}
+	if len(nodes) == 0 {
+		r.err(fmt.Errorf("XPath %s matched no nodes", path))
+		return r
+	}
+
+	if value := strings.TrimSpace(nodes[0].textContent()); value != expected {
+		r.err(fmt.Errorf("XPath %s: expected %q got %q", path, expected, value))
+	}
+
+	return r
+}
+
+func (r *Response) XMLPathCount(path string, count int) *Response {
+	r.helper()
+	defer r.track("XMLPathCount", path, count)()
+
+	nodes, ok := r.xmlPath(path)
+	if !ok {
+		return r
+	}
+
+	if len(nodes) != count {
+		r.err(fmt.Errorf("XPath %s: expected %d nodes got %d", path, count, len(nodes)))
+	}
+
+	return r
+}
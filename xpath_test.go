@@ -0,0 +1,79 @@
+package httptester_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestResponseXMLPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0"?>
+<a:response xmlns:a="urn:api">
+  <users>
+    <user id="1" role="admin"><name>alice</name><email>alice@example.com</email></user>
+    <user id="2"><name>bob</name><tags><tag>x</tag><tag>y</tag></tags></user>
+    <user id="3"><name> carol </name></user>
+  </users>
+  <total>3</total>
+</a:response>`))
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	res := c.GET("/").Do().
+		XMLPath("//user[@id='1']/name", "alice").
+		XMLPath("/response/users/user[2]/name", "bob").
+		XMLPath("//user[last()]/name", "carol").
+		XMLPath("//user[name='bob']/@id", "2").
+		XMLPath("//user[@role]/email/text()", "alice@example.com").
+		XMLPath("//user[contains(email, '@example')]/@id", "1").
+		XMLPath("//tag[.='y']/../../name", "bob").
+		XMLPath("/a:response/total", "3").
+		XMLPathCount("//user", 3).
+		XMLPathCount("//user[@id!='1']", 2).
+		XMLPathCount("//tags/*", 2).
+		XMLPathCount("//user/@*", 4)
+
+	if v := res.XMLPathValue("//user[3]/name"); v != " carol " {
+		t.Fatalf("expected untrimmed value got %q", v)
+	}
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	b.GET("/").Do().
+		XMLPath("//user[@id='2']/name", "alice").
+		XMLPath("//user[@id='4']/name", "dave").
+		XMLPathCount("//tag", 3).
+		XMLPath("//user[", "x").
+		XMLPathCount("//user[position()>1]", 0).
+		XMLPathCount("/a|/b", 0).
+		XMLPathCount("//user[count(tags)=1]", 0).
+		XMLPathCount("//user[name=bob]", 0)
+
+	if len(errs) != 8 {
+		t.Fatalf("expected 8 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		`XPath //user[@id='2']/name: expected "alice" got "bob"`,
+		"XPath //user[@id='4']/name matched no nodes",
+		"XPath //tag: expected 3 nodes got 2",
+		"invalid XPath //user[: unterminated [",
+		`invalid XPath //user[position()>1]: unsupported expression "position()>1"`,
+		`invalid XPath /a|/b: unsupported expression "a|"`,
+		`invalid XPath //user[count(tags)=1]: unsupported expression "count(tags)"`,
+		`invalid XPath //user[name=bob]: unsupported expression "bob", expected a quoted string or number`,
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}
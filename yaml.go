@@ -0,0 +1,78 @@
+package httptester
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlContentTypes are the media types YAML bodies are accepted with.
+var yamlContentTypes = []string{
+	"application/yaml",
+	"application/x-yaml",
+	"text/yaml",
+	"text/x-yaml",
+}
+
+type yamlCodec struct{}
+
+func (yamlCodec) Marshal(v interface{}) ([]byte, error) {
+	return yaml.Marshal(v)
+}
+
+func (yamlCodec) Unmarshal(data []byte, v interface{}) error {
+	return yaml.Unmarshal(data, v)
+}
+
+func init() {
+	for _, contentType := range yamlContentTypes {
+		RegisterCodec(contentType, yamlCodec{})
+	}
+}
+
+// YAML sets the body to v encoded as YAML and the Content-Type to
+// application/yaml.
+func (b *ReqBuilder) YAML(v interface{}) *ReqBuilder {
+	b.helper()
+
+	b.Header("Content-Type", "application/yaml")
+	yamlBytes, err := yaml.Marshal(v)
+	if err != nil {
+		b.onError(err)
+	}
+	return b.Body(bytes.NewReader(yamlBytes))
+}
+
+func isYAMLContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range yamlContentTypes {
+		if mediaType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// YAML decodes the YAML body into v.
+func (r *Response) YAML(v interface{}) interface{} {
+	r.helper()
+
+	if !r.hasBody() {
+		return nil
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if !isYAMLContentType(contentType) {
+		r.err(fmt.Errorf("Content-Type is not application/yaml, got %s: %s", contentType, r.bodyExcerpt()))
+	}
+	if err := yaml.Unmarshal(r.Body, v); err != nil {
+		r.err(err)
+		return nil
+	}
+	return v
+}
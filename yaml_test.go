@@ -0,0 +1,82 @@
+package httptester_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bancek/httptester"
+)
+
+func TestYAML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/echo":
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+			w.Write(body)
+		case "/config":
+			w.Header().Set("Content-Type", "text/yaml; charset=utf-8")
+			w.Write([]byte("name: api\nreplicas: 3\nports:\n  - 80\n  - 443\n"))
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{}`))
+		case "/invalid":
+			w.Header().Set("Content-Type", "application/yaml")
+			w.Write([]byte("a: [1"))
+		}
+	}))
+	defer server.Close()
+
+	c := httptester.New(t, server.URL)
+
+	type config struct {
+		Name     string `yaml:"name"`
+		Replicas int    `yaml:"replicas"`
+		Ports    []int  `yaml:"ports"`
+	}
+
+	var out config
+	c.POST("/echo").YAML(config{Name: "web", Replicas: 2, Ports: []int{8080}}).Do().
+		HeaderEq("Content-Type", "application/yaml").
+		Eq("name: web\nreplicas: 2\nports:\n    - 8080\n").
+		YAML(&out)
+	if out.Name != "web" || out.Replicas != 2 || len(out.Ports) != 1 {
+		t.Fatalf("unexpected config %+v", out)
+	}
+
+	out = config{}
+	c.GET("/config").Do().YAML(&out)
+	if out.Name != "api" || out.Replicas != 3 || len(out.Ports) != 2 || out.Ports[1] != 443 {
+		t.Fatalf("unexpected config %+v", out)
+	}
+
+	out = config{}
+	c.GET("/config").Do().Decode(&out)
+	if out.Name != "api" {
+		t.Fatalf("unexpected config %+v", out)
+	}
+
+	var errs []error
+	b := httptester.NewClient(httptester.WithBaseURL(server.URL), httptester.WithOnError(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	var v interface{}
+	b.GET("/json").Do().YAML(&v)
+	b.GET("/invalid").Do().YAML(&v)
+
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors got %v", errs)
+	}
+	for i, msg := range []string{
+		"Content-Type is not application/yaml, got application/json: {}",
+		"yaml: line 1: did not find expected ',' or ']'",
+	} {
+		if !strings.Contains(errs[i].Error(), msg) {
+			t.Errorf("expected error %d to contain %q got %s", i, msg, errs[i])
+		}
+	}
+}